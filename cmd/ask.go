@@ -0,0 +1,157 @@
+// cmd/ask.go
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/blogmon/internal/config"
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/prompt"
+	"github.com/julienpequegnot/blogmon/internal/search"
+	"github.com/spf13/cobra"
+)
+
+var askCmd = &cobra.Command{
+	Use:   "ask <question>",
+	Short: "Answer a question from your posts, with citations",
+	Long: `Retrieves the post excerpts and insights most relevant to the question, using
+embeddings when available and full-text search otherwise, and has the LLM
+answer from them alone. Citations refer to the numbered sources listed below
+the answer.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runAsk,
+}
+
+var (
+	askSources int
+	askJSON    bool
+)
+
+var citationRef = regexp.MustCompile(`\[(\d+)\]`)
+
+func init() {
+	rootCmd.AddCommand(askCmd)
+	askCmd.Flags().IntVarP(&askSources, "sources", "n", 6, "Maximum post excerpts to give the LLM")
+	askCmd.Flags().BoolVar(&askJSON, "json", false, "Print the answer and citations as JSON")
+}
+
+// askCitation is a numbered source of an answer
+type askCitation struct {
+	Number  int    `json:"number"`
+	PostID  int64  `json:"post_id"`
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Kind    string `json:"kind"`
+	Excerpt string `json:"excerpt"`
+	Cited   bool   `json:"cited"`
+}
+
+type askResult struct {
+	Question  string        `json:"question"`
+	Answer    string        `json:"answer"`
+	Citations []askCitation `json:"citations"`
+}
+
+func runAsk(cmd *cobra.Command, args []string) error {
+	question := strings.Join(args, " ")
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Embeddings are optional: without them retrieval uses full-text search
+	vector, model, err := embedQuery(db, question)
+	if err != nil && !askJSON && !errors.Is(err, errNoEmbeddingModel) {
+		fmt.Fprintf(os.Stderr, "Semantic retrieval unavailable (%v), using full-text search\n", err)
+	}
+
+	passages, err := search.NewRepository(db).Passages(question, vector, model, askSources)
+	if err != nil {
+		return fmt.Errorf("retrieval failed: %w", err)
+	}
+
+	result := askResult{Question: question, Citations: []askCitation{}}
+	if len(passages) == 0 {
+		if askJSON {
+			return printJSON(result)
+		}
+		fmt.Println("No relevant posts found. Try 'blogmon fetch' and 'blogmon extract' first.")
+		return nil
+	}
+
+	sources := make([]prompt.Source, len(passages))
+	for i, p := range passages {
+		sources[i] = prompt.Source{Number: i + 1, Title: p.Title, URL: p.URL, Text: truncateForLLM(p.Text)}
+		result.Citations = append(result.Citations, askCitation{
+			Number:  i + 1,
+			PostID:  p.PostID,
+			Title:   p.Title,
+			URL:     p.URL,
+			Kind:    p.Kind,
+			Excerpt: p.Text,
+		})
+	}
+
+	llmClient, err := newLLMClient(cfg, db)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	result.Answer, err = llmClient.Answer(ctx, question, sources)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range citationRef.FindAllStringSubmatch(result.Answer, -1) {
+		if n, _ := strconv.Atoi(m[1]); n >= 1 && n <= len(result.Citations) {
+			result.Citations[n-1].Cited = true
+		}
+	}
+
+	if askJSON {
+		return printJSON(result)
+	}
+
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	numberStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	urlStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("12")).Underline(true)
+	answerStyle := lipgloss.NewStyle().Width(80)
+
+	fmt.Println(answerStyle.Render(result.Answer))
+	fmt.Printf("\n%s\n", labelStyle.Render("SOURCES:"))
+	for _, c := range result.Citations {
+		line := fmt.Sprintf("%s %s (post %d)", numberStyle.Render(fmt.Sprintf("[%d]", c.Number)), c.Title, c.PostID)
+		if !c.Cited {
+			line = labelStyle.Render(fmt.Sprintf("[%d] %s (post %d, not cited)", c.Number, c.Title, c.PostID))
+		}
+		fmt.Println(line)
+		fmt.Printf("    %s\n", urlStyle.Render(c.URL))
+	}
+
+	return nil
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -4,23 +4,23 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/julienpequegnot/blogmon/internal/community"
 	"github.com/julienpequegnot/blogmon/internal/config"
 	"github.com/julienpequegnot/blogmon/internal/database"
 	"github.com/julienpequegnot/blogmon/internal/feed"
 	"github.com/julienpequegnot/blogmon/internal/graph"
-	"github.com/julienpequegnot/blogmon/internal/insight"
+	"github.com/julienpequegnot/blogmon/internal/job"
 	"github.com/julienpequegnot/blogmon/internal/link"
-	"github.com/julienpequegnot/blogmon/internal/llm"
 	"github.com/julienpequegnot/blogmon/internal/post"
-	"github.com/julienpequegnot/blogmon/internal/reference"
 	"github.com/julienpequegnot/blogmon/internal/score"
 	"github.com/julienpequegnot/blogmon/internal/scorer"
+	"github.com/julienpequegnot/blogmon/internal/snippet"
 	"github.com/julienpequegnot/blogmon/internal/source"
+	"github.com/julienpequegnot/blogmon/internal/topic"
 	"github.com/spf13/cobra"
 )
 
@@ -36,6 +36,10 @@ var (
 	daemonOnce     bool
 )
 
+// daemonBatch is the least number of posts each stage of a cycle processes,
+// so retried jobs and earlier backlogs are worked off without new posts
+const daemonBatch = 50
+
 func init() {
 	rootCmd.AddCommand(daemonCmd)
 	daemonCmd.Flags().IntVar(&daemonInterval, "interval", 0, "Override interval in hours (0 = use config)")
@@ -55,8 +59,12 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Blogmon daemon starting (interval: %d hours)\n", interval)
 
+	// Set up signal handling; cancellation also stops in-flight extraction
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	// Run immediately on start
-	if err := runPipeline(cfg); err != nil {
+	if err := runPipeline(ctx); err != nil {
 		fmt.Printf("Pipeline error: %v\n", err)
 	}
 
@@ -65,13 +73,6 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Set up signal handling
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
 	ticker := time.NewTicker(time.Duration(interval) * time.Hour)
 	defer ticker.Stop()
 
@@ -81,23 +82,27 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 		select {
 		case <-ticker.C:
 			fmt.Printf("\n[%s] Running scheduled pipeline...\n", time.Now().Format("2006-01-02 15:04:05"))
-			if err := runPipeline(cfg); err != nil {
+			if err := runPipeline(ctx); err != nil {
 				fmt.Printf("Pipeline error: %v\n", err)
 			}
 			fmt.Printf("Next run in %d hours.\n", interval)
 
-		case sig := <-sigChan:
-			fmt.Printf("\nReceived signal %v, shutting down...\n", sig)
-			cancel()
-			return nil
-
 		case <-ctx.Done():
+			fmt.Println("\nReceived signal, shutting down...")
 			return nil
 		}
 	}
 }
 
-func runPipeline(cfg *config.Config) error {
+// runPipeline runs one cycle. The config is loaded again every cycle, so
+// changes made while the daemon runs, e.g. with 'blogmon interests', are used
+// instead of overwritten.
+func runPipeline(ctx context.Context) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
 	db, err := database.New(config.DBPath())
 	if err != nil {
 		return err
@@ -108,6 +113,7 @@ func runPipeline(cfg *config.Config) error {
 	fmt.Println("→ Fetching new posts...")
 	srcRepo := source.NewRepository(db)
 	postRepo := post.NewRepository(db)
+	snippetRepo := snippet.NewRepository(db)
 
 	sources, err := srcRepo.List()
 	if err != nil {
@@ -116,6 +122,7 @@ func runPipeline(cfg *config.Config) error {
 
 	fetcher := feed.NewFetcher(time.Duration(cfg.Fetch.TimeoutSeconds) * time.Second)
 	newPosts := 0
+	updatedPosts := 0
 
 	for _, src := range sources {
 		if src.FeedURL == "" {
@@ -131,86 +138,87 @@ func runPipeline(cfg *config.Config) error {
 		for _, item := range items {
 			exists, _ := postRepo.Exists(item.URL)
 			if exists {
+				if changed, _ := postRepo.UpdateContentRaw(item.URL, item.Content); changed {
+					if id, err := postRepo.GetIDByURL(item.URL); err == nil {
+						snippetRepo.ReplaceForPost(id, snippet.Extract(item.Content))
+					}
+					updatedPosts++
+				}
 				continue
 			}
 
-			_, err := postRepo.Add(src.ID, item.URL, item.Title, item.Author, item.PublishedAt, item.Content)
+			saved, err := postRepo.Add(src.ID, item.URL, item.Title, item.Author, item.PublishedAt, item.Content)
 			if err == nil {
+				snippetRepo.ReplaceForPost(saved.ID, snippet.Extract(item.Content))
 				newPosts++
 			}
 		}
 
 		srcRepo.UpdateLastFetched(src.ID)
 	}
-	fmt.Printf("  Fetched %d new posts\n", newPosts)
+	fmt.Printf("  Fetched %d new posts, %d updated\n", newPosts, updatedPosts)
 
-	if newPosts == 0 {
-		fmt.Println("→ No new posts to process")
-		return nil
-	}
-
-	// Stage 2: Extract (limit to new posts)
+	// Stage 2: Extract new posts, and those whose retry is due
 	fmt.Println("→ Extracting insights...")
-	insightRepo := insight.NewRepository(db)
-	refRepo := reference.NewRepository(db)
-
-	llmClient := llm.NewClient("http://localhost:11434", cfg.APIs.LLMModel, 2*time.Minute)
+	llmClient, err := newLLMClient(cfg, db)
+	if err != nil {
+		return err
+	}
 
-	unextracted, _ := postRepo.GetUnextracted(newPosts)
-	extracted := 0
-	for _, p := range unextracted {
-		content := stripHTMLTags(p.ContentRaw)
-		if len(content) < 100 {
-			continue
-		}
-		if len(content) > 8000 {
-			content = content[:8000]
-		}
+	workers := cfg.APIs.LLMConcurrency
+	unextracted, _ := postRepo.GetUnextracted(max(newPosts, daemonBatch))
+	jobRepo := job.NewRepository(db)
+	extracted, _ := processPosts(ctx, unextracted, workers, true, trackJob(jobRepo, job.StageExtract, func(ctx context.Context, p post.Post) (string, error) {
+		return extractPost(ctx, db, llmClient, p)
+	}))
+	fmt.Printf("  Extracted insights from %d posts\n", extracted)
 
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-		result, err := llmClient.ExtractInsights(ctx, p.Title, content)
-		cancel()
+	stale, _ := postRepo.GetStaleSummaries(max(newPosts+updatedPosts, daemonBatch))
+	summarized, _ := processPosts(ctx, stale, workers, true, trackJob(jobRepo, job.StageSummary, func(ctx context.Context, p post.Post) (string, error) {
+		return "summary updated", refreshSummary(ctx, llmClient, postRepo, p)
+	}))
+	fmt.Printf("  Refreshed %d summaries\n", summarized)
 
-		if err != nil {
-			continue
-		}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-		for i, takeaway := range result.Takeaways {
-			importance := 5 - i
-			if importance < 1 {
-				importance = 1
-			}
-			insightRepo.Add(p.ID, "takeaway", takeaway, importance)
+	// Embeddings are opt-in, see 'blogmon embed'
+	if cfg.APIs.EmbeddingModel != "" {
+		fmt.Println("→ Embedding posts and insights...")
+		if embedder, err := newEmbedder(cfg); err != nil {
+			fmt.Printf("  Skipping embeddings: %v\n", err)
+		} else if posts, insights, err := embedStale(ctx, db, embedder, cfg.APIs.EmbeddingConcurrency, false, 0); err != nil {
+			fmt.Printf("  Embedding failed: %v\n", err)
+		} else {
+			fmt.Printf("  Embedded %d posts and %d insights\n", posts, insights)
 		}
 
-		for _, ref := range result.References {
-			isBlog := isBlogURL(ref.URL)
-			refRepo.Add(p.ID, ref.URL, ref.Title, ref.Context, isBlog)
+		if err := ctx.Err(); err != nil {
+			return err
 		}
-
-		wordCount := len(content) / 5 // rough estimate
-		postRepo.UpdateContentClean(p.ID, content, wordCount)
-		extracted++
 	}
-	fmt.Printf("  Extracted insights from %d posts\n", extracted)
 
 	// Stage 3: Score
 	fmt.Println("→ Scoring posts...")
+	if err := syncInterests(db, cfg); err != nil {
+		fmt.Printf("  Syncing interests failed: %v\n", err)
+	}
 	scoreRepo := score.NewRepository(db)
-	hnScorer := scorer.NewHNScorer()
-	relevanceScorer := scorer.NewRelevanceScorer(cfg.Interests)
-	noveltyScorer := scorer.NewNoveltyScorer()
-
-	allPosts, _ := postRepo.List(1000, 0)
-	for _, p := range allPosts {
-		content := p.ContentClean
-		if content == "" {
-			content = p.Title
-		}
-		noveltyScorer.AddDocument(p.ID, content)
+	signalRepo := community.NewRepository(db)
+	providers := communityProviders(cfg, nil)
+	relevanceScorer, err := newRelevance(db, cfg)
+	if err != nil {
+		fmt.Printf("  Scoring relevance by keywords only: %v\n", err)
+		relevanceScorer = &relevance{keywords: scorer.NewRelevanceScorer(cfg.Interests)}
+	}
+	var unscoredIDs []int64
+	noveltyScorer, err := noveltyIndex(db)
+	if err != nil {
+		fmt.Printf("  Skipping scoring, updating the novelty index failed: %v\n", err)
+	} else {
+		unscoredIDs, _ = scoreRepo.GetUnscoredPostIDs(max(newPosts, daemonBatch))
 	}
-
-	unscoredIDs, _ := scoreRepo.GetUnscoredPostIDs(newPosts)
 	scored := 0
 	for _, postID := range unscoredIDs {
 		p, err := postRepo.Get(postID)
@@ -218,37 +226,49 @@ func runPipeline(cfg *config.Config) error {
 			continue
 		}
 
-		var communityScore float64
-		hit, _ := hnScorer.SearchByURL(p.URL)
-		if hit != nil {
-			communityScore = scorer.CalculateCommunityScore(hit.Points, hit.NumComments, 0)
-		}
+		signals, _ := checkCommunity(signalRepo, providers, p)
+		communityScore := scorer.CommunityScoreFromSignals(signals)
 
 		content := p.ContentClean
 		if content == "" {
 			content = p.Title
 		}
-		relevanceScore := relevanceScorer.Score(p.Title, content)
-		noveltyScore := noveltyScorer.Score(content)
+		relevanceScore := relevanceScorer.Score(p)
+		noveltyScore, err := noveltyScorer.Score(p.ID, postedAt(p), content)
+		if err != nil {
+			continue
+		}
 
-		finalScore := (communityScore * cfg.Scoring.Community) +
-			(relevanceScore * cfg.Scoring.Relevance) +
-			(noveltyScore * cfg.Scoring.Novelty)
+		finalScore := calculateFinalScore(cfg.Scoring, communityScore, relevanceScore, noveltyScore, postedAt(p))
 
 		scoreRepo.Upsert(postID, communityScore, relevanceScore, noveltyScore, finalScore)
 		scored++
 	}
 	fmt.Printf("  Scored %d posts\n", scored)
 
+	if refreshed, err := refreshCommunity(db, cfg, providers, 100, nil); err != nil {
+		fmt.Printf("  Refreshing community signals failed: %v\n", err)
+	} else if refreshed > 0 {
+		fmt.Printf("  Re-checked community signals of %d posts\n", refreshed)
+	}
+
+	// Decay moves every final score, so keep them current
+	if cfg.Scoring.HalfLifeDays > 0 {
+		if _, err := rescorePosts(db, cfg, rescoreOptions{}); err != nil {
+			fmt.Printf("  Rescoring failed: %v\n", err)
+		}
+	}
+
 	// Stage 4: Link
 	fmt.Println("→ Updating concept graph...")
 	linkRepo := link.NewRepository(db)
 
+	allPosts, _ := postRepo.List(1000, 0)
+	storedTopics, _ := topic.NewRepository(db).ListAll()
 	postTopics := make(map[int64][]string)
 	for _, p := range allPosts {
 		content := p.Title + " " + p.ContentClean
-		topics := graph.ExtractTopics(content)
-		postTopics[p.ID] = topics
+		postTopics[p.ID] = graph.ResolveTopics(storedTopics[p.ID], content)
 	}
 
 	linked := 0
@@ -264,7 +284,7 @@ func runPipeline(cfg *config.Config) error {
 			similarity := graph.ComputeTopicSimilarity(topicsA, topicsB)
 			if similarity >= 0.3 {
 				sharedTopics := findSharedTopics(topicsA, topicsB)
-				relationship := "shared_topics:" + joinTopics(sharedTopics)
+				relationship := link.FormatRelationship(link.SharedTopics, joinTopics(sharedTopics))
 				if err := linkRepo.Upsert(allPosts[i].ID, allPosts[j].ID, relationship, similarity); err == nil {
 					linked++
 				}
@@ -0,0 +1,69 @@
+// cmd/debates.go
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/blogmon/internal/config"
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/link"
+	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/spf13/cobra"
+)
+
+var debatesCmd = &cobra.Command{
+	Use:   "debates",
+	Short: "List pairs of posts that contradict each other",
+	Long:  `Lists linked posts the LLM classified as contradicting each other. Run 'blogmon link --classify' first.`,
+	RunE:  runDebates,
+}
+
+var debatesLimit int
+
+func init() {
+	rootCmd.AddCommand(debatesCmd)
+	debatesCmd.Flags().IntVarP(&debatesLimit, "limit", "l", 20, "Maximum pairs to show")
+}
+
+func runDebates(cmd *cobra.Command, args []string) error {
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	links, err := link.NewRepository(db).ListByRelationship(link.Contradicts, debatesLimit)
+	if err != nil {
+		return err
+	}
+
+	if len(links) == 0 {
+		fmt.Println("No contradicting posts found. Run 'blogmon link --classify' to classify linked posts.")
+		return nil
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true)
+	idStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	vsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	rationaleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("250"))
+
+	postRepo := post.NewRepository(db)
+	for _, l := range links {
+		a, errA := postRepo.Get(l.PostIDA)
+		b, errB := postRepo.Get(l.PostIDB)
+		if errA != nil || errB != nil {
+			continue
+		}
+
+		_, rationale := link.ParseRelationship(l.Relationship)
+		fmt.Printf("%s %s (%s)\n", idStyle.Render(fmt.Sprintf("[%d]", a.ID)), titleStyle.Render(a.Title), a.SourceName)
+		fmt.Printf("  %s %s %s (%s)\n", vsStyle.Render("vs"), idStyle.Render(fmt.Sprintf("[%d]", b.ID)), titleStyle.Render(b.Title), b.SourceName)
+		if rationale != "" {
+			fmt.Printf("  %s\n", rationaleStyle.Render(rationale))
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
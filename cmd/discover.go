@@ -21,14 +21,16 @@ var discoverCmd = &cobra.Command{
 }
 
 var (
-	discoverAutoAdd bool
-	discoverLimit   int
+	discoverAutoAdd    bool
+	discoverLimit      int
+	discoverIncludeLLM bool
 )
 
 func init() {
 	rootCmd.AddCommand(discoverCmd)
 	discoverCmd.Flags().BoolVar(&discoverAutoAdd, "auto-add", false, "Automatically add discovered blogs")
 	discoverCmd.Flags().IntVarP(&discoverLimit, "limit", "l", 20, "Maximum blogs to discover")
+	discoverCmd.Flags().BoolVar(&discoverIncludeLLM, "include-llm", false, "Include references reported by the LLM but not linked in the post")
 }
 
 func runDiscover(cmd *cobra.Command, args []string) error {
@@ -41,13 +43,26 @@ func runDiscover(cmd *cobra.Command, args []string) error {
 	refRepo := reference.NewRepository(db)
 	srcRepo := source.NewRepository(db)
 
-	// Get all blog references
-	blogRefs, err := refRepo.ListBlogReferences()
+	// Get blog references, by default only those actually linked from posts
+	var blogRefs []reference.Reference
+	if discoverIncludeLLM {
+		blogRefs, err = refRepo.ListBlogReferences()
+	} else {
+		blogRefs, err = refRepo.ListCitedBlogReferences()
+	}
 	if err != nil {
 		return err
 	}
 
 	if len(blogRefs) == 0 {
+		// References stored before anchors were recorded all count as LLM-only
+		if !discoverIncludeLLM {
+			if llmRefs, err := refRepo.ListBlogReferences(); err == nil && len(llmRefs) > 0 {
+				fmt.Printf("No linked blog references found, but %d were reported by the LLM.\n", len(llmRefs))
+				fmt.Println("Include them with --include-llm, or re-extract posts to find their links: blogmon extract --reextract")
+				return nil
+			}
+		}
 		fmt.Println("No blog references found. Run 'blogmon extract' first.")
 		return nil
 	}
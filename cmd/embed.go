@@ -0,0 +1,246 @@
+// cmd/embed.go
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/config"
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/embedding"
+	"github.com/julienpequegnot/blogmon/internal/insight"
+	"github.com/julienpequegnot/blogmon/internal/markdown"
+	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/spf13/cobra"
+)
+
+var embedCmd = &cobra.Command{
+	Use:   "embed",
+	Short: "Compute embeddings for posts and insights",
+	Long: `Embeds post chunks and insights with the configured embedding model and
+stores the vectors in the database. Posts whose content or model changed since
+they were last embedded are embedded again.
+
+Embeddings are opt-in: set apis.embedding_model in the config to enable them.`,
+	RunE: runEmbed,
+}
+
+var (
+	embedLimit int
+	embedForce bool
+)
+
+// insightBatchSize is the number of insights sent per embeddings request
+const insightBatchSize = 32
+
+func init() {
+	rootCmd.AddCommand(embedCmd)
+	embedCmd.Flags().IntVarP(&embedLimit, "limit", "l", 0, "Maximum posts to embed (0 for all)")
+	embedCmd.Flags().BoolVar(&embedForce, "force", false, "Embed everything again")
+}
+
+func runEmbed(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	embedder, err := newEmbedder(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Embedding with %s\n\n", embedder.Model())
+	posts, insights, err := embedStale(ctx, db, embedder, cfg.APIs.EmbeddingConcurrency, embedForce, embedLimit)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nEmbedded %d posts and %d insights\n", posts, insights)
+	if ctx.Err() != nil {
+		fmt.Println("Interrupted; the rest will be embedded on the next run.")
+	}
+	return nil
+}
+
+// errNoEmbeddingModel is returned by newEmbedder while embeddings aren't
+// enabled in the config
+var errNoEmbeddingModel = errors.New("no embedding model configured, set apis.embedding_model to enable embeddings")
+
+// newEmbedder creates the client for the configured embeddings endpoint
+func newEmbedder(cfg *config.Config) (*embedding.Client, error) {
+	if cfg.APIs.EmbeddingModel == "" {
+		return nil, errNoEmbeddingModel
+	}
+	baseURL := cfg.APIs.EmbeddingURL
+	if baseURL == "" {
+		baseURL = cfg.APIs.LLMURL
+	}
+	return embedding.NewClient(cfg.APIs.EmbeddingProvider, baseURL, cfg.APIs.EmbeddingModel, cfg.APIs.OpenAIKey, time.Minute)
+}
+
+// embedStale embeds posts and insights that have no vectors yet, or whose
+// content or embedding model changed. A zero limit embeds every such post.
+// It stops at the first error showing the endpoint or model is unavailable,
+// since every other post would fail the same way.
+func embedStale(ctx context.Context, db *database.DB, embedder embedding.Embedder, workers int, force bool, limit int) (int, int, error) {
+	embedRepo := embedding.NewRepository(db)
+	if _, err := embedRepo.DeleteOrphans(); err != nil {
+		return 0, 0, err
+	}
+
+	allPosts, err := post.NewRepository(db).ListContent()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	postPrints, err := embedRepo.Fingerprints(embedding.KindPost)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var stale []post.Post
+	for _, p := range allPosts {
+		fp := embedding.Fingerprint{Model: embedder.Model(), ContentHash: post.ContentHash(postEmbeddingText(p))}
+		if !force && postPrints[p.ID] == fp {
+			continue
+		}
+		stale = append(stale, p)
+		if limit > 0 && len(stale) == limit {
+			break
+		}
+	}
+
+	stageCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var unavailable error
+	var once sync.Once
+	embedded, err := processPosts(stageCtx, stale, workers, true, func(ctx context.Context, p post.Post) (string, error) {
+		msg, err := embedPost(ctx, embedRepo, embedder, p)
+		if errors.Is(err, embedding.ErrUnavailable) {
+			once.Do(func() {
+				unavailable = err
+				cancel()
+			})
+		}
+		return msg, err
+	})
+	if unavailable != nil {
+		return embedded, 0, unavailable
+	}
+	if err != nil || ctx.Err() != nil {
+		return embedded, 0, err
+	}
+
+	insights, err := embedInsights(ctx, db, embedder, force)
+	return embedded, insights, err
+}
+
+// postEmbeddingText is the content a post is embedded from
+func postEmbeddingText(p post.Post) string {
+	content := p.ContentClean
+	if content == "" {
+		content = markdown.FromHTML(p.ContentRaw)
+	}
+	return p.Title + "\n\n" + content
+}
+
+func embedPost(ctx context.Context, repo *embedding.Repository, embedder embedding.Embedder, p post.Post) (string, error) {
+	text := postEmbeddingText(p)
+	chunks := embedding.Chunk(text, embedding.ChunkWords)
+	if len(chunks) == 0 {
+		return "", skipError("no content")
+	}
+
+	// The title gives every chunk of the post its context
+	inputs := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		inputs[i] = chunk
+		if i > 0 {
+			inputs[i] = p.Title + "\n\n" + chunk
+		}
+	}
+
+	vectors, err := embedder.Embed(ctx, inputs)
+	if err != nil {
+		return "", err
+	}
+
+	if err := repo.Replace(embedding.KindPost, p.ID, embedder.Model(), post.ContentHash(text), chunks, vectors); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d chunks", len(chunks)), nil
+}
+
+// embedInsights embeds every insight without an up to date vector, in batches
+func embedInsights(ctx context.Context, db *database.DB, embedder embedding.Embedder, force bool) (int, error) {
+	embedRepo := embedding.NewRepository(db)
+	prints, err := embedRepo.Fingerprints(embedding.KindInsight)
+	if err != nil {
+		return 0, err
+	}
+
+	all, err := insight.NewRepository(db).ListByType("", "", -1)
+	if err != nil {
+		return 0, err
+	}
+
+	var stale []insight.Insight
+	for _, ins := range all {
+		fp := embedding.Fingerprint{Model: embedder.Model(), ContentHash: post.ContentHash(insightEmbeddingText(ins))}
+		if force || prints[ins.ID] != fp {
+			stale = append(stale, ins)
+		}
+	}
+
+	embedded := 0
+	for start := 0; start < len(stale) && ctx.Err() == nil; start += insightBatchSize {
+		end := min(start+insightBatchSize, len(stale))
+		batch := stale[start:end]
+
+		texts := make([]string, len(batch))
+		for i, ins := range batch {
+			texts[i] = insightEmbeddingText(ins)
+		}
+
+		vectors, err := embedder.Embed(ctx, texts)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return embedded, err
+		}
+
+		for i, ins := range batch {
+			if err := embedRepo.Replace(embedding.KindInsight, ins.ID, embedder.Model(), post.ContentHash(texts[i]),
+				texts[i:i+1], vectors[i:i+1]); err != nil {
+				return embedded, err
+			}
+			embedded++
+		}
+	}
+	return embedded, nil
+}
+
+// insightEmbeddingText is the content an insight is embedded from
+func insightEmbeddingText(ins insight.Insight) string {
+	if ins.Detail != "" && ins.Type == insight.TypeDefinition {
+		return ins.Detail + ": " + ins.Content
+	}
+	return ins.Content
+}
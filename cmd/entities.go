@@ -0,0 +1,149 @@
+// cmd/entities.go
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/blogmon/internal/config"
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/entity"
+	"github.com/julienpequegnot/blogmon/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+var entitiesCmd = &cobra.Command{
+	Use:   "entities",
+	Short: "List the people, projects, libraries and companies posts mention most",
+	Long: `Entities are extracted with insights. Names and aliases are matched regardless
+of case and punctuation, so "Postgres" and "PostgreSQL" are one entity once a
+post uses both names.`,
+	RunE: runEntities,
+}
+
+var entityCmd = &cobra.Command{
+	Use:   "entity <name>",
+	Short: "Show the posts and insights about an entity over time",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runEntity,
+}
+
+var (
+	entitiesType  string
+	entitiesLimit int
+)
+
+func init() {
+	rootCmd.AddCommand(entitiesCmd, entityCmd)
+	entitiesCmd.Flags().StringVarP(&entitiesType, "type", "t", "", "Entity type: "+strings.Join(entity.Types, ", "))
+	entitiesCmd.Flags().IntVarP(&entitiesLimit, "limit", "l", 20, "Maximum entities to show")
+}
+
+func runEntities(cmd *cobra.Command, args []string) error {
+	typ := ""
+	if entitiesType != "" {
+		if typ = entity.NormalizeType(entitiesType); typ == "" {
+			return fmt.Errorf("invalid entity type: %s (valid types: %s)", entitiesType, strings.Join(entity.Types, ", "))
+		}
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	top, err := entity.NewRepository(db).Top(typ, entitiesLimit)
+	if err != nil {
+		return err
+	}
+
+	if len(top) == 0 {
+		fmt.Println("No entities found. Run 'blogmon extract' first.")
+		if prompts, err := prompt.Load(prompt.Dir(config.Dir())); err == nil {
+			fmt.Printf("Posts extracted with an earlier prompt need: blogmon extract --reextract --where version!=%s\n",
+				prompts.Version(prompt.Extraction))
+		}
+		return nil
+	}
+
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	typeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+
+	fmt.Println(headerStyle.Render(fmt.Sprintf("%-30s %-8s %6s %9s  %s", "ENTITY", "TYPE", "POSTS", "MENTIONS", "LAST SEEN")))
+	for _, e := range top {
+		lastSeen := ""
+		if e.LastSeen != nil {
+			lastSeen = e.LastSeen.Format("2006-01-02")
+		}
+		fmt.Printf("%-30s %s %6d %9d  %s\n", truncateLinkTitle(e.Name, 30), typeStyle.Render(fmt.Sprintf("%-8s", e.Type)),
+			e.Posts, e.Mentions, lastSeen)
+	}
+
+	fmt.Println("\nDetails with: blogmon entity <name>")
+	return nil
+}
+
+func runEntity(cmd *cobra.Command, args []string) error {
+	name := strings.Join(args, " ")
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	repo := entity.NewRepository(db)
+	e, err := repo.Find(name)
+	if errors.Is(err, sql.ErrNoRows) {
+		fmt.Printf("No entity named %s. See 'blogmon entities'.\n", name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	posts, err := repo.Posts(e.ID)
+	if err != nil {
+		return err
+	}
+	insights, err := repo.Insights(e)
+	if err != nil {
+		return err
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	idStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	typeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+
+	fmt.Printf("%s %s\n", titleStyle.Render(e.Name), labelStyle.Render("("+e.Type+")"))
+	if len(e.Aliases) > 0 {
+		fmt.Printf("%s %s\n", labelStyle.Render("Also known as:"), strings.Join(e.Aliases, ", "))
+	}
+
+	fmt.Printf("\n%s\n", labelStyle.Render(fmt.Sprintf("POSTS (%d):", len(posts))))
+	for _, p := range posts {
+		date := "          "
+		if p.PublishedAt != nil {
+			date = p.PublishedAt.Format("2006-01-02")
+		}
+		fmt.Printf("%s %s %s\n", date, idStyle.Render(fmt.Sprintf("[%d]", p.PostID)), p.Title)
+		if p.Context != "" {
+			fmt.Printf("           %s\n", labelStyle.Render(p.Context))
+		}
+	}
+
+	if len(insights) > 0 {
+		fmt.Printf("\n%s\n", labelStyle.Render(fmt.Sprintf("INSIGHTS (%d):", len(insights))))
+		for _, ins := range insights {
+			fmt.Printf("%s %s\n", typeStyle.Render(strings.ToUpper(ins.Type)), idStyle.Render(fmt.Sprintf("[%d] %s", ins.PostID, ins.PostTitle)))
+			printInsightBody(ins.Insight)
+		}
+	}
+
+	return nil
+}
@@ -2,18 +2,32 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
-	"regexp"
+	"os"
+	"os/signal"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/julienpequegnot/blogmon/internal/config"
 	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/entity"
+	"github.com/julienpequegnot/blogmon/internal/graph"
 	"github.com/julienpequegnot/blogmon/internal/insight"
+	"github.com/julienpequegnot/blogmon/internal/job"
 	"github.com/julienpequegnot/blogmon/internal/llm"
+	"github.com/julienpequegnot/blogmon/internal/markdown"
+	"github.com/julienpequegnot/blogmon/internal/novelty"
 	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/julienpequegnot/blogmon/internal/prompt"
 	"github.com/julienpequegnot/blogmon/internal/reference"
+	"github.com/julienpequegnot/blogmon/internal/topic"
+	"github.com/julienpequegnot/blogmon/internal/usage"
+	"github.com/julienpequegnot/blogmon/internal/worker"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
 
@@ -25,14 +39,24 @@ var extractCmd = &cobra.Command{
 }
 
 var (
-	extractLimit      int
-	extractSkipErrors bool
+	extractLimit       int
+	extractSkipErrors  bool
+	extractReextract   bool
+	extractWhere       []string
+	extractSince       string
+	extractForce       bool
+	extractConcurrency int
 )
 
 func init() {
 	rootCmd.AddCommand(extractCmd)
 	extractCmd.Flags().IntVarP(&extractLimit, "limit", "l", 10, "Maximum posts to process")
 	extractCmd.Flags().BoolVar(&extractSkipErrors, "skip-errors", false, "Continue on extraction errors")
+	extractCmd.Flags().BoolVar(&extractReextract, "reextract", false, "Redo extraction for already processed posts")
+	extractCmd.Flags().StringArrayVar(&extractWhere, "where", nil, "Re-extract posts matching model=/!=<name> or version=/!=<n> (repeatable)")
+	extractCmd.Flags().StringVar(&extractSince, "since", "", "Re-extract posts published since date (YYYY-MM-DD)")
+	extractCmd.Flags().BoolVar(&extractForce, "force", false, "Re-extract even when model, prompt and content are unchanged")
+	extractCmd.Flags().IntVarP(&extractConcurrency, "concurrency", "c", 0, "Parallel LLM requests (0 = use config)")
 }
 
 func runExtract(cmd *cobra.Command, args []string) error {
@@ -41,6 +65,10 @@ func runExtract(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if !extractReextract && (len(extractWhere) > 0 || extractSince != "") {
+		return fmt.Errorf("--where and --since require --reextract")
+	}
+
 	db, err := database.New(config.DBPath())
 	if err != nil {
 		return err
@@ -48,89 +76,405 @@ func runExtract(cmd *cobra.Command, args []string) error {
 	defer db.Close()
 
 	postRepo := post.NewRepository(db)
-	insightRepo := insight.NewRepository(db)
-	refRepo := reference.NewRepository(db)
 
-	// Get unextracted posts
-	posts, err := postRepo.GetUnextracted(extractLimit)
+	// Initialize LLM client
+	llmClient, err := newLLMClient(cfg, db)
 	if err != nil {
 		return err
 	}
 
-	if len(posts) == 0 {
+	// Get posts to extract
+	var posts []post.Post
+	if extractReextract {
+		posts, err = getReextractPosts(postRepo, llmClient)
+	} else {
+		posts, err = postRepo.GetUnextracted(extractLimit)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Get posts whose summary is missing or outdated
+	stale, err := postRepo.GetStaleSummaries(extractLimit)
+	if err != nil {
+		return err
+	}
+
+	if len(posts) == 0 && len(stale) == 0 {
 		fmt.Println("No unprocessed posts found.")
 		return nil
 	}
 
-	fmt.Printf("Found %d posts to process\n\n", len(posts))
+	fmt.Printf("Found %d posts to process", len(posts))
+	if len(stale) > 0 {
+		fmt.Printf(" and %d summaries to refresh", len(stale))
+	}
+	fmt.Print("\n\n")
 
-	// Initialize LLM client
-	llmClient := llm.NewClient(
-		"http://localhost:11434",
-		cfg.APIs.LLMModel,
-		2*time.Minute,
-	)
-
-	processed := 0
-	for _, p := range posts {
-		fmt.Printf("Extracting: %s\n", p.Title)
-
-		// Clean HTML content
-		cleanContent := stripHTMLTags(p.ContentRaw)
-		if len(cleanContent) < 100 {
-			fmt.Printf("  Skipping (content too short)\n")
-			continue
+	workers := cfg.APIs.LLMConcurrency
+	if extractConcurrency > 0 {
+		workers = extractConcurrency
+	}
+
+	// Cancel in-flight LLM calls on Ctrl+C; stored extractions are transactional
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	jobRepo := job.NewRepository(db)
+	extract := func(ctx context.Context, p post.Post) (string, error) {
+		return extractPost(ctx, db, llmClient, p)
+	}
+	if !extractReextract {
+		extract = trackJob(jobRepo, job.StageExtract, extract)
+	}
+
+	processed, err := processPosts(ctx, posts, workers, extractSkipErrors, extract)
+	if err != nil {
+		return err
+	}
+
+	if len(posts) > 0 {
+		fmt.Printf("\nProcessed %d posts\n", processed)
+	}
+	if processed > 0 {
+		// Cleaned content replaces the titles new posts were indexed with
+		if _, err := novelty.NewRepository(db).Update(); err != nil {
+			fmt.Printf("Failed to update novelty index: %v\n", err)
+		}
+	}
+	if ctx.Err() != nil {
+		fmt.Println("Interrupted; remaining posts will be processed on the next run.")
+		return nil
+	}
+
+	if len(stale) > 0 {
+		fmt.Printf("\nRefreshing %d summaries\n\n", len(stale))
+
+		refreshed, err := processPosts(ctx, stale, workers, extractSkipErrors, trackJob(jobRepo, job.StageSummary, func(ctx context.Context, p post.Post) (string, error) {
+			return "summary updated", refreshSummary(ctx, llmClient, postRepo, p)
+		}))
+		if err != nil {
+			return err
 		}
 
-		// Truncate for LLM context
-		if len(cleanContent) > 8000 {
-			cleanContent = cleanContent[:8000]
+		fmt.Printf("\nRefreshed %d summaries\n", refreshed)
+		if ctx.Err() != nil {
+			fmt.Println("Interrupted; remaining summaries will be refreshed on the next run.")
 		}
+	}
+	return nil
+}
+
+// skipError marks a post that was deliberately left untouched
+type skipError string
+
+func (e skipError) Error() string {
+	return string(e)
+}
+
+// processPosts runs task for every post on a bounded worker pool while
+// showing progress. Unless skipErrors is set, the first failure stops the
+// batch and is returned. Posts interrupted by cancellation are not counted.
+func processPosts(ctx context.Context, posts []post.Post, workers int, skipErrors bool, task func(ctx context.Context, p post.Post) (string, error)) (int, error) {
+	if len(posts) == 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	progress := worker.NewProgress(os.Stdout, len(posts), isatty.IsTerminal(os.Stdout.Fd()))
+	var mu sync.Mutex
+	var firstErr error
+
+	worker.Run(ctx, workers, posts, func(ctx context.Context, p post.Post) {
+		msg, err := task(ctx, p)
+
+		var skip skipError
+		switch {
+		case err == nil:
+			progress.Done(fmt.Sprintf("✓ %s: %s", p.Title, msg))
+		case errors.As(err, &skip):
+			progress.Skipped(fmt.Sprintf("- %s: skipped (%s)", p.Title, skip))
+		case ctx.Err() != nil:
+			// Interrupted; the post is left for the next run
+		default:
+			progress.Failed(fmt.Sprintf("✗ %s: %v", p.Title, err))
+			if !skipErrors {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				cancel()
+			}
+		}
+	})
+
+	progress.Finish()
+	return progress.Succeeded(), firstErr
+}
+
+// trackJob records the outcome of task as the post's job state for stage.
+// Failures are retried with backoff on later runs until dead-lettered.
+func trackJob(jobRepo *job.Repository, stage string, task func(ctx context.Context, p post.Post) (string, error)) func(ctx context.Context, p post.Post) (string, error) {
+	return func(ctx context.Context, p post.Post) (string, error) {
+		msg, err := task(ctx, p)
+
+		// Job updates are writes too; concurrent ones would hit a busy database
+		storeMu.Lock()
+		defer storeMu.Unlock()
+
+		var skip skipError
+		switch {
+		case err == nil:
+			if err := jobRepo.Done(p.ID, stage); err != nil {
+				return "", err
+			}
+		case errors.As(err, &skip):
+			if err := jobRepo.Skip(p.ID, stage, string(skip)); err != nil {
+				return "", err
+			}
+		case ctx.Err() != nil:
+			// Interrupted, not failed
+		default:
+			j, ferr := jobRepo.Fail(p.ID, stage, err)
+			if ferr != nil {
+				return "", errors.Join(err, ferr)
+			}
+			if j.Status == job.StatusDead {
+				return "", fmt.Errorf("%w (gave up after %d attempts, see 'blogmon jobs')", err, j.Attempts)
+			}
+		}
+		return msg, err
+	}
+}
+
+// extractPost runs LLM extraction for one post and stores the result
+func extractPost(ctx context.Context, db *database.DB, llmClient *llm.Client, p post.Post) (string, error) {
+	// Clean HTML content
+	cleanContent := markdown.FromHTML(p.ContentRaw)
+	if len(cleanContent) < 100 {
+		return "", skipError("content too short")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	result, err := llmClient.ExtractInsights(ctx, p.Title, truncateForLLM(cleanContent))
+	if err != nil {
+		return "", err
+	}
+
+	refs := mergeReferences(p, result)
+	if err := storeExtraction(db, p.ID, cleanContent, llmClient, result, refs); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d takeaways, %d quotes, %d definitions, %d code examples, %d references, %d topics",
+		len(result.Takeaways), len(result.Quotes), len(result.Definitions), len(result.CodeExamples),
+		len(refs), len(result.Topics)), nil
+}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-		result, err := llmClient.ExtractInsights(ctx, p.Title, cleanContent)
-		cancel()
+// newLLMClient creates the client for the configured LLM endpoint, using any
+// custom prompt templates found in the blogmon home directory. Every call is
+// recorded in the database for 'blogmon stats llm'.
+func newLLMClient(cfg *config.Config, db *database.DB) (*llm.Client, error) {
+	prompts, err := prompt.Load(prompt.Dir(config.Dir()))
+	if err != nil {
+		return nil, err
+	}
+
+	client := llm.NewClient(cfg.APIs.LLMURL, cfg.APIs.LLMModel, 2*time.Minute)
+	client.SetPrompts(prompts)
+	client.SetRecorder(usage.NewRepository(db))
+	return client, nil
+}
+
+// extractionCurrent reports whether a post was extracted from its current
+// content with the model and extraction prompt in use
+func extractionCurrent(llmClient *llm.Client, p post.Post) bool {
+	return p.ExtractModel == llmClient.Model() &&
+		p.ExtractPromptVersion == llmClient.PromptVersion(prompt.Extraction) &&
+		p.ExtractHash == post.ContentHash(markdown.FromHTML(p.ContentRaw))
+}
 
+func getReextractPosts(postRepo *post.Repository, llmClient *llm.Client) ([]post.Post, error) {
+	var conditions []post.ExtractionCondition
+	for _, expr := range extractWhere {
+		c, err := post.ParseExtractionCondition(expr)
 		if err != nil {
-			fmt.Printf("  Error: %v\n", err)
-			if !extractSkipErrors {
+			return nil, err
+		}
+		conditions = append(conditions, c)
+	}
+
+	var since *time.Time
+	if extractSince != "" {
+		t, err := time.Parse("2006-01-02", extractSince)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --since date: %s (expected YYYY-MM-DD)", extractSince)
+		}
+		since = &t
+	}
+
+	// Up-to-date posts are passed over unless forced, so they don't take the
+	// place of older posts that need extraction
+	var skip func(post.Post) bool
+	if !extractForce {
+		skip = func(p post.Post) bool { return extractionCurrent(llmClient, p) }
+	}
+	return postRepo.GetForReextract(conditions, since, extractLimit, skip)
+}
+
+// storeMu serializes writes from concurrent extraction workers, including
+// their job updates
+var storeMu sync.Mutex
+
+// storeExtraction atomically replaces everything derived from a previous
+// extraction of the post with the new result
+func storeExtraction(db *database.DB, postID int64, content string, llmClient *llm.Client, result *llm.ExtractionResult, refs []reference.Reference) error {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	return db.Tx(func(tx *database.DB) error {
+		postRepo := post.NewRepository(tx)
+		insightRepo := insight.NewRepository(tx)
+		refRepo := reference.NewRepository(tx)
+		topicRepo := topic.NewRepository(tx)
+
+		if err := insightRepo.DeleteForPost(postID); err != nil {
+			return err
+		}
+		if err := refRepo.DeleteForPost(postID); err != nil {
+			return err
+		}
+
+		if err := saveInsights(insightRepo, postID, result); err != nil {
+			return err
+		}
+
+		for _, ref := range refs {
+			if _, err := refRepo.AddWithOrigin(postID, ref.URL, ref.Title, ref.Context, isBlogURL(ref.URL), ref.Origin); err != nil {
 				return err
 			}
-			continue
 		}
 
-		// Save insights
-		for i, takeaway := range result.Takeaways {
-			importance := 5 - i // First takeaway most important
-			if importance < 1 {
-				importance = 1
+		if err := topicRepo.SetForPost(postID, graph.NormalizeTopics(result.Topics)); err != nil {
+			return err
+		}
+
+		if err := entity.NewRepository(tx).ReplaceForPost(postID, entityMentions(content, result)); err != nil {
+			return err
+		}
+
+		hash := post.ContentHash(content)
+		if result.Summary != "" {
+			if err := postRepo.UpdateSummary(postID, result.Summary, hash); err != nil {
+				return err
 			}
-			insightRepo.Add(p.ID, "takeaway", takeaway, importance)
 		}
+		if err := postRepo.UpdateContentClean(postID, content); err != nil {
+			return err
+		}
+		return postRepo.MarkExtracted(postID, llmClient.Model(), llmClient.PromptVersion(prompt.Extraction), hash)
+	})
+}
 
-		// Save references
-		for _, ref := range result.References {
-			isBlog := isBlogURL(ref.URL)
-			refRepo.Add(p.ID, ref.URL, ref.Title, ref.Context, isBlog)
+// mergeReferences combines the links found in the post HTML with the
+// references reported by the LLM, preferring the anchors
+func mergeReferences(p post.Post, result *llm.ExtractionResult) []reference.Reference {
+	llmRefs := make([]reference.Reference, 0, len(result.References))
+	for _, ref := range result.References {
+		llmRefs = append(llmRefs, reference.Reference{URL: ref.URL, Title: ref.Title, Context: ref.Context})
+	}
+	return reference.MergeLinks(reference.ExtractLinks(p.ContentRaw, p.URL), llmRefs)
+}
+
+// entityMentions types the entities reported by the LLM and locates them in
+// the post content. Entities of unknown types are dropped.
+func entityMentions(content string, result *llm.ExtractionResult) []entity.Mention {
+	var mentions []entity.Mention
+	for _, e := range result.Entities {
+		typ := entity.NormalizeType(e.Type)
+		if typ == "" || strings.TrimSpace(e.Name) == "" {
+			continue
 		}
+		m := entity.Mention{Name: strings.TrimSpace(e.Name), Type: typ, Aliases: e.Aliases}
+		m.Count, m.Context = entity.Locate(content, m.Names())
+		mentions = append(mentions, m)
+	}
+	return mentions
+}
 
-		// Update post with clean content
-		wordCount := len(strings.Fields(cleanContent))
-		postRepo.UpdateContentClean(p.ID, cleanContent, wordCount)
+// saveInsights stores every insight type returned by the LLM
+func saveInsights(repo *insight.Repository, postID int64, result *llm.ExtractionResult) error {
+	for i, takeaway := range result.Takeaways {
+		importance := 5 - i // First takeaway most important
+		if importance < 1 {
+			importance = 1
+		}
+		if _, err := repo.Add(postID, insight.TypeTakeaway, takeaway, importance); err != nil {
+			return err
+		}
+	}
+
+	for _, q := range result.Quotes {
+		if q.Text == "" {
+			continue
+		}
+		if _, err := repo.AddWithDetail(postID, insight.TypeQuote, q.Text, q.Attribution, 3); err != nil {
+			return err
+		}
+	}
+
+	for _, d := range result.Definitions {
+		if d.Term == "" || d.Definition == "" {
+			continue
+		}
+		if _, err := repo.AddWithDetail(postID, insight.TypeDefinition, d.Definition, d.Term, 3); err != nil {
+			return err
+		}
+	}
 
-		fmt.Printf("  Extracted %d takeaways, %d references\n", len(result.Takeaways), len(result.References))
-		processed++
+	for _, c := range result.CodeExamples {
+		if c.Code == "" {
+			continue
+		}
+		if _, err := repo.AddWithDetail(postID, insight.TypeCodeExample, c.Code, strings.ToLower(c.Language), 2); err != nil {
+			return err
+		}
 	}
 
-	fmt.Printf("\nProcessed %d posts\n", processed)
 	return nil
 }
 
-func stripHTMLTags(s string) string {
-	re := regexp.MustCompile(`<[^>]*>`)
-	clean := re.ReplaceAllString(s, " ")
-	clean = strings.Join(strings.Fields(clean), " ")
-	return strings.TrimSpace(clean)
+// refreshSummary regenerates the TL;DR of a post from its current content
+func refreshSummary(ctx context.Context, llmClient *llm.Client, postRepo *post.Repository, p post.Post) error {
+	content := markdown.FromHTML(p.ContentRaw)
+	if content == "" {
+		return skipError("no content")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	summary, err := llmClient.Summarize(ctx, p.Title, truncateForLLM(content))
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	if err := postRepo.UpdateSummary(p.ID, summary, post.ContentHash(content)); err != nil {
+		return err
+	}
+	return postRepo.UpdateContentClean(p.ID, content)
+}
+
+// truncateForLLM cuts cleaned content to fit the LLM context
+func truncateForLLM(content string) string {
+	return markdown.TruncateBytes(content, 8000)
 }
 
 func isBlogURL(rawURL string) bool {
@@ -8,7 +8,9 @@ import (
 	"github.com/julienpequegnot/blogmon/internal/config"
 	"github.com/julienpequegnot/blogmon/internal/database"
 	"github.com/julienpequegnot/blogmon/internal/feed"
+	"github.com/julienpequegnot/blogmon/internal/novelty"
 	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/julienpequegnot/blogmon/internal/snippet"
 	"github.com/julienpequegnot/blogmon/internal/source"
 	"github.com/spf13/cobra"
 )
@@ -41,6 +43,7 @@ func runFetch(cmd *cobra.Command, args []string) error {
 
 	srcRepo := source.NewRepository(db)
 	postRepo := post.NewRepository(db)
+	snippetRepo := snippet.NewRepository(db)
 
 	sources, err := srcRepo.List()
 	if err != nil {
@@ -83,14 +86,21 @@ func runFetch(cmd *cobra.Command, args []string) error {
 			for _, p := range posts {
 				exists, _ := postRepo.Exists(p.URL)
 				if exists {
+					// Keep content current so stale summaries get regenerated
+					if changed, _ := postRepo.UpdateContentRaw(p.URL, p.Content); changed {
+						if id, err := postRepo.GetIDByURL(p.URL); err == nil {
+							snippetRepo.ReplaceForPost(id, snippet.Extract(p.Content))
+						}
+					}
 					continue
 				}
 
-				_, err := postRepo.Add(s.ID, p.URL, p.Title, p.Author, p.PublishedAt, p.Content)
+				saved, err := postRepo.Add(s.ID, p.URL, p.Title, p.Author, p.PublishedAt, p.Content)
 				if err != nil {
 					fmt.Printf("  Failed to save: %s\n", p.Title)
 					continue
 				}
+				snippetRepo.ReplaceForPost(saved.ID, snippet.Extract(p.Content))
 				newCount++
 			}
 
@@ -107,5 +117,10 @@ func runFetch(cmd *cobra.Command, args []string) error {
 	wg.Wait()
 
 	fmt.Printf("\nTotal: %d new posts fetched\n", totalNew)
+	if totalNew > 0 {
+		if _, err := novelty.NewRepository(db).Update(); err != nil {
+			fmt.Printf("Failed to update novelty index: %v\n", err)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,113 @@
+// cmd/insights.go
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/blogmon/internal/config"
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/graph"
+	"github.com/julienpequegnot/blogmon/internal/insight"
+	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/spf13/cobra"
+)
+
+var insightsCmd = &cobra.Command{
+	Use:   "insights",
+	Short: "Browse extracted insights across all posts",
+	Long:  `Lists takeaways, quotes, definitions, and code examples from the whole knowledge base.`,
+	RunE:  runInsights,
+}
+
+var (
+	insightsType  string
+	insightsTopic string
+	insightsLimit int
+)
+
+func init() {
+	rootCmd.AddCommand(insightsCmd)
+	insightsCmd.Flags().StringVarP(&insightsType, "type", "t", "", "Insight type: "+strings.Join(insight.Types, ", "))
+	insightsCmd.Flags().StringVar(&insightsTopic, "topic", "", "Only show insights from posts tagged with this topic")
+	insightsCmd.Flags().IntVarP(&insightsLimit, "limit", "l", 20, "Maximum insights to show")
+}
+
+func runInsights(cmd *cobra.Command, args []string) error {
+	if insightsType != "" && !isInsightType(insightsType) {
+		return fmt.Errorf("invalid insight type: %s (valid types: %s)", insightsType, strings.Join(insight.Types, ", "))
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	topic := ""
+	if insightsTopic != "" {
+		topic = graph.NormalizeTopic(insightsTopic)
+	}
+
+	insightRepo := insight.NewRepository(db)
+	insights, err := insightRepo.ListByType(insightsType, topic, insightsLimit)
+	if err != nil {
+		return err
+	}
+
+	if len(insights) == 0 {
+		fmt.Println("No insights found. Run 'blogmon extract' first.")
+		return nil
+	}
+
+	typeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	idStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	postRepo := post.NewRepository(db)
+	titles := make(map[int64]string)
+
+	for _, ins := range insights {
+		title, ok := titles[ins.PostID]
+		if !ok {
+			if p, err := postRepo.Get(ins.PostID); err == nil {
+				title = p.Title
+			}
+			titles[ins.PostID] = title
+		}
+
+		fmt.Printf("%s %s\n", typeStyle.Render(strings.ToUpper(ins.Type)), idStyle.Render(fmt.Sprintf("[%d] %s", ins.PostID, title)))
+		printInsightBody(ins)
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// printInsightBody prints an insight formatted for its type
+func printInsightBody(ins insight.Insight) {
+	detailStyle := lipgloss.NewStyle().Bold(true)
+
+	switch ins.Type {
+	case insight.TypeQuote:
+		fmt.Printf("  “%s”\n", ins.Content)
+		if ins.Detail != "" {
+			fmt.Printf("    — %s\n", ins.Detail)
+		}
+	case insight.TypeDefinition:
+		fmt.Printf("  %s: %s\n", detailStyle.Render(ins.Detail), ins.Content)
+	case insight.TypeCodeExample:
+		printCodeExample(ins)
+	default:
+		fmt.Printf("  • %s\n", ins.Content)
+	}
+}
+
+func isInsightType(t string) bool {
+	for _, valid := range insight.Types {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,267 @@
+// cmd/interests.go
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/blogmon/internal/config"
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/interest"
+	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/spf13/cobra"
+)
+
+var interestsCmd = &cobra.Command{
+	Use:   "interests",
+	Short: "Manage the topics posts are scored for relevance against",
+	Long: `Interests are stored in the database and mirrored to the interests section
+of config.yaml, so either can be edited: when they differ, the one changed last
+wins. A config.yaml without interests never clears the stored ones.
+
+Adding, removing or reweighting an interest rescores the relevance of every
+scored post, without network calls.`,
+	RunE: runInterestsList,
+}
+
+var interestsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List interests",
+	RunE:  runInterestsList,
+}
+
+var interestsAddCmd = &cobra.Command{
+	Use:   "add <topic>",
+	Short: "Add an interest, or update one with the same topic",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runInterestsAdd,
+}
+
+var interestsRmCmd = &cobra.Command{
+	Use:   "rm <topic>",
+	Short: "Remove an interest",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runInterestsRm,
+}
+
+var interestsSetWeightCmd = &cobra.Command{
+	Use:   "set-weight <topic> <weight>",
+	Short: "Change the weight of an interest",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runInterestsSetWeight,
+}
+
+var (
+	interestWeight   float64
+	interestKeywords []string
+)
+
+func init() {
+	rootCmd.AddCommand(interestsCmd)
+	interestsCmd.AddCommand(interestsListCmd)
+	interestsCmd.AddCommand(interestsAddCmd)
+	interestsCmd.AddCommand(interestsRmCmd)
+	interestsCmd.AddCommand(interestsSetWeightCmd)
+	interestsAddCmd.Flags().Float64VarP(&interestWeight, "weight", "w", 1.0, "Weight of the interest")
+	interestsAddCmd.Flags().StringSliceVarP(&interestKeywords, "keywords", "k", nil, "Other words that count as the topic (comma-separated)")
+}
+
+// syncInterests reconciles the stored interests with config.yaml and makes
+// cfg.Interests the interests in effect. Interests edited by hand in
+// config.yaml rescore relevance like the interests commands do.
+func syncInterests(db *database.DB, cfg *config.Config) error {
+	modified, err := config.ModTime()
+	if err != nil {
+		return err
+	}
+
+	repo := interest.NewRepository(db)
+	before, err := repo.List()
+	if err != nil {
+		return err
+	}
+
+	interests, result, err := repo.Sync(cfg.Interests, modified)
+	if err != nil {
+		return fmt.Errorf("failed to sync interests: %w", err)
+	}
+	cfg.Interests = interests
+	switch result {
+	case interest.TableChanged:
+		return config.Save(cfg)
+	case interest.ConfigChanged:
+		// Importing into an empty table changes nothing: scores were already
+		// computed from config.yaml
+		if len(before) > 0 {
+			return rescoreInterests(db, cfg, before)
+		}
+	}
+	return nil
+}
+
+// changeInterests applies a change to the stored interests, mirrors it to
+// config.yaml and rescores relevance
+func changeInterests(change func(repo *interest.Repository) error) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := syncInterests(db, cfg); err != nil {
+		return err
+	}
+
+	before := cfg.Interests
+	repo := interest.NewRepository(db)
+	if err := change(repo); err != nil {
+		return err
+	}
+
+	if cfg.Interests, err = repo.List(); err != nil {
+		return err
+	}
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return rescoreInterests(db, cfg, before)
+}
+
+// rescoreInterests rescores the relevance of the posts affected by a change
+// from the interests before to cfg.Interests
+func rescoreInterests(db *database.DB, cfg *config.Config, before []config.Interest) error {
+	// Posts mentioning no interest, before or after the change, score 0 on
+	// keywords either way. The others all move: interest weights are
+	// normalized by their sum. Without interests every post is neutral.
+	opts := rescoreOptions{relevance: true}
+	if len(before) > 0 && len(cfg.Interests) > 0 {
+		ids, err := post.NewRepository(db).IDsMentioning(interestTerms(before, cfg.Interests))
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		opts.postIDs = ids
+	}
+
+	n, err := rescorePosts(db, cfg, opts)
+	if err != nil {
+		return fmt.Errorf("failed to rescore relevance: %w", err)
+	}
+	if n > 0 {
+		fmt.Printf("Rescored relevance of %d posts\n", n)
+	}
+	return nil
+}
+
+// interestTerms lists the distinct topics and keywords of the interests
+func interestTerms(lists ...[]config.Interest) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, interests := range lists {
+		for _, in := range interests {
+			for _, term := range append([]string{in.Topic}, in.Keywords...) {
+				term = strings.ToLower(term)
+				if term != "" && !seen[term] {
+					seen[term] = true
+					terms = append(terms, term)
+				}
+			}
+		}
+	}
+	return terms
+}
+
+func runInterestsList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := syncInterests(db, cfg); err != nil {
+		return err
+	}
+
+	if len(cfg.Interests) == 0 {
+		fmt.Println("No interests yet. Add one with: blogmon interests add <topic> --weight 1.0")
+		return nil
+	}
+
+	topicStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("14"))
+	weightStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	keywordStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+
+	for _, i := range cfg.Interests {
+		line := fmt.Sprintf("%s %s", weightStyle.Render(fmt.Sprintf("%4.1f", i.Weight)), topicStyle.Render(i.Topic))
+		if len(i.Keywords) > 0 {
+			line += " " + keywordStyle.Render("("+strings.Join(i.Keywords, ", ")+")")
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func runInterestsAdd(cmd *cobra.Command, args []string) error {
+	topic := interest.NormalizeTopic(args[0])
+	if topic == "" {
+		return fmt.Errorf("topic must not be empty")
+	}
+	if interestWeight < 0 {
+		return fmt.Errorf("weight must not be negative, got %g", interestWeight)
+	}
+
+	return changeInterests(func(repo *interest.Repository) error {
+		if err := repo.Add(config.Interest{Topic: topic, Weight: interestWeight, Keywords: interestKeywords}); err != nil {
+			return err
+		}
+		fmt.Printf("Added interest %s (weight %g)\n", topic, interestWeight)
+		return nil
+	})
+}
+
+func runInterestsRm(cmd *cobra.Command, args []string) error {
+	return changeInterests(func(repo *interest.Repository) error {
+		if err := repo.Remove(args[0]); err != nil {
+			return interestError(args[0], err)
+		}
+		fmt.Printf("Removed interest %s\n", interest.NormalizeTopic(args[0]))
+		return nil
+	})
+}
+
+func runInterestsSetWeight(cmd *cobra.Command, args []string) error {
+	weight, err := strconv.ParseFloat(args[1], 64)
+	if err != nil || weight < 0 {
+		return fmt.Errorf("invalid weight: %s", args[1])
+	}
+
+	return changeInterests(func(repo *interest.Repository) error {
+		if err := repo.SetWeight(args[0], weight); err != nil {
+			return interestError(args[0], err)
+		}
+		fmt.Printf("Set weight of %s to %g\n", interest.NormalizeTopic(args[0]), weight)
+		return nil
+	})
+}
+
+func interestError(topic string, err error) error {
+	if errors.Is(err, interest.ErrNotFound) {
+		return fmt.Errorf("no interest %q. See: blogmon interests list", interest.NormalizeTopic(topic))
+	}
+	return err
+}
@@ -0,0 +1,157 @@
+// cmd/jobs.go
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/blogmon/internal/config"
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/job"
+	"github.com/spf13/cobra"
+)
+
+var jobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "List failed and dead-lettered pipeline jobs",
+	Long: `Extraction and summaries are tracked per post. A failed post is retried on
+later runs after a backoff (15 minutes, doubling up to a day) and is
+dead-lettered after 5 failures. Posts skipped for having too little content are
+not retried until their content changes.`,
+	RunE: runJobs,
+}
+
+var jobsRetryCmd = &cobra.Command{
+	Use:   "retry [post-id...]",
+	Short: "Retry failed and dead-lettered jobs on the next run",
+	RunE:  runJobsRetry,
+}
+
+var (
+	jobsStage   string
+	jobsStatus  string
+	jobsLimit   int
+	jobsSkipped bool
+)
+
+func init() {
+	rootCmd.AddCommand(jobsCmd)
+	jobsCmd.AddCommand(jobsRetryCmd)
+	jobsCmd.PersistentFlags().StringVar(&jobsStage, "stage", "", "Only jobs of this stage: extract, summary")
+	jobsCmd.Flags().StringVar(&jobsStatus, "status", "", "Only jobs with this status: failed, dead, skipped, pending, done (default failed and dead)")
+	jobsCmd.Flags().IntVarP(&jobsLimit, "limit", "l", 20, "Maximum jobs to show")
+	jobsRetryCmd.Flags().BoolVar(&jobsSkipped, "skipped", false, "Also retry skipped jobs")
+}
+
+func runJobs(cmd *cobra.Command, args []string) error {
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	jobRepo := job.NewRepository(db)
+
+	counts, err := jobRepo.Counts()
+	if err != nil {
+		return err
+	}
+	if len(counts) == 0 {
+		fmt.Println("No jobs recorded yet. Run 'blogmon extract' first.")
+		return nil
+	}
+
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	idStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	failedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	deadStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	statuses := []string{job.StatusDone, job.StatusPending, job.StatusFailed, job.StatusSkipped, job.StatusDead}
+	fmt.Println(headerStyle.Render(fmt.Sprintf("%-10s %8s %8s %8s %8s %8s", "STAGE", "DONE", "PENDING", "FAILED", "SKIPPED", "DEAD")))
+	for _, stage := range []string{job.StageExtract, job.StageSummary} {
+		fmt.Printf("%-10s", stage)
+		for _, status := range statuses {
+			fmt.Printf(" %8d", counts[stage][status])
+		}
+		fmt.Println()
+	}
+
+	filter := []string{job.StatusFailed, job.StatusDead}
+	if jobsStatus != "" {
+		filter = []string{jobsStatus}
+	}
+	jobs, err := jobRepo.List(jobsStage, filter, jobsLimit)
+	if err != nil {
+		return err
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	fmt.Println()
+	for _, j := range jobs {
+		status := j.Status
+		switch j.Status {
+		case job.StatusFailed:
+			status = failedStyle.Render(status)
+		case job.StatusDead:
+			status = deadStyle.Render(status)
+		}
+
+		detail := ""
+		if j.Attempts > 0 {
+			detail = fmt.Sprintf(", %d/%d attempts", j.Attempts, job.MaxAttempts)
+		}
+		if j.NextRetryAt != nil {
+			detail += ", retry " + formatRetry(time.Until(*j.NextRetryAt))
+		}
+
+		fmt.Printf("%s %s %s %s%s\n", idStyle.Render(fmt.Sprintf("[%d]", j.PostID)), j.Stage, status,
+			truncateLinkTitle(j.PostTitle, 50), detail)
+		if j.LastError != "" {
+			fmt.Printf("    %s\n", idStyle.Render(truncateLinkTitle(j.LastError, 100)))
+		}
+	}
+
+	fmt.Println("\nRetry with: blogmon jobs retry [post-id...]")
+	return nil
+}
+
+func formatRetry(d time.Duration) string {
+	if d <= 0 {
+		return "on next run"
+	}
+	return "in " + d.Round(time.Minute).String()
+}
+
+func runJobsRetry(cmd *cobra.Command, args []string) error {
+	var postIDs []int64
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid post ID: %s", arg)
+		}
+		postIDs = append(postIDs, id)
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	statuses := []string{job.StatusFailed, job.StatusDead}
+	if jobsSkipped {
+		statuses = append(statuses, job.StatusSkipped)
+	}
+
+	n, err := job.NewRepository(db).Retry(jobsStage, statuses, postIDs)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Queued %d jobs; they run with the next 'blogmon extract' or daemon cycle.\n", n)
+	return nil
+}
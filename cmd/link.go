@@ -1,33 +1,56 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/julienpequegnot/blogmon/internal/config"
 	"github.com/julienpequegnot/blogmon/internal/database"
 	"github.com/julienpequegnot/blogmon/internal/graph"
 	"github.com/julienpequegnot/blogmon/internal/insight"
 	"github.com/julienpequegnot/blogmon/internal/link"
+	"github.com/julienpequegnot/blogmon/internal/markdown"
 	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/julienpequegnot/blogmon/internal/prompt"
+	"github.com/julienpequegnot/blogmon/internal/topic"
+	"github.com/julienpequegnot/blogmon/internal/worker"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
 
 var linkCmd = &cobra.Command{
 	Use:   "link",
 	Short: "Build concept graph by linking related posts",
-	Long:  `Analyzes posts and creates links between those sharing similar topics.`,
-	RunE:  runLink,
+	Long: `Analyzes posts and creates links between those sharing similar topics.
+
+With --classify, the LLM then reads the summaries and takeaways of strongly
+linked pairs and labels how the newer post relates to the older one: agrees,
+contradicts, extends, responds-to or same-topic, with a one-line rationale.`,
+	RunE: runLink,
 }
 
 var (
 	linkMinSimilarity float64
 	linkRebuild       bool
+	linkClassify      bool
+	linkMinStrength   float64
+	linkLimit         int
+	linkReclassify    bool
 )
 
 func init() {
 	rootCmd.AddCommand(linkCmd)
 	linkCmd.Flags().Float64Var(&linkMinSimilarity, "min-similarity", 0.3, "Minimum similarity threshold for linking")
 	linkCmd.Flags().BoolVar(&linkRebuild, "rebuild", false, "Rebuild all links from scratch")
+	linkCmd.Flags().BoolVar(&linkClassify, "classify", false, "Have the LLM classify how strongly linked posts relate (agrees, contradicts, extends, responds-to, same-topic)")
+	linkCmd.Flags().Float64Var(&linkMinStrength, "min-strength", 0.5, "Minimum link strength for --classify")
+	linkCmd.Flags().IntVarP(&linkLimit, "limit", "l", 20, "Maximum pairs to classify")
+	linkCmd.Flags().BoolVar(&linkReclassify, "reclassify", false, "With --classify, classify already classified pairs again")
 }
 
 func runLink(cmd *cobra.Command, args []string) error {
@@ -40,6 +63,7 @@ func runLink(cmd *cobra.Command, args []string) error {
 	postRepo := post.NewRepository(db)
 	insightRepo := insight.NewRepository(db)
 	linkRepo := link.NewRepository(db)
+	topicRepo := topic.NewRepository(db)
 
 	// Get all posts
 	posts, err := postRepo.List(1000, 0)
@@ -54,6 +78,11 @@ func runLink(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Analyzing %d posts for topic similarity\n\n", len(posts))
 
+	storedTopics, err := topicRepo.ListAll()
+	if err != nil {
+		return err
+	}
+
 	// Extract topics for each post
 	postTopics := make(map[int64][]string)
 	for _, p := range posts {
@@ -65,13 +94,13 @@ func runLink(cmd *cobra.Command, args []string) error {
 			insightTexts = append(insightTexts, ins.Content)
 		}
 
-		// Extract topics from title + content + insights
+		// Prefer extracted topics, falling back to title + content + insights
 		content := p.Title + " " + p.ContentClean
 		if len(insightTexts) > 0 {
 			content += " " + fmt.Sprintf("%v", insightTexts)
 		}
 
-		topics := graph.ExtractTopics(content)
+		topics := graph.ResolveTopics(storedTopics[p.ID], content)
 		postTopics[p.ID] = topics
 
 		if len(topics) > 0 {
@@ -100,7 +129,7 @@ func runLink(cmd *cobra.Command, args []string) error {
 			if similarity >= linkMinSimilarity {
 				// Find shared topics for relationship description
 				sharedTopics := findSharedTopics(topicsA, topicsB)
-				relationship := "shared_topics:" + joinTopics(sharedTopics)
+				relationship := link.FormatRelationship(link.SharedTopics, joinTopics(sharedTopics))
 
 				if err := linkRepo.Upsert(postA.ID, postB.ID, relationship, similarity); err != nil {
 					fmt.Printf("Error linking posts: %v\n", err)
@@ -117,9 +146,135 @@ func runLink(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("\nCreated %d links\n", linksCreated)
+
+	if linkClassify {
+		return classifyLinks(db)
+	}
 	return nil
 }
 
+// linkPair is a pair of linked posts to classify, oldest first
+type linkPair struct {
+	older, newer *post.Post
+	strength     float64
+}
+
+// classifyLinks has the LLM label the relationship of strongly linked posts
+func classifyLinks(db *database.DB) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	candidates, err := link.NewRepository(db).Candidates(linkMinStrength, linkReclassify, linkLimit)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		fmt.Println("No unclassified pairs to classify.")
+		return nil
+	}
+
+	postRepo := post.NewRepository(db)
+	var pairs []linkPair
+	for _, l := range candidates {
+		a, errA := postRepo.Get(l.PostIDA)
+		b, errB := postRepo.Get(l.PostIDB)
+		if errA != nil || errB != nil {
+			continue
+		}
+		if publishedBefore(b, a) {
+			a, b = b, a
+		}
+		pairs = append(pairs, linkPair{older: a, newer: b, strength: l.Strength})
+	}
+
+	llmClient, err := newLLMClient(cfg, db)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("\nClassifying %d pairs\n\n", len(pairs))
+
+	linkRepo := link.NewRepository(db)
+	insightRepo := insight.NewRepository(db)
+	progress := worker.NewProgress(os.Stdout, len(pairs), isatty.IsTerminal(os.Stdout.Fd()))
+
+	worker.Run(ctx, cfg.APIs.LLMConcurrency, pairs, func(ctx context.Context, pair linkPair) {
+		name := fmt.Sprintf("'%s' → '%s'", truncateLinkTitle(pair.newer.Title, 30), truncateLinkTitle(pair.older.Title, 30))
+
+		ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		defer cancel()
+
+		rel, err := llmClient.Relate(ctx, relationSource(insightRepo, pair.older), relationSource(insightRepo, pair.newer))
+		if err != nil {
+			if ctx.Err() == nil {
+				progress.Failed(fmt.Sprintf("✗ %s: %v", name, err))
+			}
+			return
+		}
+
+		label := link.NormalizeRelationship(rel.Relationship)
+		if label == "" {
+			progress.Failed(fmt.Sprintf("✗ %s: unknown relationship %q", name, rel.Relationship))
+			return
+		}
+
+		rationale := strings.Join(strings.Fields(rel.Rationale), " ")
+		if err := linkRepo.Classify(pair.older.ID, pair.newer.ID, label, rationale, pair.strength); err != nil {
+			progress.Failed(fmt.Sprintf("✗ %s: %v", name, err))
+			return
+		}
+		progress.Done(fmt.Sprintf("✓ %s: %s", name, label))
+	})
+	progress.Finish()
+
+	fmt.Printf("\nClassified %d pairs\n", progress.Succeeded())
+	return nil
+}
+
+// relationSource describes a post to the relate prompt by its summary and
+// takeaways, or the start of its content when it has neither
+func relationSource(insightRepo *insight.Repository, p *post.Post) prompt.Source {
+	var b strings.Builder
+	if p.Summary != "" {
+		b.WriteString(p.Summary + "\n")
+	}
+	if insights, err := insightRepo.ListForPost(p.ID); err == nil {
+		for _, ins := range insights {
+			if ins.Type == insight.TypeTakeaway {
+				b.WriteString("- " + ins.Content + "\n")
+			}
+		}
+	}
+
+	text := b.String()
+	if text == "" {
+		text = p.ContentClean
+		if text == "" {
+			text = markdown.FromHTML(p.ContentRaw)
+		}
+		text = markdown.TruncateBytes(text, 2000)
+	}
+	return prompt.Source{Title: p.Title, URL: p.URL, Text: text}
+}
+
+// publishedBefore orders posts by publication date, falling back to when
+// they were fetched
+func publishedBefore(a, b *post.Post) bool {
+	ta, tb := a.FetchedAt, b.FetchedAt
+	if a.PublishedAt != nil {
+		ta = *a.PublishedAt
+	}
+	if b.PublishedAt != nil {
+		tb = *b.PublishedAt
+	}
+	return ta.Before(tb)
+}
+
 func truncateLinkTitle(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
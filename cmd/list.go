@@ -3,6 +3,7 @@ package cmd
 
 import (
 	"fmt"
+	"math"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -20,16 +21,20 @@ var listCmd = &cobra.Command{
 }
 
 var (
-	listTop    int
-	listSince  string
-	listSortBy string
+	listTop       int
+	listSince     string
+	listSortBy    string
+	listSummaries bool
+	listMaxMin    float64
 )
 
 func init() {
 	rootCmd.AddCommand(listCmd)
 	listCmd.Flags().IntVarP(&listTop, "top", "n", 20, "Number of posts to show")
 	listCmd.Flags().StringVar(&listSince, "since", "", "Show posts since date (YYYY-MM-DD)")
-	listCmd.Flags().StringVar(&listSortBy, "sort", "date", "Sort by: date, score, source")
+	listCmd.Flags().StringVar(&listSortBy, "sort", "date", "Sort by: date, score, source, reading_time")
+	listCmd.Flags().BoolVar(&listSummaries, "summaries", false, "Show the TL;DR summary under each title")
+	listCmd.Flags().Float64Var(&listMaxMin, "max-minutes", 0, "Only posts that take at most this many minutes to read")
 }
 
 func runList(cmd *cobra.Command, args []string) error {
@@ -40,18 +45,22 @@ func runList(cmd *cobra.Command, args []string) error {
 	defer db.Close()
 
 	// Validate sort option
-	validSorts := map[string]bool{"date": true, "score": true, "source": true}
+	validSorts := map[string]bool{"date": true, "score": true, "source": true, "reading_time": true}
 	if !validSorts[listSortBy] {
-		return fmt.Errorf("invalid sort option: %s (valid options: date, score, source)", listSortBy)
+		return fmt.Errorf("invalid sort option: %s (valid options: date, score, source, reading_time)", listSortBy)
 	}
 
 	repo := post.NewRepository(db)
-	posts, err := repo.ListSorted(listTop, 0, listSortBy)
+	posts, err := repo.ListSorted(listTop, 0, listSortBy, listMaxMin)
 	if err != nil {
 		return err
 	}
 
 	if len(posts) == 0 {
+		if listMaxMin > 0 {
+			fmt.Printf("No posts readable in %g minutes. Posts are measured when extracted.\n", listMaxMin)
+			return nil
+		}
 		fmt.Println("No posts found. Run 'blogmon fetch' to download posts.")
 		return nil
 	}
@@ -61,10 +70,12 @@ func runList(cmd *cobra.Command, args []string) error {
 	idStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
 	scoreStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
 	dateStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	readStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("13"))
 	sourceStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
+	summaryStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("7")).Width(100).PaddingLeft(7)
 
 	// Header
-	fmt.Println(headerStyle.Render(fmt.Sprintf(" %-4s  %-5s  %-10s  %-20s  %s", "#", "SCORE", "DATE", "SOURCE", "TITLE")))
+	fmt.Println(headerStyle.Render(fmt.Sprintf(" %-4s  %-5s  %-10s  %-4s  %-20s  %s", "#", "SCORE", "DATE", "READ", "SOURCE", "TITLE")))
 	fmt.Println(strings.Repeat("─", 100))
 
 	for i, p := range posts {
@@ -78,6 +89,11 @@ func runList(cmd *cobra.Command, args []string) error {
 			date = p.PublishedAt.Format("2006-01-02")
 		}
 
+		read := "-"
+		if p.ReadingMinutes > 0 {
+			read = formatReadingTime(p.ReadingMinutes)
+		}
+
 		sourceName := p.SourceName
 		if len(sourceName) > 20 {
 			sourceName = sourceName[:17] + "..."
@@ -88,14 +104,20 @@ func runList(cmd *cobra.Command, args []string) error {
 			title = title[:47] + "..."
 		}
 
-		fmt.Printf(" %s  %s  %s  %s  %s\n",
+		fmt.Printf(" %s  %s  %s  %s  %s  %s\n",
 			idStyle.Render(fmt.Sprintf("%-4d", p.ID)),
 			scoreStyle.Render(fmt.Sprintf("%-5s", score)),
 			dateStyle.Render(fmt.Sprintf("%-10s", date)),
+			readStyle.Render(fmt.Sprintf("%-4s", read)),
 			sourceStyle.Render(fmt.Sprintf("%-20s", sourceName)),
 			title,
 		)
 
+		if listSummaries && p.Summary != "" {
+			fmt.Println(summaryStyle.Render(p.Summary))
+			fmt.Println()
+		}
+
 		if i >= listTop-1 {
 			break
 		}
@@ -103,3 +125,8 @@ func runList(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// formatReadingTime rounds a reading time up to whole minutes, e.g. "4m"
+func formatReadingTime(minutes float64) string {
+	return fmt.Sprintf("%dm", max(1, int(math.Ceil(minutes))))
+}
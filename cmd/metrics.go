@@ -0,0 +1,65 @@
+// cmd/metrics.go
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/julienpequegnot/blogmon/internal/config"
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/spf13/cobra"
+)
+
+var metricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "Compute reading time, code ratio and readability of posts",
+	Long: `Measures the cleaned content of extracted posts: word count, estimated
+reading time, share of code, heading count and Flesch readability. Extraction
+measures posts as it cleans them; this pass fills in posts extracted before
+metrics existed, or recomputes every post with --all.`,
+	RunE: runMetrics,
+}
+
+var metricsAll bool
+
+func init() {
+	rootCmd.AddCommand(metricsCmd)
+	metricsCmd.Flags().BoolVar(&metricsAll, "all", false, "Recompute metrics of every extracted post")
+}
+
+func runMetrics(cmd *cobra.Command, args []string) error {
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	postRepo := post.NewRepository(db)
+	posts, err := postRepo.GetUnmeasured(metricsAll)
+	if err != nil {
+		return err
+	}
+	if len(posts) == 0 {
+		fmt.Println("All extracted posts are measured.")
+		return nil
+	}
+
+	var minutes float64
+	err = db.Tx(func(tx *database.DB) error {
+		txRepo := post.NewRepository(tx)
+		for _, p := range posts {
+			m := post.Measure(p.ContentClean)
+			if err := txRepo.UpdateMetrics(p.ID, m); err != nil {
+				return fmt.Errorf("failed to store metrics of post %d: %w", p.ID, err)
+			}
+			minutes += m.ReadingMinutes
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Measured %d posts, %s of reading on average\n", len(posts), formatReadingTime(minutes/float64(len(posts))))
+	return nil
+}
@@ -0,0 +1,131 @@
+// cmd/model.go
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/blogmon/internal/config"
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/feedback"
+	"github.com/spf13/cobra"
+)
+
+var modelCmd = &cobra.Command{
+	Use:   "model",
+	Short: "Inspect the personal relevance model learned from ratings",
+}
+
+var modelStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the ratings, accuracy and strongest features of the model",
+	Long: `Shows how many posts were rated and how often the model predicts a rating
+right, estimated with leave-one-out cross-validation: each rated post is
+predicted by a model trained on all the other ratings. Compare it with the
+baseline of always predicting the most common rating.`,
+	RunE: runModelStats,
+}
+
+var modelTrainCmd = &cobra.Command{
+	Use:   "train",
+	Short: "Retrain the model from the ratings and rescore relevance",
+	Long: `Retrains the model, which 'blogmon rate' already does after every rating.
+Run it after extraction has assigned topics to rated posts, or after
+changing scoring.personal.`,
+	RunE: runModelTrain,
+}
+
+var modelFeatures int
+
+func init() {
+	rootCmd.AddCommand(modelCmd)
+	modelCmd.AddCommand(modelStatsCmd)
+	modelCmd.AddCommand(modelTrainCmd)
+	modelStatsCmd.Flags().IntVarP(&modelFeatures, "features", "f", 10, "Strongest features to show for likes and dislikes")
+}
+
+func runModelStats(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	model, stats, err := feedback.NewRepository(db).Model()
+	if err != nil {
+		return err
+	}
+	if model == nil || stats.Ratings == 0 {
+		fmt.Println("No ratings yet. Rate posts with: blogmon rate <post-id> up|down")
+		return nil
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	likeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	dislikeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+
+	fmt.Printf("\n%s\n\n", titleStyle.Render("PERSONAL RELEVANCE MODEL"))
+	fmt.Printf("%s %d (%d up, %d down)\n", labelStyle.Render("Ratings:"), stats.Ratings, stats.Likes, stats.Dislikes)
+	fmt.Printf("%s %s\n", labelStyle.Render("Trained:"), stats.TrainedAt.Local().Format("2006-01-02 15:04"))
+
+	if !model.Ready() {
+		fmt.Println("\nThe model needs both likes and dislikes before it affects relevance.")
+		return nil
+	}
+
+	baseline := float64(max(stats.Likes, stats.Dislikes)) / float64(stats.Ratings)
+	if stats.Accuracy > 0 {
+		fmt.Printf("%s %.0f%% (baseline %.0f%%)\n", labelStyle.Render("Accuracy:"), stats.Accuracy*100, baseline*100)
+	} else {
+		fmt.Printf("%s not enough ratings to cross-validate\n", labelStyle.Render("Accuracy:"))
+	}
+
+	share := min(cfg.Scoring.Personal, 1) * min(float64(stats.Ratings)/personalFullWeightRatings, 1)
+	fmt.Printf("%s %.0f%% of relevance", labelStyle.Render("Weight:"), share*100)
+	if share < cfg.Scoring.Personal {
+		fmt.Printf(" (%.0f%% from %d ratings)", min(cfg.Scoring.Personal, 1)*100, personalFullWeightRatings)
+	}
+	fmt.Println()
+
+	likes, dislikes := model.TopFeatures(modelFeatures, 2)
+	printFeatures := func(title string, style lipgloss.Style, features []feedback.FeatureWeight) {
+		if len(features) == 0 {
+			return
+		}
+		fmt.Printf("\n%s\n", labelStyle.Render(title))
+		names := make([]string, len(features))
+		for i, f := range features {
+			names[i] = style.Render(f.Feature)
+		}
+		fmt.Printf("  %s\n", strings.Join(names, ", "))
+	}
+	printFeatures("LIKED", likeStyle, likes)
+	printFeatures("DISLIKED", dislikeStyle, dislikes)
+	return nil
+}
+
+func runModelTrain(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := retrainPersonal(db, cfg); err != nil {
+		return err
+	}
+	fmt.Println("Personal model trained. See: blogmon model stats")
+	return nil
+}
@@ -0,0 +1,202 @@
+// cmd/prompts.go
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/blogmon/internal/config"
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/insight"
+	"github.com/julienpequegnot/blogmon/internal/llm"
+	"github.com/julienpequegnot/blogmon/internal/markdown"
+	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/julienpequegnot/blogmon/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+var promptsCmd = &cobra.Command{
+	Use:   "prompts",
+	Short: "Manage LLM prompt templates",
+	Long: `Prompts are Go text/template files read from $BLOGMON_HOME/prompts/<name>.tmpl.
+Post templates receive {{.Title}} and {{.Content}}; the ask template receives
+{{.Question}} and {{.Sources}}, each with .Number, .Title, .URL and .Text; the
+relate template receives the two posts to compare as {{.Sources}}, oldest first;
+the card template receives a takeaway as {{.Content}}.
+Missing files fall back to the built-in prompts.`,
+}
+
+var promptsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List prompt templates and where they are loaded from",
+	RunE:  runPromptsList,
+}
+
+var promptsInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write the built-in templates to the prompts directory for editing",
+	RunE:  runPromptsInit,
+}
+
+var promptsTestCmd = &cobra.Command{
+	Use:   "test <post-id>",
+	Short: "Render a template for a post and show the raw LLM response",
+	Long:  `Renders a prompt template against a post and prints the raw LLM response. Nothing is saved.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPromptsTest,
+}
+
+var (
+	promptsInitForce    bool
+	promptsTestTemplate string
+	promptsTestQuestion string
+	promptsTestWith     int64
+)
+
+func init() {
+	rootCmd.AddCommand(promptsCmd)
+	promptsCmd.AddCommand(promptsListCmd, promptsInitCmd, promptsTestCmd)
+	promptsInitCmd.Flags().BoolVar(&promptsInitForce, "force", false, "Overwrite existing template files")
+	promptsTestCmd.Flags().StringVarP(&promptsTestTemplate, "template", "t", prompt.Extraction, "Template to test")
+	promptsTestCmd.Flags().StringVarP(&promptsTestQuestion, "question", "q", "What is this post about?", "Question for the ask template, answered from the post")
+	promptsTestCmd.Flags().Int64Var(&promptsTestWith, "with", 0, "Other post for the relate template")
+}
+
+func runPromptsList(cmd *cobra.Command, args []string) error {
+	prompts, err := prompt.Load(prompt.Dir(config.Dir()))
+	if err != nil {
+		return err
+	}
+
+	nameStyle := lipgloss.NewStyle().Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+
+	for _, name := range prompt.Names() {
+		origin := "built-in"
+		if path := prompts.Path(name); path != "" {
+			origin = path
+		}
+		fmt.Printf("%s  %s\n", nameStyle.Render(fmt.Sprintf("%-8s", name)),
+			labelStyle.Render(fmt.Sprintf("version %s, %s", prompts.Version(name), origin)))
+	}
+	return nil
+}
+
+func runPromptsInit(cmd *cobra.Command, args []string) error {
+	dir := prompt.Dir(config.Dir())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	for _, name := range prompt.Names() {
+		path := filepath.Join(dir, name+".tmpl")
+		if _, err := os.Stat(path); err == nil && !promptsInitForce {
+			fmt.Printf("Skipping %s (already exists)\n", path)
+			continue
+		}
+
+		if err := os.WriteFile(path, []byte(prompt.Builtin(name)), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s\n", path)
+	}
+	return nil
+}
+
+func runPromptsTest(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid post ID: %s", args[0])
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	p, err := post.NewRepository(db).Get(id)
+	if err != nil {
+		return fmt.Errorf("post not found: %d", id)
+	}
+
+	prompts, err := prompt.Load(prompt.Dir(config.Dir()))
+	if err != nil {
+		return err
+	}
+
+	content := truncateForLLM(markdown.FromHTML(p.ContentRaw))
+	data := prompt.Data{Title: p.Title, Content: content}
+	switch promptsTestTemplate {
+	case prompt.Answer:
+		data.Question = promptsTestQuestion
+		data.Sources = []prompt.Source{{Number: 1, Title: p.Title, URL: p.URL, Text: content}}
+	case prompt.Relation:
+		other, err := post.NewRepository(db).Get(promptsTestWith)
+		if err != nil {
+			return fmt.Errorf("--with must be the ID of the post to relate to, got %d", promptsTestWith)
+		}
+		older, newer := other, p
+		if publishedBefore(p, other) {
+			older, newer = p, other
+		}
+		insightRepo := insight.NewRepository(db)
+		data.Sources = []prompt.Source{relationSource(insightRepo, older), relationSource(insightRepo, newer)}
+		data.Sources[0].Number, data.Sources[1].Number = 1, 2
+	case prompt.Flashcard:
+		// The card prompt is tried on the post's most important takeaway
+		insights, _ := insight.NewRepository(db).ListForPost(p.ID)
+		for _, ins := range insights {
+			if ins.Type == insight.TypeTakeaway {
+				data.Content = ins.Content
+				break
+			}
+		}
+	}
+
+	text, err := prompts.Render(promptsTestTemplate, data)
+	if err != nil {
+		return err
+	}
+
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+
+	fmt.Println(labelStyle.Render(fmt.Sprintf("PROMPT (%s, version %s):", promptsTestTemplate, prompts.Version(promptsTestTemplate))))
+	fmt.Println(text)
+
+	llmClient := llm.NewClient(cfg.APIs.LLMURL, cfg.APIs.LLMModel, 2*time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	response, err := llmClient.Generate(ctx, text)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%s\n", labelStyle.Render(fmt.Sprintf("RESPONSE (%s):", llmClient.Model())))
+	fmt.Println(response)
+
+	if promptsTestTemplate == prompt.Extraction {
+		result, err := llm.ParseExtraction(response)
+		if err != nil {
+			fmt.Printf("\n%s %v\n", labelStyle.Render("PARSE ERROR:"), err)
+			return nil
+		}
+		fmt.Printf("\n%s %d takeaways, %d quotes, %d definitions, %d code examples, %d references, %d topics\n",
+			labelStyle.Render("PARSED:"), len(result.Takeaways), len(result.Quotes), len(result.Definitions),
+			len(result.CodeExamples), len(result.References), len(result.Topics))
+	}
+
+	return nil
+}
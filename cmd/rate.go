@@ -0,0 +1,107 @@
+// cmd/rate.go
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/julienpequegnot/blogmon/internal/config"
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/feedback"
+	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/spf13/cobra"
+)
+
+var rateCmd = &cobra.Command{
+	Use:   "rate <post-id> up|down|clear",
+	Short: "Tell blogmon whether you liked a post",
+	Long: `Records whether you liked a post. Ratings train a personal model over the
+words, topics and source of posts, which is blended into relevance scores
+(scoring.personal sets its share). Each rating retrains the model and
+rescores the relevance of every scored post, without network calls.
+
+See how well the model predicts your ratings with 'blogmon model stats'.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runRate,
+}
+
+func init() {
+	rootCmd.AddCommand(rateCmd)
+}
+
+func runRate(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid post ID: %s", args[0])
+	}
+	if args[1] != "up" && args[1] != "down" && args[1] != "clear" {
+		return fmt.Errorf("rating must be up, down or clear, got %q", args[1])
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	p, err := post.NewRepository(db).Get(id)
+	if err != nil {
+		return fmt.Errorf("post not found: %d", id)
+	}
+
+	repo := feedback.NewRepository(db)
+	if args[1] == "clear" {
+		cleared, err := repo.Clear(id)
+		if err != nil {
+			return err
+		}
+		if !cleared {
+			fmt.Printf("%s was not rated\n", truncateLinkTitle(p.Title, 60))
+			return nil
+		}
+		fmt.Printf("Cleared rating of %s\n", truncateLinkTitle(p.Title, 60))
+	} else {
+		if err := repo.Rate(id, args[1] == "up"); err != nil {
+			return err
+		}
+		fmt.Printf("Rated %s %s\n", truncateLinkTitle(p.Title, 60), formatRating(args[1] == "up"))
+	}
+
+	return retrainPersonal(db, cfg)
+}
+
+// retrainPersonal fits the personal model to the current ratings and
+// rescores relevance with it
+func retrainPersonal(db *database.DB, cfg *config.Config) error {
+	model, stats, err := feedback.NewRepository(db).Retrain()
+	if err != nil {
+		return fmt.Errorf("failed to train personal model: %w", err)
+	}
+	if !model.Ready() {
+		fmt.Printf("Personal model needs likes and dislikes to learn from (%d up, %d down)\n", stats.Likes, stats.Dislikes)
+	}
+
+	if err := syncInterests(db, cfg); err != nil {
+		return err
+	}
+	n, err := rescorePosts(db, cfg, rescoreOptions{relevance: true})
+	if err != nil {
+		return fmt.Errorf("failed to rescore relevance: %w", err)
+	}
+	if n > 0 {
+		fmt.Printf("Rescored relevance of %d posts\n", n)
+	}
+	return nil
+}
+
+func formatRating(liked bool) string {
+	if liked {
+		return "up"
+	}
+	return "down"
+}
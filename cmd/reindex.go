@@ -6,6 +6,9 @@ import (
 
 	"github.com/julienpequegnot/blogmon/internal/config"
 	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/markdown"
+	"github.com/julienpequegnot/blogmon/internal/novelty"
+	"github.com/julienpequegnot/blogmon/internal/post"
 	"github.com/julienpequegnot/blogmon/internal/search"
 	"github.com/spf13/cobra"
 )
@@ -13,12 +16,18 @@ import (
 var reindexCmd = &cobra.Command{
 	Use:   "reindex",
 	Short: "Rebuild search index",
-	Long:  `Rebuilds the full-text search index from all posts.`,
-	RunE:  runReindex,
+	Long: `Rebuilds the full-text search index and the novelty index from all posts.
+
+With --reclean, the cleaned Markdown content of extracted posts is first
+regenerated from their raw HTML, without calling the LLM again.`,
+	RunE: runReindex,
 }
 
+var reindexReclean bool
+
 func init() {
 	rootCmd.AddCommand(reindexCmd)
+	reindexCmd.Flags().BoolVar(&reindexReclean, "reclean", false, "Regenerate cleaned content from raw HTML before indexing")
 }
 
 func runReindex(cmd *cobra.Command, args []string) error {
@@ -28,6 +37,14 @@ func runReindex(cmd *cobra.Command, args []string) error {
 	}
 	defer db.Close()
 
+	if reindexReclean {
+		n, err := recleanPosts(post.NewRepository(db))
+		if err != nil {
+			return fmt.Errorf("failed to reclean content: %w", err)
+		}
+		fmt.Printf("Recleaned %d posts\n", n)
+	}
+
 	fmt.Println("Rebuilding search index...")
 
 	searchRepo := search.NewRepository(db)
@@ -36,5 +53,33 @@ func runReindex(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Println("Search index rebuilt successfully.")
+
+	n, err := novelty.NewRepository(db).Rebuild()
+	if err != nil {
+		return fmt.Errorf("failed to rebuild novelty index: %w", err)
+	}
+	fmt.Printf("Novelty index rebuilt from %d posts.\n", n)
 	return nil
 }
+
+// recleanPosts converts the raw HTML of every extracted post to Markdown again
+func recleanPosts(postRepo *post.Repository) (int, error) {
+	// A negative limit lifts SQLite's LIMIT
+	posts, err := postRepo.GetForReextract(nil, nil, -1, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, p := range posts {
+		content := markdown.FromHTML(p.ContentRaw)
+		if content == "" || content == p.ContentClean {
+			continue
+		}
+		if err := postRepo.UpdateContentClean(p.ID, content); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
@@ -0,0 +1,333 @@
+// cmd/review.go
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/blogmon/internal/card"
+	"github.com/julienpequegnot/blogmon/internal/config"
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/insight"
+	"github.com/julienpequegnot/blogmon/internal/llm"
+	"github.com/julienpequegnot/blogmon/internal/topic"
+	"github.com/julienpequegnot/blogmon/internal/worker"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+var reviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Review due flashcards of your insights",
+	Long: `Shows the flashcards due today one at a time. Press Enter to reveal the
+answer, then grade how well you remembered it: 1 again, 2 hard, 3 good, 4 easy.
+Cards are rescheduled with SM-2, so well-known cards come back less and less
+often. Create cards from your insights with 'blogmon review generate'.`,
+	RunE: runReview,
+}
+
+var reviewGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Turn important takeaways and definitions into flashcards",
+	Long: `Takeaways become question/answer cards written by the LLM, or cloze cards
+hiding their most specific word with --cloze or when the LLM fails. Definitions
+become "What is <term>?" cards. Insights that already have a card are skipped,
+even after re-extraction.`,
+	RunE: runReviewGenerate,
+}
+
+var reviewExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export flashcards as CSV for Anki",
+	RunE:  runReviewExport,
+}
+
+var (
+	reviewLimit         int
+	reviewMinImportance int
+	reviewCloze         bool
+	reviewOutput        string
+)
+
+// reviewGrades maps the keys of a review session to SM-2 grades
+var reviewGrades = map[string]int{
+	"1": card.GradeAgain,
+	"2": card.GradeHard,
+	"3": card.GradeGood,
+	"4": card.GradeEasy,
+}
+
+func init() {
+	rootCmd.AddCommand(reviewCmd)
+	reviewCmd.AddCommand(reviewGenerateCmd)
+	reviewCmd.AddCommand(reviewExportCmd)
+	reviewCmd.Flags().IntVarP(&reviewLimit, "limit", "l", 20, "Maximum cards to review")
+	reviewGenerateCmd.Flags().IntVarP(&reviewLimit, "limit", "l", 20, "Maximum cards to create")
+	reviewGenerateCmd.Flags().IntVar(&reviewMinImportance, "min-importance", 3, "Minimum insight importance (1-5)")
+	reviewGenerateCmd.Flags().BoolVar(&reviewCloze, "cloze", false, "Make cloze cards without the LLM")
+	reviewExportCmd.Flags().StringVarP(&reviewOutput, "output", "o", "", "Write to this file instead of stdout")
+}
+
+func runReview(cmd *cobra.Command, args []string) error {
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	cardRepo := card.NewRepository(db)
+	now := time.Now()
+
+	cards, err := cardRepo.Due(now, reviewLimit)
+	if err != nil {
+		return err
+	}
+	if len(cards) == 0 {
+		counts, err := cardRepo.Count(now)
+		if err != nil {
+			return err
+		}
+		if counts.Total == 0 {
+			fmt.Println("No flashcards yet. Run 'blogmon review generate' first.")
+		} else {
+			fmt.Printf("No cards due. %d cards in the deck.\n", counts.Total)
+		}
+		return nil
+	}
+
+	questionStyle := lipgloss.NewStyle().Bold(true)
+	answerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+
+	in := bufio.NewReader(cmd.InOrStdin())
+	reviewed := 0
+
+session:
+	for i, c := range cards {
+		fmt.Printf("\n%s %s\n", dimStyle.Render(fmt.Sprintf("[%d/%d]", i+1, len(cards))), questionStyle.Render(c.Question))
+		fmt.Println(dimStyle.Render("    " + truncateLinkTitle(c.PostTitle, 70)))
+
+		key, err := readKey(in, "Enter to show the answer, s to skip, q to quit: ")
+		if err != nil || key == "q" {
+			break
+		}
+		if key == "s" {
+			continue
+		}
+
+		fmt.Printf("\n  %s\n\n", answerStyle.Render(c.Answer))
+
+		for {
+			key, err := readKey(in, "Grade: [1] again  [2] hard  [3] good  [4] easy  (q to quit): ")
+			if err != nil || key == "q" {
+				break session
+			}
+			grade, ok := reviewGrades[key]
+			if !ok {
+				continue
+			}
+
+			state, err := cardRepo.Review(c, grade, time.Now())
+			if err != nil {
+				return err
+			}
+			reviewed++
+			fmt.Println(dimStyle.Render(fmt.Sprintf("  Next review in %s", formatInterval(state.IntervalDays))))
+			break
+		}
+	}
+
+	counts, err := cardRepo.Count(time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nReviewed %d cards, %d still due\n", reviewed, counts.Due)
+	return nil
+}
+
+// readKey prints a question and reads the trimmed, lowercased reply
+func readKey(in *bufio.Reader, question string) (string, error) {
+	fmt.Print(question)
+	line, err := in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		fmt.Println()
+		return "", err
+	}
+	return strings.ToLower(strings.TrimSpace(line)), nil
+}
+
+func formatInterval(days int) string {
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+func runReviewGenerate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	cardRepo := card.NewRepository(db)
+	candidates, err := cardRepo.Candidates(reviewMinImportance, reviewLimit)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		fmt.Println("No insights without a card. Lower --min-importance or run 'blogmon extract'.")
+		return nil
+	}
+
+	llmClient, err := newLLMClient(cfg, db)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("\nCreating cards from %d insights\n\n", len(candidates))
+
+	topicRepo := topic.NewRepository(db)
+	progress := worker.NewProgress(os.Stdout, len(candidates), isatty.IsTerminal(os.Stdout.Fd()))
+
+	worker.Run(ctx, cfg.APIs.LLMConcurrency, candidates, func(ctx context.Context, c card.Candidate) {
+		name := truncateLinkTitle(c.Content, 60)
+
+		kind, question, answer, err := makeCard(ctx, llmClient, topicRepo, c)
+		if err != nil {
+			if ctx.Err() == nil {
+				progress.Skipped(fmt.Sprintf("- %s: %v", name, err))
+			}
+			return
+		}
+
+		added, err := cardRepo.Add(c.Insight, kind, question, answer, time.Now())
+		if err != nil {
+			progress.Failed(fmt.Sprintf("✗ %s: %v", name, err))
+			return
+		}
+		if !added {
+			progress.Skipped(fmt.Sprintf("- %s: already has a card", name))
+			return
+		}
+		progress.Done(fmt.Sprintf("✓ %s (%s)", name, kind))
+	})
+	progress.Finish()
+
+	fmt.Printf("\nCreated %d cards. Start reviewing with: blogmon review\n", progress.Succeeded())
+	return nil
+}
+
+// makeCard writes the question and answer for an insight. Takeaways fall back
+// to a cloze over the post's topics when the LLM fails.
+func makeCard(ctx context.Context, llmClient *llm.Client, topicRepo *topic.Repository, c card.Candidate) (string, string, string, error) {
+	if c.Type == insight.TypeDefinition {
+		return card.KindQA, fmt.Sprintf("What is %s?", c.Detail), c.Content, nil
+	}
+
+	var llmErr error
+	if !reviewCloze {
+		callCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		defer cancel()
+
+		fc, err := llmClient.Flashcard(callCtx, c.PostTitle, c.Content)
+		if err == nil {
+			return card.KindQA, fc.Question, fc.Answer, nil
+		}
+		if ctx.Err() != nil {
+			return "", "", "", err
+		}
+		llmErr = err
+	}
+
+	topics, _ := topicRepo.ListForPost(c.PostID)
+	question, answer, ok := card.Cloze(c.Content, topics)
+	if !ok {
+		if llmErr != nil {
+			return "", "", "", llmErr
+		}
+		return "", "", "", fmt.Errorf("no word to hide")
+	}
+	return card.KindCloze, question, answer, nil
+}
+
+func runReviewExport(cmd *cobra.Command, args []string) error {
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	cards, err := card.NewRepository(db).All()
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if reviewOutput != "" {
+		f, err := os.Create(reviewOutput)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	topicRepo := topic.NewRepository(db)
+	if err := writeAnkiCSV(out, cards, topicRepo); err != nil {
+		return err
+	}
+
+	if reviewOutput != "" {
+		fmt.Printf("Exported %d cards to %s\n", len(cards), reviewOutput)
+	}
+	return nil
+}
+
+// writeAnkiCSV writes cards as front, back and tags columns, with the header
+// lines Anki reads to import them without configuration
+func writeAnkiCSV(out io.Writer, cards []card.Card, topicRepo *topic.Repository) error {
+	fmt.Fprintln(out, "#separator:comma")
+	fmt.Fprintln(out, "#html:false")
+	fmt.Fprintln(out, "#tags column:3")
+
+	w := csv.NewWriter(out)
+	for _, c := range cards {
+		back := c.Answer
+		if c.PostTitle != "" {
+			back += "\n\n— " + c.PostTitle
+			if c.PostURL != "" {
+				back += " (" + c.PostURL + ")"
+			}
+		}
+
+		tags := []string{"blogmon", "blogmon::" + c.Kind}
+		topics, _ := topicRepo.ListForPost(c.PostID)
+		for _, t := range topics {
+			tags = append(tags, strings.ReplaceAll(t, " ", "_"))
+		}
+
+		if err := w.Write([]string{c.Question, back, strings.Join(tags, " ")}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
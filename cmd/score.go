@@ -2,31 +2,68 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/julienpequegnot/blogmon/internal/community"
 	"github.com/julienpequegnot/blogmon/internal/config"
 	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/feedback"
+	"github.com/julienpequegnot/blogmon/internal/novelty"
 	"github.com/julienpequegnot/blogmon/internal/post"
 	"github.com/julienpequegnot/blogmon/internal/score"
 	"github.com/julienpequegnot/blogmon/internal/scorer"
+	"github.com/julienpequegnot/blogmon/internal/topic"
 	"github.com/spf13/cobra"
 )
 
 var scoreCmd = &cobra.Command{
 	Use:   "score",
 	Short: "Calculate scores for posts",
-	Long:  `Calculates community, relevance, and novelty scores for unscored posts.`,
-	RunE:  runScore,
+	Long: `Calculates community, relevance, and novelty scores for unscored posts.
+
+Novelty measures how different a post is from the posts published before
+it, through a TF-IDF index kept in the database and updated as posts are
+fetched, extracted and scored. --rescore-novelty recomputes it for every
+scored post, e.g. for posts scored before novelty was defined this way.
+
+Community signals (HN, Reddit and Lobsters points and comments) are recorded
+with every check. Posts up to community.refresh_days old are checked again,
+at intervals of a quarter of their age between an hour and a week, and their
+community and final scores are updated.
+
+With scoring.half_life_days set, final scores halve every half-life of post
+age. --rescore recomputes final scores from the stored component scores, to
+apply new weights or let decay catch up; --all also recomputes relevance from
+the current interests, community from the recorded signals and novelty.
+Neither calls any API.
+
+Relevance blends keyword matches against the interests with a personal model
+learned from 'blogmon rate' feedback; scoring.personal sets the model's share.`,
+	RunE: runScore,
 }
 
 var (
-	scoreLimit  int
-	scoreSkipHN bool
+	scoreLimit          int
+	scoreSkipHN         bool
+	scoreSkipReddit     bool
+	scoreSkipLobsters   bool
+	scoreNoRefresh      bool
+	scoreRescore        bool
+	scoreRescoreNovelty bool
+	scoreAll            bool
 )
 
 func init() {
 	rootCmd.AddCommand(scoreCmd)
 	scoreCmd.Flags().IntVarP(&scoreLimit, "limit", "l", 50, "Maximum posts to score")
 	scoreCmd.Flags().BoolVar(&scoreSkipHN, "skip-hn", false, "Skip HN API calls (for testing)")
+	scoreCmd.Flags().BoolVar(&scoreSkipReddit, "skip-reddit", false, "Skip Reddit API calls (for testing)")
+	scoreCmd.Flags().BoolVar(&scoreSkipLobsters, "skip-lobsters", false, "Skip Lobsters API calls (for testing)")
+	scoreCmd.Flags().BoolVar(&scoreNoRefresh, "no-refresh", false, "Don't re-check community signals of scored posts")
+	scoreCmd.Flags().BoolVar(&scoreRescore, "rescore", false, "Recompute final scores from stored component scores, without network calls")
+	scoreCmd.Flags().BoolVar(&scoreRescoreNovelty, "rescore-novelty", false, "Recompute novelty scores against earlier posts (implies --rescore)")
+	scoreCmd.Flags().BoolVar(&scoreAll, "all", false, "Also recompute relevance, community and novelty scores (implies --rescore)")
 }
 
 func runScore(cmd *cobra.Command, args []string) error {
@@ -41,8 +78,31 @@ func runScore(cmd *cobra.Command, args []string) error {
 	}
 	defer db.Close()
 
+	if err := syncInterests(db, cfg); err != nil {
+		return err
+	}
+
+	if scoreRescore || scoreRescoreNovelty || scoreAll {
+		n, err := rescorePosts(db, cfg, rescoreOptions{
+			relevance: scoreAll,
+			community: scoreAll,
+			novelty:   scoreAll || scoreRescoreNovelty,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Rescored %d posts\n", n)
+		return nil
+	}
+
 	postRepo := post.NewRepository(db)
 	scoreRepo := score.NewRepository(db)
+	signalRepo := community.NewRepository(db)
+	providers := communityProviders(cfg, map[string]bool{
+		scorer.ProviderHN:       scoreSkipHN,
+		scorer.ProviderReddit:   scoreSkipReddit,
+		scorer.ProviderLobsters: scoreSkipLobsters,
+	})
 
 	// Get unscored post IDs
 	unscoredIDs, err := scoreRepo.GetUnscoredPostIDs(scoreLimit)
@@ -52,24 +112,18 @@ func runScore(cmd *cobra.Command, args []string) error {
 
 	if len(unscoredIDs) == 0 {
 		fmt.Println("No unscored posts found.")
-		return nil
+	} else {
+		fmt.Printf("Scoring %d posts\n\n", len(unscoredIDs))
 	}
 
-	fmt.Printf("Scoring %d posts\n\n", len(unscoredIDs))
-
 	// Initialize scorers
-	hnScorer := scorer.NewHNScorer()
-	relevanceScorer := scorer.NewRelevanceScorer(cfg.Interests)
-	noveltyScorer := scorer.NewNoveltyScorer()
-
-	// Build novelty corpus from existing posts
-	allPosts, _ := postRepo.List(1000, 0)
-	for _, p := range allPosts {
-		content := p.ContentClean
-		if content == "" {
-			content = p.Title
-		}
-		noveltyScorer.AddDocument(p.ID, content)
+	relevanceScorer, err := newRelevance(db, cfg)
+	if err != nil {
+		return err
+	}
+	noveltyScorer, err := noveltyIndex(db)
+	if err != nil {
+		return err
 	}
 
 	weights := cfg.Scoring
@@ -83,32 +137,34 @@ func runScore(cmd *cobra.Command, args []string) error {
 
 		fmt.Printf("Scoring: %s\n", p.Title)
 
-		// Community score (HN)
-		var communityScore float64
-		if !scoreSkipHN {
-			hit, err := hnScorer.SearchByURL(p.URL)
-			if err != nil {
-				fmt.Printf("  HN API error: %v\n", err)
-			} else if hit != nil {
-				communityScore = scorer.CalculateCommunityScore(hit.Points, hit.NumComments, 0)
-				fmt.Printf("  HN: %d points, %d comments\n", hit.Points, hit.NumComments)
+		// Community score
+		signals, errs := checkCommunity(signalRepo, providers, p)
+		for _, err := range errs {
+			fmt.Printf("  %v\n", err)
+		}
+		for _, s := range signals {
+			if s.Submissions > 0 {
+				fmt.Printf("  %s\n", formatSignal(s))
 			}
 		}
+		communityScore := scorer.CommunityScoreFromSignals(signals)
 
 		// Relevance score
+		relevanceScore := relevanceScorer.Score(p)
+
+		// Novelty score
 		content := p.ContentClean
 		if content == "" {
 			content = p.Title
 		}
-		relevanceScore := relevanceScorer.Score(p.Title, content)
-
-		// Novelty score
-		noveltyScore := noveltyScorer.Score(content)
+		noveltyScore, err := noveltyScorer.Score(p.ID, postedAt(p), content)
+		if err != nil {
+			fmt.Printf("  Error scoring novelty: %v\n", err)
+			continue
+		}
 
 		// Final score
-		finalScore := (communityScore * weights.Community) +
-			(relevanceScore * weights.Relevance) +
-			(noveltyScore * weights.Novelty)
+		finalScore := calculateFinalScore(weights, communityScore, relevanceScore, noveltyScore, postedAt(p))
 
 		// Save score
 		if err := scoreRepo.Upsert(postID, communityScore, relevanceScore, noveltyScore, finalScore); err != nil {
@@ -120,6 +176,294 @@ func runScore(cmd *cobra.Command, args []string) error {
 			communityScore, relevanceScore, noveltyScore, finalScore)
 	}
 
+	if !scoreNoRefresh && len(providers) > 0 {
+		fmt.Println("\nRefreshing community signals")
+		refreshed, err := refreshCommunity(db, cfg, providers, scoreLimit, func(p *post.Post, before, after float64) {
+			if after != before {
+				fmt.Printf("  %s: community %.1f -> %.1f\n", truncateLinkTitle(p.Title, 50), before, after)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  Re-checked %d posts\n", refreshed)
+	}
+
 	fmt.Println("\nScoring complete")
 	return nil
 }
+
+// calculateFinalScore weighs the component scores of a post and decays the
+// result with the post's age
+func calculateFinalScore(weights config.ScoringConfig, community, relevance, novelty float64, posted time.Time) float64 {
+	final := community*weights.Community + relevance*weights.Relevance + novelty*weights.Novelty
+	return final * scorer.RecencyFactor(time.Since(posted), weights.HalfLifeDays)
+}
+
+// postedAt is the publication time of a post, or its fetch time when the
+// feed had none
+func postedAt(p *post.Post) time.Time {
+	if p.PublishedAt != nil {
+		return *p.PublishedAt
+	}
+	return p.FetchedAt
+}
+
+// noveltyIndex brings the novelty index up to date with new and changed
+// posts and returns a scorer over it
+func noveltyIndex(db *database.DB) (*novelty.Scorer, error) {
+	repo := novelty.NewRepository(db)
+	if _, err := repo.Update(); err != nil {
+		return nil, err
+	}
+	return repo.Scorer()
+}
+
+// personalFullWeightRatings is the number of ratings at which the personal
+// model gets the full scoring.personal share of relevance; with fewer, its
+// share shrinks in proportion
+const personalFullWeightRatings = 20
+
+// relevance scores posts against the interests, blended with the personal
+// model learned from ratings once it can tell likes from dislikes
+type relevance struct {
+	keywords *scorer.RelevanceScorer
+	model    *feedback.Model
+	weight   float64
+	topics   map[int64][]string
+}
+
+func newRelevance(db *database.DB, cfg *config.Config) (*relevance, error) {
+	r := &relevance{keywords: scorer.NewRelevanceScorer(cfg.Interests), weight: cfg.Scoring.Personal}
+	if r.weight <= 0 {
+		return r, nil
+	}
+
+	model, _, err := feedback.NewRepository(db).Model()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load personal model: %w", err)
+	}
+	if !model.Ready() {
+		return r, nil
+	}
+	r.model = model
+	if r.topics, err = topic.NewRepository(db).ListAll(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Score is the relevance of a post, 0-100
+func (r *relevance) Score(p *post.Post) float64 {
+	content := p.ContentClean
+	if content == "" {
+		content = p.Title
+	}
+	keywords := r.keywords.Score(p.Title, content)
+	if r.model == nil {
+		return keywords
+	}
+
+	personal := r.model.Score(feedback.Features(p.Title, p.ContentClean, p.SourceID, r.topics[p.ID]))
+	w := min(r.weight, 1) * min(float64(r.model.Ratings())/personalFullWeightRatings, 1)
+	return keywords*(1-w) + personal*w
+}
+
+// rescoreOptions selects the component scores rescorePosts recomputes
+// before the final score; the others are kept as stored
+type rescoreOptions struct {
+	relevance bool // from the interests and the personal model
+	community bool // from the latest recorded signals
+	novelty   bool // against the posts published earlier
+
+	postIDs []int64 // posts to rescore; nil rescores every scored post
+}
+
+// rescorePosts recomputes the final score of the scored posts from their
+// stored components, after recomputing the components selected by opts.
+func rescorePosts(db *database.DB, cfg *config.Config, opts rescoreOptions) (int, error) {
+	scores, err := score.NewRepository(db).All()
+	if err != nil {
+		return 0, err
+	}
+	if opts.postIDs != nil {
+		selected := make(map[int64]bool, len(opts.postIDs))
+		for _, id := range opts.postIDs {
+			selected[id] = true
+		}
+		kept := scores[:0]
+		for _, s := range scores {
+			if selected[s.PostID] {
+				kept = append(kept, s)
+			}
+		}
+		scores = kept
+	}
+
+	var relevanceScorer *relevance
+	if opts.relevance {
+		if relevanceScorer, err = newRelevance(db, cfg); err != nil {
+			return 0, err
+		}
+	}
+
+	// Novelty is read from the index outside of the transaction
+	if opts.novelty {
+		noveltyScorer, err := noveltyIndex(db)
+		if err != nil {
+			return 0, err
+		}
+		postRepo := post.NewRepository(db)
+		for i := range scores {
+			p, err := postRepo.Get(scores[i].PostID)
+			if err != nil {
+				return 0, err
+			}
+			content := p.ContentClean
+			if content == "" {
+				content = p.Title
+			}
+			if scores[i].NoveltyScore, err = noveltyScorer.Score(p.ID, scores[i].PostedAt, content); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	err = db.Tx(func(tx *database.DB) error {
+		scoreRepo := score.NewRepository(tx)
+		postRepo := post.NewRepository(tx)
+		signalRepo := community.NewRepository(tx)
+
+		for _, s := range scores {
+			if opts.relevance {
+				p, err := postRepo.Get(s.PostID)
+				if err != nil {
+					return err
+				}
+				s.RelevanceScore = relevanceScorer.Score(p)
+			}
+			if opts.community {
+				// Posts scored before signals were recorded keep their score
+				if signals, err := signalRepo.Latest(s.PostID); err != nil {
+					return err
+				} else if len(signals) > 0 {
+					s.CommunityScore = scorer.CommunityScoreFromSignals(signals)
+				}
+			}
+
+			final := calculateFinalScore(cfg.Scoring, s.CommunityScore, s.RelevanceScore, s.NoveltyScore, s.PostedAt)
+			if err := scoreRepo.Upsert(s.PostID, s.CommunityScore, s.RelevanceScore, s.NoveltyScore, final); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(scores), nil
+}
+
+// communityProviders builds the signal providers enabled in the config,
+// leaving out the skipped ones
+func communityProviders(cfg *config.Config, skip map[string]bool) []scorer.CommunitySignal {
+	var providers []scorer.CommunitySignal
+	for _, name := range cfg.Community.Providers {
+		if skip[name] {
+			continue
+		}
+		switch name {
+		case scorer.ProviderHN:
+			providers = append(providers, scorer.NewHNScorer(cfg.Community.HNURL))
+		case scorer.ProviderReddit:
+			providers = append(providers, scorer.NewRedditScorer(cfg.Reddit.BaseURL, cfg.Reddit.Subreddits, cfg.Fetch.UserAgent))
+		case scorer.ProviderLobsters:
+			providers = append(providers, scorer.NewLobstersScorer(cfg.Community.LobstersURL))
+		}
+	}
+	return providers
+}
+
+// checkCommunity looks a post up with every provider and records the
+// snapshots. It returns the latest signal of each provider, falling back to
+// the previous snapshot of a provider that failed.
+func checkCommunity(repo *community.Repository, providers []scorer.CommunitySignal, p *post.Post) ([]scorer.Signal, []error) {
+	var errs []error
+	now := time.Now()
+	for _, provider := range providers {
+		s, err := provider.Lookup(p.URL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			continue
+		}
+		if err := repo.Record(p.ID, s, now); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	signals, err := repo.Latest(p.ID)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return signals, errs
+}
+
+// refreshCommunity re-checks the signals of scored posts that are due and
+// updates their community and final scores. Posts keep their scores when no
+// provider could be reached and they have no earlier signals.
+func refreshCommunity(db *database.DB, cfg *config.Config, providers []scorer.CommunitySignal, limit int,
+	changed func(p *post.Post, before, after float64)) (int, error) {
+	signalRepo := community.NewRepository(db)
+	scoreRepo := score.NewRepository(db)
+	postRepo := post.NewRepository(db)
+
+	maxAge := time.Duration(cfg.Community.RefreshDays) * 24 * time.Hour
+	ids, err := signalRepo.DueForRefresh(time.Now(), maxAge, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	refreshed := 0
+	for _, id := range ids {
+		p, err := postRepo.Get(id)
+		if err != nil {
+			continue
+		}
+		s, err := scoreRepo.Get(id)
+		if err != nil {
+			continue
+		}
+
+		signals, errs := checkCommunity(signalRepo, providers, p)
+		if len(signals) == 0 && len(errs) > 0 {
+			continue // no provider answered and nothing was seen before, e.g. offline
+		}
+		communityScore := scorer.CommunityScoreFromSignals(signals)
+		finalScore := calculateFinalScore(cfg.Scoring, communityScore, s.RelevanceScore, s.NoveltyScore, postedAt(p))
+		if err := scoreRepo.UpdateCommunity(id, communityScore, finalScore); err != nil {
+			return refreshed, err
+		}
+		if changed != nil {
+			changed(p, s.CommunityScore, communityScore)
+		}
+		refreshed++
+	}
+	return refreshed, nil
+}
+
+// formatSignal describes a signal, e.g. "HN: 120 points, 30 comments"
+func formatSignal(s scorer.Signal) string {
+	name := map[string]string{
+		scorer.ProviderHN:       "HN",
+		scorer.ProviderReddit:   "Reddit",
+		scorer.ProviderLobsters: "Lobsters",
+	}[s.Provider]
+	if name == "" {
+		name = s.Provider
+	}
+	detail := fmt.Sprintf("%d points, %d comments", s.Points, s.Comments)
+	if s.Submissions > 1 {
+		detail = fmt.Sprintf("%d submissions, %s", s.Submissions, detail)
+	}
+	return strings.Join([]string{name, detail}, ": ")
+}
@@ -2,11 +2,16 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/julienpequegnot/blogmon/internal/config"
 	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/embedding"
+	"github.com/julienpequegnot/blogmon/internal/graph"
 	"github.com/julienpequegnot/blogmon/internal/search"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
@@ -15,7 +20,11 @@ import (
 var searchCmd = &cobra.Command{
 	Use:   "search <query>",
 	Short: "Search posts by content",
-	Long:  `Full-text search across all post titles and content.`,
+	Long: `Searches post titles and content.
+
+By default results combine full-text (BM25) and semantic (embedding) rankings.
+Use --semantic or --lexical for a single ranking. Semantic search needs
+embeddings, see 'blogmon embed'; without them search falls back to full-text.`,
 	Args:  cobra.MinimumNArgs(1),
 	RunE:  runSearch,
 }
@@ -23,12 +32,19 @@ var searchCmd = &cobra.Command{
 var (
 	searchLimit    int
 	searchUseScore bool
+	searchTopic    string
+	searchSemantic bool
+	searchLexical  bool
 )
 
 func init() {
 	rootCmd.AddCommand(searchCmd)
 	searchCmd.Flags().IntVarP(&searchLimit, "limit", "l", 20, "Maximum results to show")
-	searchCmd.Flags().BoolVar(&searchUseScore, "ranked", false, "Rank by combined relevance and score")
+	searchCmd.Flags().BoolVar(&searchUseScore, "ranked", false, "Rank by combined relevance and score (full-text only)")
+	searchCmd.Flags().StringVar(&searchTopic, "topic", "", "Only show posts tagged with this topic")
+	searchCmd.Flags().BoolVar(&searchSemantic, "semantic", false, "Rank by embedding similarity only")
+	searchCmd.Flags().BoolVar(&searchLexical, "lexical", false, "Rank by full-text match only")
+	searchCmd.MarkFlagsMutuallyExclusive("semantic", "lexical")
 }
 
 func runSearch(cmd *cobra.Command, args []string) error {
@@ -42,13 +58,38 @@ func runSearch(cmd *cobra.Command, args []string) error {
 
 	searchRepo := search.NewRepository(db)
 
+	opts := search.Options{
+		Limit:  searchLimit,
+		Ranked: searchUseScore,
+	}
+	if searchTopic != "" {
+		opts.Topic = graph.NormalizeTopic(searchTopic)
+	}
+
 	var results []search.SearchResult
-	if searchUseScore {
-		results, err = searchRepo.SearchWithScore(query, searchLimit)
+	mode := "lexical"
+	if searchLexical || searchUseScore {
+		results, err = searchRepo.Query(query, opts)
 	} else {
-		results, err = searchRepo.Search(query, searchLimit)
+		var vector []float32
+		var model string
+		vector, model, err = embedQuery(db, query)
+		switch {
+		case err != nil && searchSemantic:
+			return fmt.Errorf("semantic search unavailable: %w", err)
+		case errors.Is(err, errNoEmbeddingModel):
+			results, err = searchRepo.Query(query, opts)
+		case err != nil:
+			fmt.Printf("Semantic search unavailable (%v), using full-text search\n", err)
+			results, err = searchRepo.Query(query, opts)
+		case searchSemantic:
+			mode = "semantic"
+			results, err = searchRepo.Semantic(query, vector, model, opts)
+		default:
+			mode = "hybrid"
+			results, err = searchRepo.Hybrid(query, vector, model, opts)
+		}
 	}
-
 	if err != nil {
 		return fmt.Errorf("search failed: %w", err)
 	}
@@ -64,7 +105,7 @@ func runSearch(cmd *cobra.Command, args []string) error {
 	sourceStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
 	snippetStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("250"))
 
-	fmt.Printf("\n%s '%s' (%d results)\n\n", titleStyle.Render("SEARCH:"), query, len(results))
+	fmt.Printf("\n%s '%s' (%d results, %s)\n\n", titleStyle.Render("SEARCH:"), query, len(results), mode)
 
 	for _, r := range results {
 		fmt.Printf("%s %s\n", idStyle.Render(fmt.Sprintf("[%d]", r.PostID)), r.Title)
@@ -85,3 +126,34 @@ func runSearch(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// embedQuery embeds a search query with the configured model, failing when
+// no posts have been embedded with it yet
+func embedQuery(db *database.DB, query string) ([]float32, string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, "", err
+	}
+
+	embedder, err := newEmbedder(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+
+	counts, err := embedding.NewRepository(db).Count(embedder.Model())
+	if err != nil {
+		return nil, "", err
+	}
+	if counts[embedding.KindPost] == 0 {
+		return nil, "", fmt.Errorf("no posts embedded with %s, run 'blogmon embed'", embedder.Model())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	vectors, err := embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, "", err
+	}
+	return vectors[0], embedder.Model(), nil
+}
@@ -7,10 +7,13 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/blogmon/internal/community"
 	"github.com/julienpequegnot/blogmon/internal/config"
 	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/feedback"
 	"github.com/julienpequegnot/blogmon/internal/insight"
 	"github.com/julienpequegnot/blogmon/internal/link"
+	"github.com/julienpequegnot/blogmon/internal/markdown"
 	"github.com/julienpequegnot/blogmon/internal/post"
 	"github.com/julienpequegnot/blogmon/internal/reference"
 	"github.com/julienpequegnot/blogmon/internal/score"
@@ -29,6 +32,22 @@ func init() {
 	rootCmd.AddCommand(showCmd)
 }
 
+// relatedGroups orders related posts by relationship, as read from the
+// shown post
+var relatedGroups = []struct {
+	label string
+	title string
+}{
+	{link.Contradicts, "contradicts"},
+	{link.RespondsTo, "responds to"},
+	{link.RespondedToBy, "answered by"},
+	{link.Extends, "builds on"},
+	{link.ExtendedBy, "extended by"},
+	{link.Agrees, "agrees with"},
+	{link.SameTopic, "same topic"},
+	{link.SharedTopics, ""},
+}
+
 func runShow(cmd *cobra.Command, args []string) error {
 	id, err := strconv.ParseInt(args[0], 10, 64)
 	if err != nil {
@@ -68,6 +87,14 @@ func runShow(cmd *cobra.Command, args []string) error {
 	if p.FinalScore != nil && *p.FinalScore > 0 {
 		fmt.Printf("%s %.0f\n", labelStyle.Render("Score:"), *p.FinalScore)
 	}
+	if p.ReadingMinutes > 0 {
+		fmt.Printf("%s %s\n", labelStyle.Render("Length:"), valueStyle.Render(fmt.Sprintf(
+			"%d words, %s read, %.0f%% code, %d headings, readability %.0f (%s)",
+			p.WordCount, formatReadingTime(p.ReadingMinutes), p.CodeRatio*100, p.Headings, p.Readability, readabilityLevel(p.Readability))))
+	}
+	if r, err := feedback.NewRepository(db).Get(id); err == nil && r != nil {
+		fmt.Printf("%s %s\n", labelStyle.Render("Rated:"), valueStyle.Render(formatRating(r.Liked)))
+	}
 	fmt.Printf("%s %s\n", labelStyle.Render("URL:"), urlStyle.Render(p.URL))
 
 	// Show score breakdown if available
@@ -76,17 +103,42 @@ func runShow(cmd *cobra.Command, args []string) error {
 		fmt.Printf("\n%s\n", labelStyle.Render("SCORES:"))
 		fmt.Printf("  Community: %.1f  Relevance: %.1f  Novelty: %.1f  → Final: %.1f\n",
 			s.CommunityScore, s.RelevanceScore, s.NoveltyScore, s.FinalScore)
+		if signals, err := community.NewRepository(db).Latest(id); err == nil {
+			for _, sig := range signals {
+				if sig.Submissions > 0 {
+					fmt.Printf("  %s\n", formatSignal(sig))
+				}
+			}
+		}
+	}
+
+	// Show summary
+	if p.Summary != "" {
+		fmt.Printf("\n%s\n", labelStyle.Render("SUMMARY:"))
+		fmt.Println(lipgloss.NewStyle().Width(70).PaddingLeft(2).Render(p.Summary))
 	}
 
-	// Show insights
+	// Show insights, one section per type
 	insightRepo := insight.NewRepository(db)
 	insights, _ := insightRepo.ListForPost(id)
-	if len(insights) > 0 {
-		fmt.Printf("\n%s\n", labelStyle.Render("KEY TAKEAWAYS:"))
-		for _, ins := range insights {
-			if ins.Type == "takeaway" {
-				fmt.Printf("  • %s\n", ins.Content)
-			}
+	byType := make(map[string][]insight.Insight)
+	for _, ins := range insights {
+		byType[ins.Type] = append(byType[ins.Type], ins)
+	}
+
+	sections := []struct{ insightType, label string }{
+		{insight.TypeTakeaway, "KEY TAKEAWAYS:"},
+		{insight.TypeQuote, "QUOTES:"},
+		{insight.TypeDefinition, "DEFINITIONS:"},
+		{insight.TypeCodeExample, "CODE EXAMPLES:"},
+	}
+	for _, section := range sections {
+		if len(byType[section.insightType]) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s\n", labelStyle.Render(section.label))
+		for _, ins := range byType[section.insightType] {
+			printInsightBody(ins)
 		}
 	}
 
@@ -96,23 +148,41 @@ func runShow(cmd *cobra.Command, args []string) error {
 	if len(refs) > 0 {
 		fmt.Printf("\n%s\n", labelStyle.Render("REFERENCES:"))
 		for _, ref := range refs {
+			marker := ""
+			if ref.Origin == reference.OriginLLM {
+				marker = labelStyle.Render(" (unlinked)")
+			}
 			if ref.Title != "" {
-				fmt.Printf("  → %s (%s)\n", ref.Title, ref.URL)
+				fmt.Printf("  → %s (%s)%s\n", ref.Title, ref.URL, marker)
 			} else {
-				fmt.Printf("  → %s\n", ref.URL)
+				fmt.Printf("  → %s%s\n", ref.URL, marker)
 			}
 		}
 	}
 
-	// Show related posts
-	linkRepo := link.NewRepository(db)
-	relatedIDs, _ := linkRepo.GetRelatedPosts(id, 5)
-	if len(relatedIDs) > 0 {
+	// Show related posts, grouped by how this post relates to them
+	related, _ := link.NewRepository(db).Related(id, 8)
+	if len(related) > 0 {
 		fmt.Printf("\n%s\n", labelStyle.Render("RELATED POSTS:"))
+		relationStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
 		postRepo := post.NewRepository(db)
-		for _, relID := range relatedIDs {
-			if relPost, err := postRepo.Get(relID); err == nil {
-				fmt.Printf("  → [%d] %s\n", relPost.ID, relPost.Title)
+		for _, group := range relatedGroups {
+			for _, rel := range related {
+				if rel.Label != group.label {
+					continue
+				}
+				relPost, err := postRepo.Get(rel.PostID)
+				if err != nil {
+					continue
+				}
+				if rel.Label == link.SharedTopics {
+					fmt.Printf("  → [%d] %s\n", relPost.ID, relPost.Title)
+					continue
+				}
+				fmt.Printf("  %s [%d] %s\n", relationStyle.Render(fmt.Sprintf("%-11s", group.title)), relPost.ID, relPost.Title)
+				if rel.Detail != "" {
+					fmt.Printf("              %s\n", labelStyle.Render(rel.Detail))
+				}
 			}
 		}
 	}
@@ -122,7 +192,7 @@ func runShow(cmd *cobra.Command, args []string) error {
 	// Show content preview
 	content := p.ContentClean
 	if content == "" {
-		content = stripHTML(p.ContentRaw)
+		content = markdown.Text(p.ContentRaw)
 	}
 	if len(content) > 500 {
 		content = content[:500] + "..."
@@ -136,17 +206,24 @@ func runShow(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func stripHTML(s string) string {
-	var result strings.Builder
-	inTag := false
-	for _, r := range s {
-		if r == '<' {
-			inTag = true
-		} else if r == '>' {
-			inTag = false
-		} else if !inTag {
-			result.WriteRune(r)
-		}
+func printCodeExample(ins insight.Insight) {
+	fmt.Printf("  ```%s\n", ins.Detail)
+	for _, line := range strings.Split(strings.TrimRight(ins.Content, "\n"), "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+	fmt.Println("  ```")
+}
+
+// readabilityLevel names a Flesch reading ease score
+func readabilityLevel(score float64) string {
+	switch {
+	case score >= 70:
+		return "easy"
+	case score >= 50:
+		return "moderate"
+	case score >= 30:
+		return "difficult"
+	default:
+		return "very difficult"
 	}
-	return strings.TrimSpace(result.String())
 }
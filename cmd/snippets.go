@@ -0,0 +1,171 @@
+// cmd/snippets.go
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/blogmon/internal/config"
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/julienpequegnot/blogmon/internal/snippet"
+	"github.com/spf13/cobra"
+)
+
+var snippetsCmd = &cobra.Command{
+	Use:   "snippets",
+	Short: "Browse the code snippet library",
+	Long: `Code blocks are extracted from posts when they are fetched, together with
+their language and the paragraph introducing them.`,
+	RunE: runSnippetsLanguages,
+}
+
+var snippetsSearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search code snippets",
+	Long:  `Searches the code and captions of snippets. Each term (3+ characters) is matched literally as a substring, e.g. "context.WithTimeout".`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runSnippetsSearch,
+}
+
+var snippetsRebuildCmd = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Extract snippets again from every stored post",
+	RunE:  runSnippetsRebuild,
+}
+
+var (
+	snippetsLang  string
+	snippetsLimit int
+)
+
+func init() {
+	rootCmd.AddCommand(snippetsCmd)
+	snippetsCmd.AddCommand(snippetsSearchCmd, snippetsRebuildCmd)
+	snippetsSearchCmd.Flags().StringVar(&snippetsLang, "lang", "", "Only show snippets in this language")
+	snippetsSearchCmd.Flags().IntVarP(&snippetsLimit, "limit", "l", 10, "Maximum results")
+}
+
+func runSnippetsLanguages(cmd *cobra.Command, args []string) error {
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	counts, err := snippet.NewRepository(db).Languages()
+	if err != nil {
+		return err
+	}
+
+	if len(counts) == 0 {
+		fmt.Println("No snippets found. Run 'blogmon fetch' or 'blogmon snippets rebuild' first.")
+		return nil
+	}
+
+	langs := make([]string, 0, len(counts))
+	for lang := range counts {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool { return counts[langs[i]] > counts[langs[j]] })
+
+	for _, lang := range langs {
+		name := lang
+		if name == "" {
+			name = "(unknown)"
+		}
+		fmt.Printf("%-12s %d\n", name, counts[lang])
+	}
+	fmt.Println("\nSearch with: blogmon snippets search <query> [--lang <language>]")
+	return nil
+}
+
+func runSnippetsSearch(cmd *cobra.Command, args []string) error {
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	query := strings.Join(args, " ")
+	results, err := snippet.NewRepository(db).Search(query, snippetsLang, snippetsLimit)
+	if err != nil {
+		return fmt.Errorf("search failed: %w", err)
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("No snippets found for: %s\n", query)
+		return nil
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	idStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	langStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	captionStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("250"))
+
+	fmt.Printf("Found %d snippets for: %s\n\n", len(results), query)
+
+	for _, r := range results {
+		lang := r.Language
+		if lang == "" {
+			lang = "code"
+		}
+		fmt.Printf("%s %s %s\n", idStyle.Render(fmt.Sprintf("[%d]", r.PostID)), titleStyle.Render(r.PostTitle), langStyle.Render(lang))
+		fmt.Printf("    %s\n", idStyle.Render(r.PostURL))
+		if r.Caption != "" {
+			fmt.Printf("    %s\n", captionStyle.Render(r.Caption))
+		}
+		printSnippet(r.Code, r.Language, 15)
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func runSnippetsRebuild(cmd *cobra.Command, args []string) error {
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	postRepo := post.NewRepository(db)
+	snippetRepo := snippet.NewRepository(db)
+
+	posts, err := postRepo.List(-1, 0)
+	if err != nil {
+		return err
+	}
+
+	total := 0
+	for _, summary := range posts {
+		p, err := postRepo.Get(summary.ID)
+		if err != nil {
+			return err
+		}
+		snippets := snippet.Extract(p.ContentRaw)
+		if err := snippetRepo.ReplaceForPost(p.ID, snippets); err != nil {
+			return err
+		}
+		total += len(snippets)
+	}
+
+	fmt.Printf("Extracted %d snippets from %d posts\n", total, len(posts))
+	return nil
+}
+
+// printSnippet prints a fenced code block, cut after maxLines lines
+func printSnippet(code, language string, maxLines int) {
+	lines := strings.Split(code, "\n")
+	fmt.Printf("    ```%s\n", language)
+	for i, line := range lines {
+		if i == maxLines {
+			fmt.Printf("    ... (%d more lines)\n", len(lines)-maxLines)
+			break
+		}
+		fmt.Printf("    %s\n", line)
+	}
+	fmt.Println("    ```")
+}
@@ -0,0 +1,117 @@
+// cmd/stats.go
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/blogmon/internal/config"
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show usage statistics",
+}
+
+var statsLLMCmd = &cobra.Command{
+	Use:   "llm",
+	Short: "Show LLM throughput, latency and failure rates",
+	Long: `Reports the LLM calls recorded by extract, summaries, ask and the daemon:
+per pipeline stage, per model, and per day and model to compare models over
+time. Throughput is the number of response tokens generated per second of
+successful calls, as reported by Ollama.`,
+	RunE: runStatsLLM,
+}
+
+var statsDays int
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.AddCommand(statsLLMCmd)
+	statsLLMCmd.Flags().IntVar(&statsDays, "days", 30, "Time window in days")
+}
+
+func runStatsLLM(cmd *cobra.Command, args []string) error {
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	repo := usage.NewRepository(db)
+	since := time.Now().AddDate(0, 0, -statsDays)
+
+	byStage, err := repo.Stats(usage.ByStage, since)
+	if err != nil {
+		return err
+	}
+	if len(byStage) == 0 {
+		fmt.Printf("No LLM calls in the last %d days.\n", statsDays)
+		return nil
+	}
+
+	byModel, err := repo.Stats(usage.ByModel, since)
+	if err != nil {
+		return err
+	}
+	byDay, err := repo.Stats(usage.ByDay, since)
+	if err != nil {
+		return err
+	}
+	classes, err := repo.ErrorClasses(since)
+	if err != nil {
+		return err
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+
+	fmt.Printf("\n%s (last %d days)\n", titleStyle.Render("LLM USAGE"), statsDays)
+
+	printUsageTable(headerStyle, "STAGE", byStage, false)
+	printUsageTable(headerStyle, "MODEL", byModel, false)
+	printUsageTable(headerStyle, "DAY", byDay, true)
+
+	if len(classes) > 0 {
+		names := make([]string, 0, len(classes))
+		for class := range classes {
+			names = append(names, class)
+		}
+		sort.Slice(names, func(i, j int) bool { return classes[names[i]] > classes[names[j]] })
+
+		fmt.Printf("\n%s\n", headerStyle.Render("FAILURES"))
+		for _, class := range names {
+			fmt.Printf("%-12s %d\n", class, classes[class])
+		}
+	}
+
+	fmt.Println()
+	return nil
+}
+
+func printUsageTable(headerStyle lipgloss.Style, group string, stats []usage.Stat, withModel bool) {
+	fmt.Println()
+	if withModel {
+		fmt.Println(headerStyle.Render(fmt.Sprintf("%-12s %-20s %6s %7s %9s %9s %10s %8s",
+			group, "MODEL", "CALLS", "FAILED", "AVG", "MAX", "TOKENS", "TOK/S")))
+	} else {
+		fmt.Println(headerStyle.Render(fmt.Sprintf("%-20s %6s %7s %9s %9s %10s %8s",
+			group, "CALLS", "FAILED", "AVG", "MAX", "TOKENS", "TOK/S")))
+	}
+
+	for _, s := range stats {
+		name := fmt.Sprintf("%-20s", truncateLinkTitle(s.Group, 20))
+		if withModel {
+			name = fmt.Sprintf("%-12s %-20s", s.Group, truncateLinkTitle(s.Model, 20))
+		}
+		fmt.Printf("%s %6d %6.1f%% %9s %9s %10d %8.1f\n",
+			name, s.Calls, s.FailureRate()*100,
+			s.AvgLatency().Round(10*time.Millisecond), s.MaxLatency.Round(10*time.Millisecond),
+			s.PromptTokens+s.ResponseTokens, s.TokensPerSecond())
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/julienpequegnot/blogmon/internal/database"
 	"github.com/julienpequegnot/blogmon/internal/graph"
 	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/julienpequegnot/blogmon/internal/topic"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 )
@@ -50,12 +51,17 @@ func runTrends(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	storedTopics, err := topic.NewRepository(db).ListAll()
+	if err != nil {
+		return err
+	}
+
 	// Build trend analyzer
 	analyzer := graph.NewTrendAnalyzer()
 
 	for _, p := range posts {
 		content := p.Title + " " + p.ContentClean
-		topics := graph.ExtractTopics(content)
+		topics := graph.ResolveTopics(storedTopics[p.ID], content)
 		// Handle nil PublishedAt
 		publishedAt := time.Now()
 		if p.PublishedAt != nil {
@@ -0,0 +1,56 @@
+package card
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/julienpequegnot/blogmon/internal/markdown"
+)
+
+// ClozeBlank replaces the hidden word in a cloze question
+const ClozeBlank = "[...]"
+
+// minClozeWord is the shortest word worth hiding
+const minClozeWord = 5
+
+// Common words too vague to make a useful blank
+var clozeStopWords = map[string]bool{
+	"about": true, "after": true, "always": true, "because": true, "before": true, "being": true,
+	"could": true, "every": true, "instead": true, "never": true, "often": true, "other": true,
+	"rather": true, "really": true, "should": true, "their": true, "there": true, "these": true,
+	"those": true, "through": true, "using": true, "where": true, "which": true, "while": true,
+	"without": true, "would": true, "things": true, "something": true, "important": true,
+}
+
+var clozeWord = regexp.MustCompile(`[\pL\pN][\pL\pN_.+#/-]*[\pL\pN+#]`)
+
+// Cloze hides the most specific word of a takeaway: the longest of the known
+// terms it contains, e.g. the post's topics, or else its longest word that is
+// not a common one. It returns the question with the word blanked and the
+// word, or false when no word is worth hiding.
+func Cloze(text string, terms []string) (string, string, bool) {
+	best, start, end := "", -1, -1
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if len(term) <= len(best) {
+			continue
+		}
+		if found := markdown.FindWords(text, term); len(found) > 0 {
+			best, start, end = term, found[0][0], found[0][1]
+		}
+	}
+
+	if best == "" {
+		for _, loc := range clozeWord.FindAllStringIndex(text, -1) {
+			word := text[loc[0]:loc[1]]
+			if len(word) >= minClozeWord && len(word) > len(best) && !clozeStopWords[strings.ToLower(word)] {
+				best, start, end = word, loc[0], loc[1]
+			}
+		}
+	}
+
+	if start < 0 {
+		return "", "", false
+	}
+	return text[:start] + ClozeBlank + text[end:], text[start:end], true
+}
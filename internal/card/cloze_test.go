@@ -0,0 +1,29 @@
+package card
+
+import "testing"
+
+func TestCloze(t *testing.T) {
+	tests := []struct {
+		text     string
+		terms    []string
+		question string
+		answer   string
+	}{
+		{"Size Postgres pools with Little's law", []string{"postgres", "performance"}, "Size [...] pools with Little's law", "Postgres"},
+		{"Measure tail latencies before tuning", nil, "Measure tail [...] before tuning", "latencies"},
+		{"Always measure before you change things", []string{"go"}, "Always [...] before you change things", "measure"},
+		{"Tune PostgreSQL’s planner", []string{"postgresql"}, "Tune [...]’s planner", "PostgreSQL"},
+		{"ȺȺȺ then Rust wins", []string{"rust"}, "ȺȺȺ then [...] wins", "Rust"},
+	}
+
+	for _, tt := range tests {
+		question, answer, ok := Cloze(tt.text, tt.terms)
+		if !ok || question != tt.question || answer != tt.answer {
+			t.Errorf("Cloze(%q) = %q, %q, %v; want %q, %q", tt.text, question, answer, ok, tt.question, tt.answer)
+		}
+	}
+
+	if _, _, ok := Cloze("Do it now", nil); ok {
+		t.Error("expected no cloze without a specific word")
+	}
+}
@@ -0,0 +1,181 @@
+package card
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/insight"
+	"github.com/julienpequegnot/blogmon/internal/post"
+)
+
+// Card kinds
+const (
+	KindQA    = "qa"
+	KindCloze = "cloze"
+)
+
+// Card is a flashcard made from an insight. Question and answer are copied
+// from the insight, so cards survive re-extraction.
+type Card struct {
+	ID             int64
+	PostID         int64
+	InsightID      int64
+	Kind           string
+	Question       string
+	Answer         string
+	PostTitle      string
+	PostURL        string
+	LastReviewedAt *time.Time
+	State
+}
+
+// Candidate is an insight without a card yet
+type Candidate struct {
+	insight.Insight
+	PostTitle string
+}
+
+// Counts summarizes the card deck
+type Counts struct {
+	Total int
+	Due   int
+	New   int // never reviewed
+}
+
+type Repository struct {
+	db *database.DB
+}
+
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// SourceHash identifies the insight a card was made from across
+// re-extractions, which give insights new IDs
+func SourceHash(ins insight.Insight) string {
+	return post.ContentHash(fmt.Sprintf("%d\n%s\n%s\n%s", ins.PostID, ins.Type, ins.Detail, ins.Content))
+}
+
+// Candidates returns takeaways and definitions with at least minImportance
+// that have no card yet, most important first
+func (r *Repository) Candidates(minImportance, limit int) ([]Candidate, error) {
+	hashes, err := r.sourceHashes()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT i.id, i.post_id, i.type, i.content, COALESCE(i.detail, ''), COALESCE(i.importance, 0), p.title
+		FROM insights i
+		JOIN posts p ON i.post_id = p.id
+		WHERE i.type IN (?, ?) AND COALESCE(i.importance, 0) >= ?
+		ORDER BY i.importance DESC, p.published_at DESC, i.id
+	`, insight.TypeTakeaway, insight.TypeDefinition, minImportance)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []Candidate
+	for rows.Next() && len(candidates) < limit {
+		var c Candidate
+		if err := rows.Scan(&c.ID, &c.PostID, &c.Type, &c.Content, &c.Detail, &c.Importance, &c.PostTitle); err != nil {
+			return nil, err
+		}
+		if !hashes[SourceHash(c.Insight)] {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates, rows.Err()
+}
+
+func (r *Repository) sourceHashes() (map[string]bool, error) {
+	rows, err := r.db.Query(`SELECT source_hash FROM cards`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := make(map[string]bool)
+	for rows.Next() {
+		var h string
+		if err := rows.Scan(&h); err != nil {
+			return nil, err
+		}
+		hashes[h] = true
+	}
+	return hashes, rows.Err()
+}
+
+// Add stores a new card for an insight, due immediately. It returns false
+// when the insight already has a card.
+func (r *Repository) Add(ins insight.Insight, kind, question, answer string, now time.Time) (bool, error) {
+	s := NewState(now)
+	result, err := r.db.Exec(`
+		INSERT OR IGNORE INTO cards (post_id, insight_id, kind, question, answer, source_hash, ease, interval_days, repetitions, lapses, due_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, ins.PostID, ins.ID, kind, question, answer, SourceHash(ins), s.Ease, s.IntervalDays, s.Repetitions, s.Lapses,
+		s.DueAt.UTC(), now.UTC())
+	if err != nil {
+		return false, fmt.Errorf("failed to insert card: %w", err)
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// Due returns the cards due at the given time, most overdue first
+func (r *Repository) Due(now time.Time, limit int) ([]Card, error) {
+	return r.list(`WHERE c.due_at <= ? ORDER BY c.due_at, c.id LIMIT ?`, now.UTC(), limit)
+}
+
+// All returns every card, oldest first
+func (r *Repository) All() ([]Card, error) {
+	return r.list(`ORDER BY c.id`)
+}
+
+func (r *Repository) list(clause string, args ...any) ([]Card, error) {
+	rows, err := r.db.Query(`
+		SELECT c.id, c.post_id, c.insight_id, c.kind, c.question, c.answer, COALESCE(p.title, ''), COALESCE(p.url, ''),
+		       c.ease, c.interval_days, c.repetitions, c.lapses, c.due_at, c.last_reviewed_at
+		FROM cards c
+		LEFT JOIN posts p ON c.post_id = p.id
+		`+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cards []Card
+	for rows.Next() {
+		var c Card
+		if err := rows.Scan(&c.ID, &c.PostID, &c.InsightID, &c.Kind, &c.Question, &c.Answer, &c.PostTitle, &c.PostURL,
+			&c.Ease, &c.IntervalDays, &c.Repetitions, &c.Lapses, &c.DueAt, &c.LastReviewedAt); err != nil {
+			return nil, err
+		}
+		cards = append(cards, c)
+	}
+	return cards, rows.Err()
+}
+
+// Review schedules a card after a review graded 0-5 and returns its new state
+func (r *Repository) Review(c Card, grade int, now time.Time) (State, error) {
+	s := Schedule(c.State, grade, now)
+	_, err := r.db.Exec(`
+		UPDATE cards SET ease = ?, interval_days = ?, repetitions = ?, lapses = ?, due_at = ?, last_reviewed_at = ?
+		WHERE id = ?
+	`, s.Ease, s.IntervalDays, s.Repetitions, s.Lapses, s.DueAt.UTC(), now.UTC(), c.ID)
+	return s, err
+}
+
+// Count summarizes the deck at the given time
+func (r *Repository) Count(now time.Time) (Counts, error) {
+	var c Counts
+	err := r.db.QueryRow(`
+		SELECT COUNT(*),
+		       COALESCE(SUM(CASE WHEN due_at <= ? THEN 1 ELSE 0 END), 0),
+		       COALESCE(SUM(CASE WHEN last_reviewed_at IS NULL THEN 1 ELSE 0 END), 0)
+		FROM cards
+	`, now.UTC()).Scan(&c.Total, &c.Due, &c.New)
+	return c, err
+}
@@ -0,0 +1,106 @@
+package card
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/insight"
+	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/julienpequegnot/blogmon/internal/source"
+)
+
+func setupTestDB(t *testing.T) (*database.DB, int64) {
+	tmpDir := t.TempDir()
+	db, err := database.New(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+
+	srcRepo := source.NewRepository(db)
+	src, _ := srcRepo.Add("https://test.com", "Test", "")
+
+	postRepo := post.NewRepository(db)
+	p, _ := postRepo.Add(src.ID, "https://test.com/post", "Pools", "Author", time.Now(), "content")
+
+	return db, p.ID
+}
+
+func TestCandidatesSkipCardedInsights(t *testing.T) {
+	db, postID := setupTestDB(t)
+	defer db.Close()
+
+	insightRepo := insight.NewRepository(db)
+	insightRepo.Add(postID, insight.TypeTakeaway, "Size pools with Little's law", 5)
+	insightRepo.Add(postID, insight.TypeTakeaway, "Minor point", 1)
+	insightRepo.AddWithDetail(postID, insight.TypeDefinition, "99th percentile latency", "p99", 3)
+	insightRepo.Add(postID, insight.TypeQuote, "Measure first", 5)
+
+	repo := NewRepository(db)
+	candidates, err := repo.Candidates(3, 10)
+	if err != nil {
+		t.Fatalf("failed to list candidates: %v", err)
+	}
+	if len(candidates) != 2 || candidates[0].Content != "Size pools with Little's law" || candidates[0].PostTitle != "Pools" {
+		t.Fatalf("unexpected candidates: %+v", candidates)
+	}
+
+	added, err := repo.Add(candidates[0].Insight, KindQA, "How to size a pool?", "Little's law", time.Now())
+	if err != nil || !added {
+		t.Fatalf("failed to add card: %v", err)
+	}
+
+	// Re-extraction gives the insight a new ID but the same content
+	insightRepo.DeleteForPost(postID)
+	insightRepo.Add(postID, insight.TypeTakeaway, "Size pools with Little's law", 5)
+
+	candidates, _ = repo.Candidates(3, 10)
+	if len(candidates) != 0 {
+		t.Errorf("expected re-extracted insights to keep their card, got %+v", candidates)
+	}
+}
+
+func TestReviewReschedules(t *testing.T) {
+	db, postID := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	repo := NewRepository(db)
+	ins := insight.Insight{ID: 1, PostID: postID, Type: insight.TypeTakeaway, Content: "Measure p99"}
+	if _, err := repo.Add(ins, KindCloze, "Measure [...]", "p99", now); err != nil {
+		t.Fatalf("failed to add card: %v", err)
+	}
+	if added, _ := repo.Add(ins, KindCloze, "Measure [...]", "p99", now); added {
+		t.Error("expected a second card for the same insight to be ignored")
+	}
+
+	due, err := repo.Due(now.Add(time.Second), 10)
+	if err != nil || len(due) != 1 {
+		t.Fatalf("expected 1 due card, got %d (%v)", len(due), err)
+	}
+	if due[0].PostTitle != "Pools" || due[0].Ease != 2.5 {
+		t.Errorf("unexpected card: %+v", due[0])
+	}
+
+	state, err := repo.Review(due[0], GradeGood, now)
+	if err != nil {
+		t.Fatalf("failed to review: %v", err)
+	}
+	if state.IntervalDays != 1 || state.Repetitions != 1 {
+		t.Errorf("unexpected state: %+v", state)
+	}
+
+	due, _ = repo.Due(now.Add(time.Hour), 10)
+	if len(due) != 0 {
+		t.Errorf("expected no due cards after review, got %d", len(due))
+	}
+
+	counts, err := repo.Count(now.AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatalf("failed to count: %v", err)
+	}
+	if counts != (Counts{Total: 1, Due: 1, New: 0}) {
+		t.Errorf("unexpected counts: %+v", counts)
+	}
+}
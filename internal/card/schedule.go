@@ -0,0 +1,61 @@
+package card
+
+import (
+	"math"
+	"time"
+)
+
+// Grades of a review, on the SM-2 scale of 0 (blackout) to 5 (perfect)
+const (
+	GradeAgain = 1
+	GradeHard  = 3
+	GradeGood  = 4
+	GradeEasy  = 5
+)
+
+const (
+	initialEase = 2.5
+	minEase     = 1.3
+)
+
+// State is the SM-2 scheduling state of a card
+type State struct {
+	Ease         float64
+	IntervalDays int
+	Repetitions  int
+	Lapses       int
+	DueAt        time.Time
+}
+
+// NewState is the state of a card never reviewed, due now
+func NewState(now time.Time) State {
+	return State{Ease: initialEase, DueAt: now}
+}
+
+// Schedule applies the SM-2 algorithm to a review graded 0-5 at the given
+// time. Grades below 3 restart the card at a one day interval; the ease
+// factor moves with every grade and never drops below 1.3.
+func Schedule(s State, grade int, now time.Time) State {
+	grade = max(0, min(5, grade))
+
+	if grade < 3 {
+		s.Repetitions = 0
+		s.IntervalDays = 1
+		s.Lapses++
+	} else {
+		switch s.Repetitions {
+		case 0:
+			s.IntervalDays = 1
+		case 1:
+			s.IntervalDays = 6
+		default:
+			s.IntervalDays = int(math.Round(float64(s.IntervalDays) * s.Ease))
+		}
+		s.Repetitions++
+	}
+
+	q := float64(5 - grade)
+	s.Ease = math.Max(minEase, s.Ease+0.1-q*(0.08+q*0.02))
+	s.DueAt = now.AddDate(0, 0, s.IntervalDays)
+	return s
+}
@@ -0,0 +1,53 @@
+package card
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleIntervals(t *testing.T) {
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	s := NewState(now)
+
+	var intervals []int
+	for i := 0; i < 4; i++ {
+		s = Schedule(s, GradeGood, now)
+		intervals = append(intervals, s.IntervalDays)
+	}
+
+	// Good keeps the ease at 2.5: 1, 6, then interval * 2.5
+	want := []int{1, 6, 15, 38}
+	for i := range want {
+		if intervals[i] != want[i] {
+			t.Fatalf("expected intervals %v, got %v", want, intervals)
+		}
+	}
+	if s.Ease != 2.5 {
+		t.Errorf("expected ease 2.5 after good grades, got %f", s.Ease)
+	}
+	if !s.DueAt.Equal(now.AddDate(0, 0, 38)) {
+		t.Errorf("expected due in 38 days, got %v", s.DueAt)
+	}
+}
+
+func TestScheduleLapse(t *testing.T) {
+	now := time.Now()
+	s := NewState(now)
+	s = Schedule(s, GradeEasy, now)
+	s = Schedule(s, GradeEasy, now)
+	if s.Ease <= initialEase {
+		t.Errorf("expected easy grades to raise the ease, got %f", s.Ease)
+	}
+
+	s = Schedule(s, GradeAgain, now)
+	if s.Repetitions != 0 || s.IntervalDays != 1 || s.Lapses != 1 {
+		t.Errorf("expected a lapse to restart the card, got %+v", s)
+	}
+
+	for i := 0; i < 10; i++ {
+		s = Schedule(s, GradeAgain, now)
+	}
+	if s.Ease != minEase {
+		t.Errorf("expected ease to bottom out at %f, got %f", minEase, s.Ease)
+	}
+}
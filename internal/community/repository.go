@@ -0,0 +1,152 @@
+// Package community keeps the history of community signals of posts, the
+// points and comments they got on HN, Reddit and Lobsters over time.
+package community
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/scorer"
+)
+
+// Bounds of the refresh interval of a post
+const (
+	MinRefreshInterval = time.Hour
+	MaxRefreshInterval = 7 * 24 * time.Hour
+)
+
+// Snapshot is a signal of a post as checked at a point in time
+type Snapshot struct {
+	PostID int64
+	scorer.Signal
+	CheckedAt time.Time
+}
+
+type Repository struct {
+	db *database.DB
+}
+
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// RefreshInterval is how long to wait before re-checking a post that was age
+// old when last checked: a quarter of its age, between an hour and a week.
+// Posts gather most of their votes in their first days, so checks thin out
+// as they age.
+func RefreshInterval(age time.Duration) time.Duration {
+	return min(max(age/4, MinRefreshInterval), MaxRefreshInterval)
+}
+
+// Record stores a snapshot of a post's signal
+func (r *Repository) Record(postID int64, s scorer.Signal, at time.Time) error {
+	_, err := r.db.Exec(`
+		INSERT INTO signals (post_id, provider, points, comments, submissions, checked_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, postID, s.Provider, s.Points, s.Comments, s.Submissions, at.UTC())
+	return err
+}
+
+// Latest returns the most recent signal of each provider for a post
+func (r *Repository) Latest(postID int64) ([]scorer.Signal, error) {
+	rows, err := r.db.Query(`
+		SELECT provider, points, comments, submissions
+		FROM signals s
+		WHERE post_id = ? AND id = (
+			SELECT MAX(id) FROM signals WHERE post_id = s.post_id AND provider = s.provider
+		)
+		ORDER BY provider
+	`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var signals []scorer.Signal
+	for rows.Next() {
+		var s scorer.Signal
+		if err := rows.Scan(&s.Provider, &s.Points, &s.Comments, &s.Submissions); err != nil {
+			return nil, err
+		}
+		signals = append(signals, s)
+	}
+	return signals, rows.Err()
+}
+
+// History returns every snapshot of a post, oldest first
+func (r *Repository) History(postID int64) ([]Snapshot, error) {
+	rows, err := r.db.Query(`
+		SELECT post_id, provider, points, comments, submissions, checked_at
+		FROM signals
+		WHERE post_id = ?
+		ORDER BY checked_at, id
+	`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []Snapshot
+	for rows.Next() {
+		var s Snapshot
+		if err := rows.Scan(&s.PostID, &s.Provider, &s.Points, &s.Comments, &s.Submissions, &s.CheckedAt); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+// DueForRefresh returns the scored posts younger than maxAge whose signals
+// are due for a re-check, newest first. Posts scored before signals were
+// recorded are due right away.
+func (r *Repository) DueForRefresh(now time.Time, maxAge time.Duration, limit int) ([]int64, error) {
+	rows, err := r.db.Query(`
+		SELECT p.id, p.published_at, p.fetched_at, MAX(sg.checked_at)
+		FROM posts p
+		JOIN scores sc ON sc.post_id = p.id
+		LEFT JOIN signals sg ON sg.post_id = p.id
+		WHERE datetime(COALESCE(p.published_at, p.fetched_at)) >= datetime(?)
+		GROUP BY p.id
+	`, now.Add(-maxAge).UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type due struct {
+		id        int64
+		published time.Time
+	}
+	var posts []due
+	for rows.Next() {
+		var id int64
+		var publishedAt *time.Time
+		var fetchedAt time.Time
+		var lastChecked sql.NullString
+		if err := rows.Scan(&id, &publishedAt, &fetchedAt, &lastChecked); err != nil {
+			return nil, err
+		}
+
+		published := fetchedAt
+		if publishedAt != nil {
+			published = *publishedAt
+		}
+		if checked := database.ParseTime(lastChecked); checked != nil && checked.Add(RefreshInterval(checked.Sub(published))).After(now) {
+			continue
+		}
+		posts = append(posts, due{id, published})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(posts, func(i, j int) bool { return posts[i].published.After(posts[j].published) })
+	var ids []int64
+	for i := 0; i < len(posts) && i < limit; i++ {
+		ids = append(ids, posts[i].id)
+	}
+	return ids, nil
+}
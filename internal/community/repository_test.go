@@ -0,0 +1,111 @@
+package community
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/julienpequegnot/blogmon/internal/score"
+	"github.com/julienpequegnot/blogmon/internal/scorer"
+	"github.com/julienpequegnot/blogmon/internal/source"
+)
+
+func setupTestDB(t *testing.T) (*database.DB, *post.Repository, int64) {
+	tmpDir := t.TempDir()
+	db, err := database.New(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+
+	srcRepo := source.NewRepository(db)
+	src, _ := srcRepo.Add("https://test.com", "Test", "")
+
+	return db, post.NewRepository(db), src.ID
+}
+
+func TestLatestAndHistory(t *testing.T) {
+	db, postRepo, srcID := setupTestDB(t)
+	defer db.Close()
+
+	p, _ := postRepo.Add(srcID, "https://test.com/p1", "Post", "Author", time.Now(), "content")
+	repo := NewRepository(db)
+
+	start := time.Now().Add(-2 * time.Hour)
+	repo.Record(p.ID, scorer.Signal{Provider: scorer.ProviderHN}, start)
+	repo.Record(p.ID, scorer.Signal{Provider: scorer.ProviderReddit, Points: 5, Comments: 1, Submissions: 1}, start)
+	repo.Record(p.ID, scorer.Signal{Provider: scorer.ProviderHN, Points: 120, Comments: 30, Submissions: 1}, start.Add(time.Hour))
+
+	latest, err := repo.Latest(p.ID)
+	if err != nil {
+		t.Fatalf("failed to get latest signals: %v", err)
+	}
+	if len(latest) != 2 || latest[0].Provider != scorer.ProviderHN || latest[0].Points != 120 || latest[1].Points != 5 {
+		t.Errorf("unexpected latest signals: %+v", latest)
+	}
+
+	history, err := repo.History(p.ID)
+	if err != nil {
+		t.Fatalf("failed to get history: %v", err)
+	}
+	if len(history) != 3 || history[0].Points != 0 || history[2].Points != 120 {
+		t.Errorf("unexpected history: %+v", history)
+	}
+}
+
+func TestDueForRefresh(t *testing.T) {
+	db, postRepo, srcID := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	scoreRepo := score.NewRepository(db)
+	add := func(url string, age time.Duration) int64 {
+		p, _ := postRepo.Add(srcID, url, "Post", "Author", now.Add(-age), "content")
+		scoreRepo.Upsert(p.ID, 0, 50, 50, 30)
+		return p.ID
+	}
+	day := 24 * time.Hour
+
+	fresh := add("https://test.com/fresh", 8*time.Hour)    // checked 1h ago, interval 2h
+	recent := add("https://test.com/recent", 4*day)        // checked 2 days ago, interval 1 day
+	week := add("https://test.com/week", 8*day)            // checked 1 day ago, interval ~2 days
+	unchecked := add("https://test.com/unchecked", 20*day) // scored before signals existed
+	add("https://test.com/old", 60*day)                    // older than the refresh window
+	unscored, _ := postRepo.Add(srcID, "https://test.com/unscored", "Post", "Author", now, "content")
+
+	repo := NewRepository(db)
+	repo.Record(fresh, scorer.Signal{Provider: scorer.ProviderHN}, now.Add(-time.Hour))
+	repo.Record(recent, scorer.Signal{Provider: scorer.ProviderHN}, now.Add(-2*day))
+	repo.Record(week, scorer.Signal{Provider: scorer.ProviderHN}, now.Add(-day))
+
+	ids, err := repo.DueForRefresh(now, 30*day, 10)
+	if err != nil {
+		t.Fatalf("failed to get due posts: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != recent || ids[1] != unchecked {
+		t.Errorf("expected recent then unchecked posts, got %v (unscored %d)", ids, unscored.ID)
+	}
+
+	ids, _ = repo.DueForRefresh(now, 30*day, 1)
+	if len(ids) != 1 {
+		t.Errorf("expected the limit to apply, got %v", ids)
+	}
+}
+
+func TestRefreshInterval(t *testing.T) {
+	tests := []struct {
+		age  time.Duration
+		want time.Duration
+	}{
+		{10 * time.Minute, MinRefreshInterval},
+		{8 * time.Hour, 2 * time.Hour},
+		{4 * 24 * time.Hour, 24 * time.Hour},
+		{90 * 24 * time.Hour, MaxRefreshInterval},
+	}
+	for _, tt := range tests {
+		if got := RefreshInterval(tt.age); got != tt.want {
+			t.Errorf("RefreshInterval(%v) = %v, want %v", tt.age, got, tt.want)
+		}
+	}
+}
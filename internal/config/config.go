@@ -3,17 +3,19 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Interests []Interest    `yaml:"interests"`
-	Scoring   ScoringConfig `yaml:"scoring"`
-	APIs      APIConfig     `yaml:"apis"`
-	Fetch     FetchConfig   `yaml:"fetch"`
-	Daemon    DaemonConfig  `yaml:"daemon"`
-	Reddit    RedditConfig  `yaml:"reddit"`
+	Interests []Interest      `yaml:"interests"`
+	Scoring   ScoringConfig   `yaml:"scoring"`
+	APIs      APIConfig       `yaml:"apis"`
+	Fetch     FetchConfig     `yaml:"fetch"`
+	Daemon    DaemonConfig    `yaml:"daemon"`
+	Reddit    RedditConfig    `yaml:"reddit"`
+	Community CommunityConfig `yaml:"community"`
 }
 
 type Interest struct {
@@ -26,12 +28,26 @@ type ScoringConfig struct {
 	Community float64 `yaml:"community"`
 	Relevance float64 `yaml:"relevance"`
 	Novelty   float64 `yaml:"novelty"`
+
+	// Final scores halve every HalfLifeDays of post age; 0 disables decay
+	HalfLifeDays float64 `yaml:"half_life_days,omitempty"`
+
+	// Share of the relevance score taken from the model learned from
+	// ratings, once it has seen likes and dislikes; 0 disables it
+	Personal float64 `yaml:"personal"`
 }
 
 type APIConfig struct {
-	LLMProvider string `yaml:"llm_provider"`
-	LLMModel    string `yaml:"llm_model"`
-	OpenAIKey   string `yaml:"openai_key,omitempty"`
+	LLMProvider    string `yaml:"llm_provider"`
+	LLMModel       string `yaml:"llm_model"`
+	LLMURL         string `yaml:"llm_url"`
+	LLMConcurrency int    `yaml:"llm_concurrency"` // parallel requests sent to the LLM endpoint
+	OpenAIKey      string `yaml:"openai_key,omitempty"`
+
+	EmbeddingProvider    string `yaml:"embedding_provider"`        // ollama or openai (any OpenAI-compatible endpoint)
+	EmbeddingModel       string `yaml:"embedding_model,omitempty"` // embeddings are disabled until set
+	EmbeddingURL         string `yaml:"embedding_url,omitempty"`   // defaults to llm_url
+	EmbeddingConcurrency int    `yaml:"embedding_concurrency"`     // parallel requests sent to the embedding endpoint
 }
 
 type FetchConfig struct {
@@ -45,7 +61,17 @@ type DaemonConfig struct {
 }
 
 type RedditConfig struct {
-	Subreddits []string `yaml:"subreddits"`
+	Subreddits []string `yaml:"subreddits"` // searched when the exact URL lookup finds nothing
+	BaseURL    string   `yaml:"base_url"`
+}
+
+// CommunityConfig selects the community signal providers. Scored posts up
+// to RefreshDays old are re-checked, less often as they age.
+type CommunityConfig struct {
+	Providers   []string `yaml:"providers"` // hn, reddit, lobsters
+	HNURL       string   `yaml:"hn_url"`
+	LobstersURL string   `yaml:"lobsters_url"`
+	RefreshDays int      `yaml:"refresh_days"`
 }
 
 func Default() *Config {
@@ -55,10 +81,16 @@ func Default() *Config {
 			Community: 0.3,
 			Relevance: 0.4,
 			Novelty:   0.3,
+			Personal:  0.5,
 		},
 		APIs: APIConfig{
-			LLMProvider: "ollama",
-			LLMModel:    "llama3.2",
+			LLMProvider:    "ollama",
+			LLMModel:       "llama3.2",
+			LLMURL:         "http://localhost:11434",
+			LLMConcurrency: 2,
+
+			EmbeddingProvider:    "ollama",
+			EmbeddingConcurrency: 2,
 		},
 		Fetch: FetchConfig{
 			Concurrency:    5,
@@ -70,6 +102,13 @@ func Default() *Config {
 		},
 		Reddit: RedditConfig{
 			Subreddits: []string{"programming", "golang"},
+			BaseURL:    "https://www.reddit.com",
+		},
+		Community: CommunityConfig{
+			Providers:   []string{"hn", "reddit", "lobsters"},
+			HNURL:       "https://hn.algolia.com",
+			LobstersURL: "https://lobste.rs",
+			RefreshDays: 30,
 		},
 	}
 }
@@ -106,6 +145,19 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// ModTime returns when config.yaml was last written, or the zero time when
+// it does not exist
+func ModTime() (time.Time, error) {
+	info, err := os.Stat(configPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
 func Save(cfg *Config) error {
 	if err := os.MkdirAll(Dir(), 0755); err != nil {
 		return err
@@ -15,6 +15,15 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.Fetch.TimeoutSeconds != 30 {
 		t.Errorf("expected timeout 30, got %d", cfg.Fetch.TimeoutSeconds)
 	}
+	if cfg.APIs.LLMConcurrency != 2 {
+		t.Errorf("expected LLM concurrency 2, got %d", cfg.APIs.LLMConcurrency)
+	}
+	if cfg.APIs.EmbeddingConcurrency != 2 {
+		t.Errorf("expected embedding concurrency 2, got %d", cfg.APIs.EmbeddingConcurrency)
+	}
+	if cfg.APIs.EmbeddingModel != "" {
+		t.Errorf("expected embeddings to be disabled by default, got model %q", cfg.APIs.EmbeddingModel)
+	}
 }
 
 func TestConfigDir(t *testing.T) {
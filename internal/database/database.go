@@ -3,17 +3,19 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 type DB struct {
 	conn *sql.DB
+	tx   *sql.Tx
 	path string
 }
 
 func New(path string) (*DB, error) {
-	conn, err := sql.Open("sqlite3", path+"?_foreign_keys=on&_fts5=true")
+	conn, err := sql.Open("sqlite3", path+"?_foreign_keys=on&_fts5=true&_busy_timeout=5000")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -23,6 +25,10 @@ func New(path string) (*DB, error) {
 		conn.Close()
 		return nil, fmt.Errorf("failed to initialize schema: %w", err)
 	}
+	if err := db.migrate(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
+	}
 
 	return db, nil
 }
@@ -32,17 +38,63 @@ func (db *DB) Close() error {
 }
 
 func (db *DB) Exec(query string, args ...any) (sql.Result, error) {
+	if db.tx != nil {
+		return db.tx.Exec(query, args...)
+	}
 	return db.conn.Exec(query, args...)
 }
 
 func (db *DB) Query(query string, args ...any) (*sql.Rows, error) {
+	if db.tx != nil {
+		return db.tx.Query(query, args...)
+	}
 	return db.conn.Query(query, args...)
 }
 
 func (db *DB) QueryRow(query string, args ...any) *sql.Row {
+	if db.tx != nil {
+		return db.tx.QueryRow(query, args...)
+	}
 	return db.conn.QueryRow(query, args...)
 }
 
+// Tx runs fn inside a transaction. Repositories created from the DB passed
+// to fn share the transaction, which is rolled back if fn returns an error.
+func (db *DB) Tx(fn func(tx *DB) error) error {
+	if db.tx != nil {
+		return fn(db)
+	}
+
+	sqlTx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(&DB{conn: db.conn, tx: sqlTx, path: db.path}); err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+	return sqlTx.Commit()
+}
+
+// timeLayouts are the formats DATETIME values come back in as text, e.g.
+// from MIN and MAX, which the driver doesn't convert to time.Time
+var timeLayouts = []string{"2006-01-02 15:04:05.999999999-07:00", "2006-01-02T15:04:05Z07:00", "2006-01-02 15:04:05"}
+
+// ParseTime reads a DATETIME returned as text, or returns nil when it is NULL
+// or in no known format
+func ParseTime(s sql.NullString) *time.Time {
+	if !s.Valid {
+		return nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s.String); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
 func (db *DB) initSchema() error {
 	schema := `
 	CREATE TABLE IF NOT EXISTS sources (
@@ -66,7 +118,17 @@ func (db *DB) initSchema() error {
 		fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		content_raw TEXT,
 		content_clean TEXT,
-		word_count INTEGER
+		word_count INTEGER,
+		reading_minutes REAL,
+		code_ratio REAL,
+		headings INTEGER,
+		readability REAL,
+		summary TEXT,
+		summary_hash TEXT,
+		extracted_at DATETIME,
+		extract_model TEXT,
+		extract_prompt_version TEXT,
+		extract_hash TEXT
 	);
 
 	CREATE TABLE IF NOT EXISTS insights (
@@ -74,6 +136,7 @@ func (db *DB) initSchema() error {
 		post_id INTEGER NOT NULL REFERENCES posts(id),
 		type TEXT NOT NULL,
 		content TEXT NOT NULL,
+		detail TEXT,
 		importance INTEGER
 	);
 
@@ -83,7 +146,8 @@ func (db *DB) initSchema() error {
 		url TEXT NOT NULL,
 		title TEXT,
 		context TEXT,
-		is_blog BOOLEAN DEFAULT FALSE
+		is_blog BOOLEAN DEFAULT FALSE,
+		origin TEXT DEFAULT 'llm'
 	);
 
 	CREATE TABLE IF NOT EXISTS scores (
@@ -108,12 +172,151 @@ func (db *DB) initSchema() error {
 		id INTEGER PRIMARY KEY,
 		topic TEXT NOT NULL UNIQUE,
 		weight REAL DEFAULT 1.0,
-		keywords TEXT
+		keywords TEXT,
+		updated_at DATETIME
+	);
+
+	CREATE TABLE IF NOT EXISTS post_topics (
+		post_id INTEGER NOT NULL REFERENCES posts(id),
+		topic TEXT NOT NULL,
+		PRIMARY KEY (post_id, topic)
+	);
+
+	CREATE TABLE IF NOT EXISTS snippets (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		post_id INTEGER NOT NULL REFERENCES posts(id),
+		language TEXT,
+		caption TEXT,
+		code TEXT NOT NULL,
+		position INTEGER DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS embeddings (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		owner_id INTEGER NOT NULL,
+		chunk INTEGER NOT NULL DEFAULT 0,
+		text TEXT,
+		model TEXT NOT NULL,
+		dim INTEGER NOT NULL,
+		content_hash TEXT,
+		vector BLOB NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(kind, owner_id, chunk)
+	);
+
+	CREATE TABLE IF NOT EXISTS llm_calls (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		stage TEXT NOT NULL,
+		model TEXT NOT NULL,
+		prompt_tokens INTEGER DEFAULT 0,
+		response_tokens INTEGER DEFAULT 0,
+		latency_ms INTEGER NOT NULL,
+		success BOOLEAN NOT NULL,
+		error_class TEXT,
+		error TEXT,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS jobs (
+		post_id INTEGER NOT NULL REFERENCES posts(id),
+		stage TEXT NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER DEFAULT 0,
+		last_error TEXT,
+		next_retry_at DATETIME,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (post_id, stage)
+	);
+
+	CREATE TABLE IF NOT EXISTS entities (
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		type TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS entity_aliases (
+		key TEXT PRIMARY KEY,
+		entity_id INTEGER NOT NULL REFERENCES entities(id),
+		alias TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS post_entities (
+		post_id INTEGER NOT NULL REFERENCES posts(id),
+		entity_id INTEGER NOT NULL REFERENCES entities(id),
+		mentions INTEGER DEFAULT 0,
+		context TEXT,
+		PRIMARY KEY (post_id, entity_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS signals (
+		id INTEGER PRIMARY KEY,
+		post_id INTEGER NOT NULL REFERENCES posts(id),
+		provider TEXT NOT NULL,
+		points INTEGER DEFAULT 0,
+		comments INTEGER DEFAULT 0,
+		submissions INTEGER DEFAULT 0,
+		checked_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS cards (
+		id INTEGER PRIMARY KEY,
+		post_id INTEGER NOT NULL REFERENCES posts(id),
+		insight_id INTEGER,
+		kind TEXT NOT NULL,
+		question TEXT NOT NULL,
+		answer TEXT NOT NULL,
+		source_hash TEXT NOT NULL UNIQUE,
+		ease REAL DEFAULT 2.5,
+		interval_days INTEGER DEFAULT 0,
+		repetitions INTEGER DEFAULT 0,
+		lapses INTEGER DEFAULT 0,
+		due_at DATETIME NOT NULL,
+		last_reviewed_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS ratings (
+		post_id INTEGER PRIMARY KEY REFERENCES posts(id),
+		rating INTEGER NOT NULL,
+		rated_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS models (
+		name TEXT PRIMARY KEY,
+		data TEXT NOT NULL,
+		examples INTEGER DEFAULT 0,
+		accuracy REAL DEFAULT 0,
+		trained_at DATETIME NOT NULL
+	);
+
+	-- Novelty index: the term frequencies of each post as a JSON object, and
+	-- the number of indexed posts having each term
+	CREATE TABLE IF NOT EXISTS novelty_docs (
+		post_id INTEGER PRIMARY KEY REFERENCES posts(id),
+		terms TEXT NOT NULL,
+		indexed_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS novelty_terms (
+		term TEXT PRIMARY KEY,
+		doc_freq INTEGER NOT NULL
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_posts_source ON posts(source_id);
 	CREATE INDEX IF NOT EXISTS idx_posts_published ON posts(published_at);
 	CREATE INDEX IF NOT EXISTS idx_scores_final ON scores(final_score DESC);
+	CREATE INDEX IF NOT EXISTS idx_post_topics_topic ON post_topics(topic);
+	CREATE INDEX IF NOT EXISTS idx_snippets_post ON snippets(post_id);
+	CREATE INDEX IF NOT EXISTS idx_snippets_language ON snippets(language);
+	CREATE INDEX IF NOT EXISTS idx_embeddings_model ON embeddings(kind, model, dim);
+	CREATE INDEX IF NOT EXISTS idx_llm_calls_created ON llm_calls(created_at);
+	CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(stage, status);
+	CREATE INDEX IF NOT EXISTS idx_entity_aliases_entity ON entity_aliases(entity_id);
+	CREATE INDEX IF NOT EXISTS idx_post_entities_entity ON post_entities(entity_id);
+	CREATE INDEX IF NOT EXISTS idx_cards_due ON cards(due_at);
+	CREATE INDEX IF NOT EXISTS idx_signals_post ON signals(post_id, provider, checked_at);
 
 	CREATE VIRTUAL TABLE IF NOT EXISTS posts_fts USING fts5(
 		title,
@@ -124,16 +327,111 @@ func (db *DB) initSchema() error {
 		INSERT INTO posts_fts(rowid, title, content) VALUES (new.id, new.title, COALESCE(new.content_clean, new.content_raw, ''));
 	END;
 
-	CREATE TRIGGER IF NOT EXISTS posts_ad AFTER DELETE ON posts BEGIN
-		INSERT INTO posts_fts(posts_fts, rowid, title, content) VALUES('delete', old.id, old.title, COALESCE(old.content_clean, old.content_raw, ''));
+	-- posts_fts stores its own content, so rows are removed with DELETE rather
+	-- than the 'delete' command reserved for external-content tables
+	DROP TRIGGER IF EXISTS posts_ad;
+	CREATE TRIGGER posts_ad AFTER DELETE ON posts BEGIN
+		DELETE FROM posts_fts WHERE rowid = old.id;
 	END;
 
-	CREATE TRIGGER IF NOT EXISTS posts_au AFTER UPDATE ON posts BEGIN
-		INSERT INTO posts_fts(posts_fts, rowid, title, content) VALUES('delete', old.id, old.title, COALESCE(old.content_clean, old.content_raw, ''));
+	DROP TRIGGER IF EXISTS posts_au;
+	CREATE TRIGGER posts_au AFTER UPDATE ON posts BEGIN
+		DELETE FROM posts_fts WHERE rowid = old.id;
 		INSERT INTO posts_fts(rowid, title, content) VALUES (new.id, new.title, COALESCE(new.content_clean, new.content_raw, ''));
 	END;
+
+	-- A post whose text changed is dropped from the novelty index, to be
+	-- indexed again with its new terms
+	CREATE TRIGGER IF NOT EXISTS posts_novelty_au AFTER UPDATE OF title, content_clean ON posts
+	WHEN old.title IS NOT new.title OR old.content_clean IS NOT new.content_clean BEGIN
+		DELETE FROM novelty_docs WHERE post_id = old.id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS novelty_docs_ad AFTER DELETE ON novelty_docs BEGIN
+		UPDATE novelty_terms SET doc_freq = doc_freq - 1
+		WHERE term IN (SELECT key FROM json_each(old.terms));
+	END;
+
+	-- Trigram tokens let identifiers match inside longer names, e.g. a search
+	-- for "Timeout" finds context.WithTimeout
+	CREATE VIRTUAL TABLE IF NOT EXISTS snippets_fts USING fts5(
+		code,
+		caption,
+		tokenize = 'trigram'
+	);
+
+	CREATE TRIGGER IF NOT EXISTS snippets_ai AFTER INSERT ON snippets BEGIN
+		INSERT INTO snippets_fts(rowid, code, caption) VALUES (new.id, new.code, COALESCE(new.caption, ''));
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS snippets_ad AFTER DELETE ON snippets BEGIN
+		DELETE FROM snippets_fts WHERE rowid = old.id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS snippets_au AFTER UPDATE ON snippets BEGIN
+		DELETE FROM snippets_fts WHERE rowid = old.id;
+		INSERT INTO snippets_fts(rowid, code, caption) VALUES (new.id, new.code, COALESCE(new.caption, ''));
+	END;
 	`
 
 	_, err := db.conn.Exec(schema)
 	return err
 }
+
+// Columns added after the initial schema, applied to existing databases
+var columnMigrations = []struct {
+	table      string
+	column     string
+	definition string
+}{
+	{"insights", "detail", "TEXT"},
+	{"posts", "summary", "TEXT"},
+	{"posts", "summary_hash", "TEXT"},
+	{"posts", "extracted_at", "DATETIME"},
+	{"posts", "extract_model", "TEXT"},
+	{"posts", "extract_prompt_version", "TEXT"},
+	{"posts", "extract_hash", "TEXT"},
+	{"refs", "origin", "TEXT DEFAULT 'llm'"},
+	{"interests", "updated_at", "DATETIME"},
+	{"posts", "reading_minutes", "REAL"},
+	{"posts", "code_ratio", "REAL"},
+	{"posts", "headings", "INTEGER"},
+	{"posts", "readability", "REAL"},
+}
+
+func (db *DB) migrate() error {
+	for _, m := range columnMigrations {
+		exists, err := db.hasColumn(m.table, m.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := db.conn.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", m.table, m.column, m.definition)); err != nil {
+			return fmt.Errorf("failed to add %s.%s: %w", m.table, m.column, err)
+		}
+	}
+	return nil
+}
+
+func (db *DB) hasColumn(table, column string) (bool, error) {
+	rows, err := db.conn.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
@@ -2,6 +2,8 @@
 package database
 
 import (
+	"database/sql"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -33,7 +35,7 @@ func TestInitSchema(t *testing.T) {
 	defer db.Close()
 
 	// Verify tables exist by querying them
-	tables := []string{"sources", "posts", "insights", "refs", "scores", "links", "interests"}
+	tables := []string{"sources", "posts", "insights", "refs", "scores", "links", "interests", "post_topics", "snippets", "embeddings", "llm_calls", "jobs", "entities", "entity_aliases", "post_entities", "signals", "cards", "ratings", "models", "novelty_docs", "novelty_terms"}
 	for _, table := range tables {
 		rows, err := db.conn.Query("SELECT 1 FROM " + table + " LIMIT 1")
 		if err != nil {
@@ -43,3 +45,86 @@ func TestInitSchema(t *testing.T) {
 		rows.Close()
 	}
 }
+
+func TestMigrateAddsColumns(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+
+	db, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+
+	// Simulate a database created before the column existed
+	if _, err := db.conn.Exec("ALTER TABLE insights DROP COLUMN detail"); err != nil {
+		t.Fatalf("failed to drop column: %v", err)
+	}
+	db.Close()
+
+	db, err = New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	exists, err := db.hasColumn("insights", "detail")
+	if err != nil {
+		t.Fatalf("failed to inspect columns: %v", err)
+	}
+	if !exists {
+		t.Error("expected migration to add insights.detail")
+	}
+}
+
+func TestTxRollback(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := New(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	err = db.Tx(func(tx *DB) error {
+		if _, err := tx.Exec(`INSERT INTO interests (topic) VALUES ('golang')`); err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+	if err == nil {
+		t.Fatal("expected error from transaction")
+	}
+
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM interests`).Scan(&count)
+	if count != 0 {
+		t.Errorf("expected rollback to discard insert, got %d rows", count)
+	}
+
+	err = db.Tx(func(tx *DB) error {
+		_, err := tx.Exec(`INSERT INTO interests (topic) VALUES ('golang')`)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to commit transaction: %v", err)
+	}
+
+	db.QueryRow(`SELECT COUNT(*) FROM interests`).Scan(&count)
+	if count != 1 {
+		t.Errorf("expected 1 row after commit, got %d", count)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	for _, s := range []string{"2024-03-01 10:30:00.5+00:00", "2024-03-01T10:30:00Z", "2024-03-01 10:30:00"} {
+		got := ParseTime(sql.NullString{String: s, Valid: true})
+		if got == nil || got.Year() != 2024 || got.Minute() != 30 {
+			t.Errorf("failed to parse %q: %v", s, got)
+		}
+	}
+	if ParseTime(sql.NullString{}) != nil {
+		t.Error("expected nil for NULL")
+	}
+	if ParseTime(sql.NullString{String: "yesterday", Valid: true}) != nil {
+		t.Error("expected nil for an unknown format")
+	}
+}
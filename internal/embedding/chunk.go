@@ -0,0 +1,54 @@
+package embedding
+
+import (
+	"strings"
+)
+
+// ChunkWords is the target chunk size, small enough for the context window
+// of common local embedding models
+const ChunkWords = 200
+
+// Chunk splits Markdown text into pieces of roughly maxWords words along
+// paragraph boundaries. Paragraphs longer than maxWords are split by words.
+func Chunk(text string, maxWords int) []string {
+	var chunks []string
+	var current []string
+	words := 0
+
+	flush := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, strings.Join(current, "\n\n"))
+			current = nil
+			words = 0
+		}
+	}
+
+	for _, para := range strings.Split(text, "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+
+		fields := strings.Fields(para)
+		if len(fields) > maxWords {
+			flush()
+			for start := 0; start < len(fields); start += maxWords {
+				end := start + maxWords
+				if end > len(fields) {
+					end = len(fields)
+				}
+				chunks = append(chunks, strings.Join(fields[start:end], " "))
+			}
+			continue
+		}
+
+		if words+len(fields) > maxWords {
+			flush()
+		}
+		current = append(current, para)
+		words += len(fields)
+	}
+	flush()
+
+	return chunks
+}
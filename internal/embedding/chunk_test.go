@@ -0,0 +1,30 @@
+package embedding
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChunk(t *testing.T) {
+	text := "one two three\n\nfour five\n\nsix seven eight nine"
+
+	chunks := Chunk(text, 5)
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d: %q", len(chunks), chunks)
+	}
+	if chunks[0] != "one two three\n\nfour five" {
+		t.Errorf("expected paragraphs grouped, got %q", chunks[0])
+	}
+}
+
+func TestChunkSplitsLongParagraphs(t *testing.T) {
+	text := strings.Repeat("word ", 12)
+
+	chunks := Chunk(text, 5)
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(chunks))
+	}
+	if len(strings.Fields(chunks[2])) != 2 {
+		t.Errorf("expected 2 words in the last chunk, got %q", chunks[2])
+	}
+}
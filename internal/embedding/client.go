@@ -0,0 +1,156 @@
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Supported embedding providers
+const (
+	ProviderOllama = "ollama"
+	ProviderOpenAI = "openai" // any OpenAI-compatible /embeddings endpoint
+)
+
+// ErrUnavailable is wrapped by errors that every later request would hit
+// too: the endpoint can't be reached, or it doesn't serve the model
+var ErrUnavailable = errors.New("embedding endpoint unavailable")
+
+// Embedder turns texts into vectors
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	Model() string
+}
+
+// Client calls an Ollama or OpenAI-compatible embeddings endpoint
+type Client struct {
+	provider   string
+	baseURL    string
+	model      string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the given provider. For OpenAI-compatible
+// endpoints baseURL includes the API version, e.g. https://api.openai.com/v1.
+func NewClient(provider, baseURL, model, apiKey string, timeout time.Duration) (*Client, error) {
+	switch provider {
+	case ProviderOllama, ProviderOpenAI:
+	default:
+		return nil, fmt.Errorf("unknown embedding provider: %s (expected %s or %s)", provider, ProviderOllama, ProviderOpenAI)
+	}
+
+	return &Client{
+		provider: provider,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		model:    model,
+		apiKey:   apiKey,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}, nil
+}
+
+func (c *Client) Model() string {
+	return c.model
+}
+
+type embedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type ollamaResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+type openAIResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed returns one vector per text, in order
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	path := "/api/embed"
+	if c.provider == ProviderOpenAI {
+		path = "/embeddings"
+	}
+
+	body, err := c.post(ctx, path, embedRequest{Model: c.model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+
+	var vectors [][]float32
+	if c.provider == ProviderOpenAI {
+		var result openAIResponse
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		sort.Slice(result.Data, func(i, j int) bool { return result.Data[i].Index < result.Data[j].Index })
+		for _, d := range result.Data {
+			vectors = append(vectors, d.Embedding)
+		}
+	} else {
+		var result ollamaResponse
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		vectors = result.Embeddings
+	}
+
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(vectors))
+	}
+	return vectors, nil
+}
+
+func (c *Client) post(ctx context.Context, path string, payload any) ([]byte, error) {
+	jsonBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: model %s not found: %s", ErrUnavailable, c.model, strings.TrimSpace(string(body)))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embedding API error %d: %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
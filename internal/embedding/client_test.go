@@ -0,0 +1,85 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEmbedOllama(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		var req embedRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "nomic-embed-text" || len(req.Input) != 2 {
+			t.Errorf("unexpected request: %+v", req)
+		}
+		w.Write([]byte(`{"embeddings": [[1, 0], [0, 1]]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ProviderOllama, server.URL, "nomic-embed-text", "", time.Second)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	vectors, err := client.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("embed failed: %v", err)
+	}
+	if len(vectors) != 2 || vectors[1][1] != 1 {
+		t.Errorf("unexpected vectors: %v", vectors)
+	}
+}
+
+func TestEmbedOpenAICompatible(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("missing API key")
+		}
+		// Out of order on purpose: results are sorted by index
+		w.Write([]byte(`{"data": [{"index": 1, "embedding": [0, 1]}, {"index": 0, "embedding": [1, 0]}]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ProviderOpenAI, server.URL+"/v1/", "text-embedding-3-small", "secret", time.Second)
+
+	vectors, err := client.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("embed failed: %v", err)
+	}
+	if vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("expected vectors in input order, got %v", vectors)
+	}
+}
+
+func TestEmbedUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"model \"missing\" not found, try pulling it first"}`, http.StatusNotFound)
+	}))
+	client, _ := NewClient(ProviderOllama, server.URL, "missing", "", time.Second)
+
+	if _, err := client.Embed(context.Background(), []string{"a"}); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected unavailable error for unknown model, got %v", err)
+	}
+
+	server.Close()
+	if _, err := client.Embed(context.Background(), []string{"a"}); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected unavailable error for unreachable endpoint, got %v", err)
+	}
+}
+
+func TestNewClientUnknownProvider(t *testing.T) {
+	if _, err := NewClient("cohere", "http://localhost", "m", "", time.Second); err == nil {
+		t.Error("expected error for unknown provider")
+	}
+}
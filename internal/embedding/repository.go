@@ -0,0 +1,221 @@
+package embedding
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+)
+
+// Kinds of embedded objects
+const (
+	KindPost    = "post"
+	KindInsight = "insight"
+)
+
+// Fingerprint identifies what an object was embedded from
+type Fingerprint struct {
+	Model       string
+	ContentHash string
+}
+
+// Match is a nearest-neighbour result: the best matching chunk of an owner
+type Match struct {
+	Kind       string
+	OwnerID    int64
+	Chunk      int
+	Text       string
+	Similarity float64 // cosine similarity
+}
+
+type Repository struct {
+	db *database.DB
+}
+
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Replace stores the chunk vectors of an object, replacing earlier ones
+// whatever model produced them
+func (r *Repository) Replace(kind string, ownerID int64, model, contentHash string, texts []string, vectors [][]float32) error {
+	if len(texts) != len(vectors) {
+		return fmt.Errorf("got %d texts for %d vectors", len(texts), len(vectors))
+	}
+
+	return r.db.Tx(func(tx *database.DB) error {
+		if _, err := tx.Exec(`DELETE FROM embeddings WHERE kind = ? AND owner_id = ?`, kind, ownerID); err != nil {
+			return err
+		}
+		for i, v := range vectors {
+			if _, err := tx.Exec(`
+				INSERT INTO embeddings (kind, owner_id, chunk, text, model, dim, content_hash, vector)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			`, kind, ownerID, i, texts[i], model, len(v), contentHash, encodeVector(normalize(v))); err != nil {
+				return fmt.Errorf("failed to insert embedding: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Fingerprints returns the model and content hash each object of a kind was
+// embedded with, keyed by owner ID, to find objects needing re-embedding
+func (r *Repository) Fingerprints(kind string) (map[int64]Fingerprint, error) {
+	rows, err := r.db.Query(`
+		SELECT owner_id, model, content_hash FROM embeddings WHERE kind = ? AND chunk = 0
+	`, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prints := make(map[int64]Fingerprint)
+	for rows.Next() {
+		var id int64
+		var fp Fingerprint
+		if err := rows.Scan(&id, &fp.Model, &fp.ContentHash); err != nil {
+			return nil, err
+		}
+		prints[id] = fp
+	}
+	return prints, rows.Err()
+}
+
+// DeleteOrphans removes vectors of posts and insights that no longer exist
+func (r *Repository) DeleteOrphans() (int64, error) {
+	result, err := r.db.Exec(`
+		DELETE FROM embeddings
+		WHERE (kind = 'post' AND owner_id NOT IN (SELECT id FROM posts))
+		   OR (kind = 'insight' AND owner_id NOT IN (SELECT id FROM insights))
+	`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Count returns the number of embedded objects per kind for a model
+func (r *Repository) Count(model string) (map[string]int, error) {
+	rows, err := r.db.Query(`
+		SELECT kind, COUNT(DISTINCT owner_id) FROM embeddings WHERE model = ? GROUP BY kind
+	`, model)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var kind string
+		var n int
+		if err := rows.Scan(&kind, &n); err != nil {
+			return nil, err
+		}
+		counts[kind] = n
+	}
+	return counts, rows.Err()
+}
+
+// Nearest returns the k objects of a kind closest to the query vector, each
+// with its best matching chunk. Only vectors from the same model and
+// dimension are compared. The scan is exhaustive, which is fast enough for
+// a personal library of a few thousand posts.
+func (r *Repository) Nearest(query []float32, model, kind string, k int) ([]Match, error) {
+	rows, err := r.db.Query(`
+		SELECT owner_id, chunk, text, vector FROM embeddings
+		WHERE kind = ? AND model = ? AND dim = ?
+	`, kind, model, len(query))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	q := normalize(query)
+	best := make(map[int64]Match)
+	for rows.Next() {
+		var m Match
+		var blob []byte
+		if err := rows.Scan(&m.OwnerID, &m.Chunk, &m.Text, &blob); err != nil {
+			return nil, err
+		}
+		m.Kind = kind
+		m.Similarity = dot(q, decodeVector(blob))
+		if prev, ok := best[m.OwnerID]; !ok || m.Similarity > prev.Similarity {
+			best[m.OwnerID] = m
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	matches := make([]Match, 0, len(best))
+	for _, m := range best {
+		matches = append(matches, m)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Similarity != matches[j].Similarity {
+			return matches[i].Similarity > matches[j].Similarity
+		}
+		return matches[i].OwnerID < matches[j].OwnerID
+	})
+	if k > 0 && len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches, nil
+}
+
+// Cosine returns the cosine similarity of two vectors of equal length
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	return dot(normalize(a), normalize(b))
+}
+
+func dot(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+func normalize(v []float32) []float32 {
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	if norm == 0 {
+		return v
+	}
+	norm = math.Sqrt(norm)
+
+	out := make([]float32, len(v))
+	for i, x := range v {
+		out[i] = float32(float64(x) / norm)
+	}
+	return out
+}
+
+// Vectors are stored as little-endian float32 arrays
+func encodeVector(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(x))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) []float32 {
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v
+}
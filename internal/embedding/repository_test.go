@@ -0,0 +1,104 @@
+package embedding
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/julienpequegnot/blogmon/internal/source"
+)
+
+func setupTestDB(t *testing.T) (*database.DB, []int64) {
+	tmpDir := t.TempDir()
+	db, err := database.New(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+
+	srcRepo := source.NewRepository(db)
+	src, _ := srcRepo.Add("https://test.com", "Test", "")
+
+	postRepo := post.NewRepository(db)
+	var ids []int64
+	for _, url := range []string{"https://test.com/p1", "https://test.com/p2", "https://test.com/p3"} {
+		p, _ := postRepo.Add(src.ID, url, "Post", "Author", time.Now(), "content")
+		ids = append(ids, p.ID)
+	}
+
+	return db, ids
+}
+
+func TestNearest(t *testing.T) {
+	db, ids := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	repo.Replace(KindPost, ids[0], "m", "h1", []string{"latency", "pools"}, [][]float32{{1, 0, 0}, {0, 1, 0}})
+	repo.Replace(KindPost, ids[1], "m", "h2", []string{"queues"}, [][]float32{{0.7, 0.7, 0}})
+	repo.Replace(KindPost, ids[2], "other-model", "h3", []string{"ignored"}, [][]float32{{1, 0, 0}})
+
+	matches, err := repo.Nearest([]float32{2, 0, 0}, "m", KindPost, 10)
+	if err != nil {
+		t.Fatalf("nearest failed: %v", err)
+	}
+
+	if len(matches) != 2 {
+		t.Fatalf("expected one match per post of the model, got %d", len(matches))
+	}
+	if matches[0].OwnerID != ids[0] || matches[0].Text != "latency" {
+		t.Errorf("expected best chunk of first post, got %+v", matches[0])
+	}
+	if matches[0].Similarity < 0.99 || matches[1].Similarity > matches[0].Similarity {
+		t.Errorf("unexpected similarities: %+v", matches)
+	}
+}
+
+func TestReplaceAndFingerprints(t *testing.T) {
+	db, ids := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	repo.Replace(KindPost, ids[0], "m1", "old", []string{"a", "b"}, [][]float32{{1, 0}, {0, 1}})
+	if err := repo.Replace(KindPost, ids[0], "m2", "new", []string{"a"}, [][]float32{{1, 1}}); err != nil {
+		t.Fatalf("replace failed: %v", err)
+	}
+
+	prints, _ := repo.Fingerprints(KindPost)
+	if prints[ids[0]] != (Fingerprint{Model: "m2", ContentHash: "new"}) {
+		t.Errorf("unexpected fingerprint: %+v", prints[ids[0]])
+	}
+
+	counts, _ := repo.Count("m1")
+	if counts[KindPost] != 0 {
+		t.Errorf("expected vectors of the old model to be replaced, got %d", counts[KindPost])
+	}
+}
+
+func TestDeleteOrphans(t *testing.T) {
+	db, ids := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	repo.Replace(KindInsight, 999, "m", "h", []string{"gone"}, [][]float32{{1}})
+	repo.Replace(KindPost, ids[0], "m", "h", []string{"kept"}, [][]float32{{1}})
+
+	n, err := repo.DeleteOrphans()
+	if err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 orphan removed, got %d", n)
+	}
+}
+
+func TestVectorRoundTrip(t *testing.T) {
+	v := []float32{0.25, -1.5, 3}
+	got := decodeVector(encodeVector(v))
+	for i := range v {
+		if got[i] != v[i] {
+			t.Fatalf("expected %v, got %v", v, got)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package entity
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/julienpequegnot/blogmon/internal/markdown"
+)
+
+// Entity types
+const (
+	TypePerson  = "person"
+	TypeProject = "project"
+	TypeLibrary = "library"
+	TypeCompany = "company"
+)
+
+// Types lists every entity type in display order
+var Types = []string{TypePerson, TypeProject, TypeLibrary, TypeCompany}
+
+// Other names models use for the entity types
+var typeAliases = map[string]string{
+	"people":       TypePerson,
+	"author":       TypePerson,
+	"product":      TypeProject,
+	"tool":         TypeProject,
+	"database":     TypeProject,
+	"language":     TypeProject,
+	"framework":    TypeLibrary,
+	"package":      TypeLibrary,
+	"crate":        TypeLibrary,
+	"module":       TypeLibrary,
+	"org":          TypeCompany,
+	"organization": TypeCompany,
+	"organisation": TypeCompany,
+	"vendor":       TypeCompany,
+}
+
+const maxContextLen = 300
+
+// Mention is an entity found in a post
+type Mention struct {
+	Name    string
+	Type    string
+	Aliases []string
+	Count   int    // occurrences of the name or an alias in the post
+	Context string // sentence of the first occurrence
+}
+
+// Names returns the name followed by the aliases of the mention
+func (m Mention) Names() []string {
+	return append([]string{m.Name}, m.Aliases...)
+}
+
+// NormalizeType maps a type reported by the LLM to one of Types, or returns
+// "" when it matches none
+func NormalizeType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	for _, known := range Types {
+		if t == known {
+			return t
+		}
+	}
+	return typeAliases[t]
+}
+
+// Key identifies a name regardless of case, spacing and punctuation, so
+// "Node.js", "NodeJS" and "node js" are the same entity. '+' and '#' are kept
+// to tell C, C++ and C# apart.
+func Key(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Locate counts the whole-word, case-insensitive occurrences of the names in
+// content and returns the sentence around the first one
+func Locate(content string, names []string) (int, string) {
+	count, first, firstLen := 0, -1, 0
+
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+
+		for _, loc := range markdown.FindWords(content, name) {
+			count++
+			if first < 0 || loc[0] < first {
+				first, firstLen = loc[0], loc[1]-loc[0]
+			}
+		}
+	}
+
+	if first < 0 {
+		return 0, ""
+	}
+	return count, sentenceAt(content, first, firstLen)
+}
+
+// Contains reports whether any of the names occurs as a whole word in text
+func Contains(text string, names []string) bool {
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name != "" && len(markdown.FindWords(text, name)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// sentenceAt returns the sentence containing text[i:i+n] on a single line
+func sentenceAt(text string, i, n int) string {
+	start := 0
+	for _, sep := range []string{". ", "! ", "? ", "\n"} {
+		if j := strings.LastIndex(text[:i], sep); j >= 0 && j+len(sep) > start {
+			start = j + len(sep)
+		}
+	}
+
+	end := len(text)
+	for _, sep := range []string{". ", "! ", "? ", "\n"} {
+		if j := strings.Index(text[i+n:], sep); j >= 0 && i+n+j+1 < end {
+			end = i + n + j + 1
+		}
+	}
+
+	sentence := strings.Join(strings.Fields(text[start:end]), " ")
+	if len(sentence) > maxContextLen {
+		cut := strings.LastIndex(sentence[:maxContextLen-3], " ")
+		if cut <= 0 {
+			cut = len(markdown.TruncateBytes(sentence, maxContextLen-3))
+		}
+		sentence = sentence[:cut] + "..."
+	}
+	return sentence
+}
@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"Node.js", "nodejs", true},
+		{"ClickHouse", "Click House", true},
+		{"C++", "C#", false},
+		{"C", "C++", false},
+	}
+	for _, tt := range tests {
+		if got := Key(tt.a) == Key(tt.b); got != tt.same {
+			t.Errorf("Key(%q) == Key(%q) is %v, want %v", tt.a, tt.b, got, tt.same)
+		}
+	}
+}
+
+func TestNormalizeType(t *testing.T) {
+	tests := map[string]string{
+		"Person":       TypePerson,
+		"organization": TypeCompany,
+		"framework":    TypeLibrary,
+		"concept":      "",
+	}
+	for in, want := range tests {
+		if got := NormalizeType(in); got != want {
+			t.Errorf("NormalizeType(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLocate(t *testing.T) {
+	content := "We moved analytics off Postgres. ClickHouse ingests our events now.\n\n" +
+		"Compared to PostgreSQL, clickhouse compresses columns. Clickhousex is unrelated."
+
+	count, context := Locate(content, []string{"ClickHouse"})
+	if count != 2 {
+		t.Errorf("expected 2 whole-word mentions, got %d", count)
+	}
+	if context != "ClickHouse ingests our events now." {
+		t.Errorf("unexpected context: %q", context)
+	}
+
+	count, context = Locate(content, []string{"PostgreSQL", "Postgres"})
+	if count != 2 || context != "We moved analytics off Postgres." {
+		t.Errorf("expected 2 mentions starting in the first sentence, got %d, %q", count, context)
+	}
+
+	if count, _ := Locate("We tuned PostgreSQL’s planner—again.", []string{"PostgreSQL"}); count != 1 {
+		t.Errorf("expected punctuation next to a name not to block the match, got %d", count)
+	}
+
+	if count, _ := Locate(content, []string{"Redis"}); count != 0 {
+		t.Errorf("expected no mention of Redis, got %d", count)
+	}
+}
+
+func TestLocateMultibyteCase(t *testing.T) {
+	// "Ⱥ" takes 2 bytes and its lowercase "ⱥ" 3, so offsets in lowercased
+	// content would not match the original
+	content := strings.Repeat("Ⱥ", 20) + " then Go. Go is fast."
+
+	count, context := Locate(content, []string{"go"})
+	if count != 2 {
+		t.Errorf("expected 2 mentions, got %d", count)
+	}
+	if !strings.HasSuffix(context, "then Go.") {
+		t.Errorf("unexpected context: %q", context)
+	}
+
+	long := strings.Repeat("é", 200) + " Go"
+	_, context = Locate(long, []string{"go"})
+	if !utf8.ValidString(context) || len(context) > maxContextLen {
+		t.Errorf("expected context cut on a rune boundary, got %q", context)
+	}
+}
@@ -0,0 +1,247 @@
+package entity
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/insight"
+)
+
+type Entity struct {
+	ID      int64
+	Name    string
+	Type    string
+	Aliases []string
+}
+
+// Summary is an entity with the posts mentioning it
+type Summary struct {
+	Entity
+	Posts     int
+	Mentions  int
+	FirstSeen *time.Time
+	LastSeen  *time.Time
+}
+
+// PostMention is a post mentioning an entity
+type PostMention struct {
+	PostID      int64
+	Title       string
+	URL         string
+	PublishedAt *time.Time
+	Mentions    int
+	Context     string
+}
+
+// InsightMention is an insight about an entity
+type InsightMention struct {
+	insight.Insight
+	PostTitle string
+}
+
+type Repository struct {
+	db *database.DB
+}
+
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// ReplaceForPost replaces the entities mentioned by a post. Mentions are
+// matched to existing entities by the key of their name or any alias, and
+// new aliases are added to the entity.
+func (r *Repository) ReplaceForPost(postID int64, mentions []Mention) error {
+	return r.db.Tx(func(tx *database.DB) error {
+		if _, err := tx.Exec(`DELETE FROM post_entities WHERE post_id = ?`, postID); err != nil {
+			return err
+		}
+
+		repo := NewRepository(tx)
+		for _, m := range mentions {
+			id, err := repo.resolve(m)
+			if err != nil {
+				return err
+			}
+			if id == 0 {
+				continue
+			}
+
+			// Several mentions may resolve to one entity, e.g. "Postgres" and "PostgreSQL"
+			if _, err := tx.Exec(`
+				INSERT INTO post_entities (post_id, entity_id, mentions, context) VALUES (?, ?, ?, ?)
+				ON CONFLICT(post_id, entity_id) DO UPDATE SET
+					mentions = mentions + excluded.mentions,
+					context = CASE WHEN context = '' THEN excluded.context ELSE context END
+			`, postID, id, m.Count, m.Context); err != nil {
+				return fmt.Errorf("failed to insert post entity: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// resolve returns the ID of the entity for a mention, creating it when none
+// of its names is known, or 0 when the mention has no usable name
+func (r *Repository) resolve(m Mention) (int64, error) {
+	var keys []string
+	aliases := make(map[string]string)
+	for _, name := range m.Names() {
+		name = strings.TrimSpace(name)
+		if k := Key(name); k != "" && aliases[k] == "" {
+			keys = append(keys, k)
+			aliases[k] = name
+		}
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	// The name decides over aliases when they point to different entities
+	var id int64
+	for _, k := range keys {
+		err := r.db.QueryRow(`SELECT entity_id FROM entity_aliases WHERE key = ?`, k).Scan(&id)
+		if err == nil {
+			break
+		}
+		if err != sql.ErrNoRows {
+			return 0, err
+		}
+	}
+
+	if id == 0 {
+		result, err := r.db.Exec(`INSERT INTO entities (name, type) VALUES (?, ?)`, aliases[keys[0]], m.Type)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert entity: %w", err)
+		}
+		if id, err = result.LastInsertId(); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, k := range keys {
+		if _, err := r.db.Exec(
+			`INSERT OR IGNORE INTO entity_aliases (key, entity_id, alias) VALUES (?, ?, ?)`,
+			k, id, aliases[k],
+		); err != nil {
+			return 0, fmt.Errorf("failed to insert alias: %w", err)
+		}
+	}
+	return id, nil
+}
+
+// Top returns the entities mentioned by the most posts. An empty type
+// matches every type.
+func (r *Repository) Top(entityType string, limit int) ([]Summary, error) {
+	rows, err := r.db.Query(`
+		SELECT e.id, e.name, e.type, COUNT(*), COALESCE(SUM(pe.mentions), 0), MIN(p.published_at), MAX(p.published_at)
+		FROM entities e
+		JOIN post_entities pe ON pe.entity_id = e.id
+		JOIN posts p ON pe.post_id = p.id
+		WHERE ? = '' OR e.type = ?
+		GROUP BY e.id
+		ORDER BY COUNT(*) DESC, SUM(pe.mentions) DESC, e.name
+		LIMIT ?
+	`, entityType, entityType, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []Summary
+	for rows.Next() {
+		var s Summary
+		var first, last sql.NullString
+		if err := rows.Scan(&s.ID, &s.Name, &s.Type, &s.Posts, &s.Mentions, &first, &last); err != nil {
+			return nil, err
+		}
+		s.FirstSeen, s.LastSeen = database.ParseTime(first), database.ParseTime(last)
+		summaries = append(summaries, s)
+	}
+	return summaries, rows.Err()
+}
+
+// Find returns the entity known by the name or one of its aliases, or
+// sql.ErrNoRows
+func (r *Repository) Find(name string) (*Entity, error) {
+	var e Entity
+	err := r.db.QueryRow(`
+		SELECT e.id, e.name, e.type FROM entity_aliases a JOIN entities e ON a.entity_id = e.id WHERE a.key = ?
+	`, Key(name)).Scan(&e.ID, &e.Name, &e.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`SELECT alias FROM entity_aliases WHERE entity_id = ? ORDER BY alias`, e.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, err
+		}
+		if alias != e.Name {
+			e.Aliases = append(e.Aliases, alias)
+		}
+	}
+	return &e, rows.Err()
+}
+
+// Posts returns the posts mentioning an entity, newest first
+func (r *Repository) Posts(entityID int64) ([]PostMention, error) {
+	rows, err := r.db.Query(`
+		SELECT p.id, p.title, p.url, p.published_at, pe.mentions, COALESCE(pe.context, '')
+		FROM post_entities pe
+		JOIN posts p ON pe.post_id = p.id
+		WHERE pe.entity_id = ?
+		ORDER BY p.published_at DESC
+	`, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []PostMention
+	for rows.Next() {
+		var pm PostMention
+		if err := rows.Scan(&pm.PostID, &pm.Title, &pm.URL, &pm.PublishedAt, &pm.Mentions, &pm.Context); err != nil {
+			return nil, err
+		}
+		posts = append(posts, pm)
+	}
+	return posts, rows.Err()
+}
+
+// Insights returns the insights of posts mentioning the entity that name it,
+// such as quotes attributed to a person, newest post first
+func (r *Repository) Insights(e *Entity) ([]InsightMention, error) {
+	rows, err := r.db.Query(`
+		SELECT i.id, i.post_id, i.type, i.content, COALESCE(i.detail, ''), COALESCE(i.importance, 0), p.title
+		FROM insights i
+		JOIN post_entities pe ON pe.post_id = i.post_id AND pe.entity_id = ?
+		JOIN posts p ON i.post_id = p.id
+		ORDER BY p.published_at DESC, i.importance DESC
+	`, e.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := append([]string{e.Name}, e.Aliases...)
+	var insights []InsightMention
+	for rows.Next() {
+		var im InsightMention
+		if err := rows.Scan(&im.ID, &im.PostID, &im.Type, &im.Content, &im.Detail, &im.Importance, &im.PostTitle); err != nil {
+			return nil, err
+		}
+		if Contains(im.Content, names) || Contains(im.Detail, names) {
+			insights = append(insights, im)
+		}
+	}
+	return insights, rows.Err()
+}
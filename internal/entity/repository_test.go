@@ -0,0 +1,113 @@
+package entity
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/insight"
+	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/julienpequegnot/blogmon/internal/source"
+)
+
+func setupTestDB(t *testing.T) (*database.DB, []int64) {
+	tmpDir := t.TempDir()
+	db, err := database.New(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+
+	srcRepo := source.NewRepository(db)
+	src, _ := srcRepo.Add("https://test.com", "Test", "")
+
+	postRepo := post.NewRepository(db)
+	var ids []int64
+	for i, url := range []string{"https://test.com/p1", "https://test.com/p2"} {
+		p, _ := postRepo.Add(src.ID, url, "Post", "Author", time.Now().AddDate(0, 0, i-10), "content")
+		ids = append(ids, p.ID)
+	}
+
+	return db, ids
+}
+
+func TestAliasesResolveToOneEntity(t *testing.T) {
+	db, ids := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	if err := repo.ReplaceForPost(ids[0], []Mention{
+		{Name: "Postgres", Type: TypeProject, Count: 3, Context: "We use Postgres."},
+		{Name: "Brendan Gregg", Type: TypePerson, Count: 1},
+	}); err != nil {
+		t.Fatalf("failed to store mentions: %v", err)
+	}
+	if err := repo.ReplaceForPost(ids[1], []Mention{
+		{Name: "PostgreSQL", Type: TypeProject, Aliases: []string{"postgres"}, Count: 2},
+	}); err != nil {
+		t.Fatalf("failed to store mentions: %v", err)
+	}
+
+	top, err := repo.Top("", 10)
+	if err != nil {
+		t.Fatalf("failed to list entities: %v", err)
+	}
+	if len(top) != 2 || top[0].Name != "Postgres" || top[0].Posts != 2 || top[0].Mentions != 5 {
+		t.Fatalf("expected Postgres in 2 posts first, got %+v", top)
+	}
+	if top[0].LastSeen == nil || top[0].FirstSeen == nil || !top[0].LastSeen.After(*top[0].FirstSeen) {
+		t.Errorf("expected first and last seen dates, got %v and %v", top[0].FirstSeen, top[0].LastSeen)
+	}
+
+	people, _ := repo.Top(TypePerson, 10)
+	if len(people) != 1 || people[0].Name != "Brendan Gregg" {
+		t.Errorf("expected only Brendan Gregg, got %+v", people)
+	}
+
+	e, err := repo.Find("postgresql")
+	if err != nil {
+		t.Fatalf("failed to find entity by alias: %v", err)
+	}
+	if e.Name != "Postgres" || len(e.Aliases) != 1 || e.Aliases[0] != "PostgreSQL" {
+		t.Errorf("unexpected entity: %+v", e)
+	}
+
+	posts, err := repo.Posts(e.ID)
+	if err != nil {
+		t.Fatalf("failed to list posts: %v", err)
+	}
+	if len(posts) != 2 || posts[0].PostID != ids[1] || posts[1].Context != "We use Postgres." {
+		t.Errorf("expected both posts, newest first, got %+v", posts)
+	}
+
+	// Re-extraction replaces the post's mentions
+	repo.ReplaceForPost(ids[0], nil)
+	if posts, _ := repo.Posts(e.ID); len(posts) != 1 {
+		t.Errorf("expected 1 post after re-extraction, got %d", len(posts))
+	}
+}
+
+func TestInsightsAboutEntity(t *testing.T) {
+	db, ids := setupTestDB(t)
+	defer db.Close()
+
+	insightRepo := insight.NewRepository(db)
+	insightRepo.AddWithDetail(ids[0], insight.TypeQuote, "Flame graphs show where time goes", "Brendan Gregg", 3)
+	insightRepo.Add(ids[0], insight.TypeTakeaway, "Profile before tuning", 5)
+	insightRepo.AddWithDetail(ids[1], insight.TypeQuote, "Unrelated post quoting Brendan Gregg", "Someone", 3)
+
+	repo := NewRepository(db)
+	repo.ReplaceForPost(ids[0], []Mention{{Name: "Brendan Gregg", Type: TypePerson, Count: 1}})
+
+	e, err := repo.Find("Brendan Gregg")
+	if err != nil {
+		t.Fatalf("failed to find entity: %v", err)
+	}
+	insights, err := repo.Insights(e)
+	if err != nil {
+		t.Fatalf("failed to list insights: %v", err)
+	}
+	if len(insights) != 1 || insights[0].Detail != "Brendan Gregg" {
+		t.Errorf("expected the attributed quote only, got %+v", insights)
+	}
+}
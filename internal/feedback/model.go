@@ -0,0 +1,214 @@
+// Package feedback learns which posts the user likes from their ratings,
+// with a naive Bayes model over the words, topics and source of posts.
+package feedback
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Class indexes of the model counts
+const (
+	disliked = 0
+	liked    = 1
+)
+
+// Example is a rated post, reduced to its features
+type Example struct {
+	PostID   int64
+	Features []string
+	Liked    bool
+}
+
+// Model is a naive Bayes classifier over the presence of post features. It
+// is stored as JSON, so its fields are exported.
+type Model struct {
+	Docs   [2]int            `json:"docs"`   // rated posts per class
+	Counts [2]map[string]int `json:"counts"` // posts per class having the feature
+	Vocab  map[string]int    `json:"vocab"`  // posts having the feature, both classes
+}
+
+// FeatureWeight is how strongly a feature points to a like (positive) or a
+// dislike (negative)
+type FeatureWeight struct {
+	Feature string
+	Weight  float64
+}
+
+// Features describes a post for the model: the distinct words of its title
+// and content, its topics and its source
+func Features(title, content string, sourceID int64, topics []string) []string {
+	seen := make(map[string]bool)
+	var features []string
+	add := func(f string) {
+		if !seen[f] {
+			seen[f] = true
+			features = append(features, f)
+		}
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(title+" "+content), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		if len(w) > 2 && !stopWords[w] {
+			add(w)
+		}
+	}
+	for _, t := range topics {
+		add("topic:" + strings.ToLower(t))
+	}
+	if sourceID != 0 {
+		add(fmt.Sprintf("source:%d", sourceID))
+	}
+	return features
+}
+
+// Train fits a model to rated posts
+func Train(examples []Example) *Model {
+	m := &Model{
+		Counts: [2]map[string]int{{}, {}},
+		Vocab:  make(map[string]int),
+	}
+	for _, ex := range examples {
+		m.add(ex, 1)
+	}
+	return m
+}
+
+func (m *Model) add(ex Example, delta int) {
+	c := class(ex.Liked)
+	m.Docs[c] += delta
+	for _, f := range ex.Features {
+		m.Counts[c][f] += delta
+		m.Vocab[f] += delta
+		if m.Vocab[f] == 0 {
+			delete(m.Vocab, f)
+		}
+	}
+}
+
+// Ready reports whether the model has seen both likes and dislikes, without
+// which it can't tell them apart
+func (m *Model) Ready() bool {
+	return m != nil && m.Docs[liked] > 0 && m.Docs[disliked] > 0
+}
+
+// Ratings is the number of rated posts the model was trained on
+func (m *Model) Ratings() int {
+	if m == nil {
+		return 0
+	}
+	return m.Docs[liked] + m.Docs[disliked]
+}
+
+// Probability is the chance that the user likes a post with these features.
+// Features the model never saw are ignored. The evidence of the features is
+// damped by the square root of their count, so long posts don't get extreme
+// probabilities from many weakly informative words.
+func (m *Model) Probability(features []string) float64 {
+	if !m.Ready() {
+		return 0.5
+	}
+
+	logit := math.Log(float64(m.Docs[liked]+1) / float64(m.Docs[disliked]+1))
+	evidence, known := 0.0, 0
+	for _, f := range features {
+		if m.Vocab[f] == 0 {
+			continue
+		}
+		evidence += m.logRatio(f)
+		known++
+	}
+	if known > 0 {
+		logit += evidence / math.Sqrt(float64(known))
+	}
+	return 1 / (1 + math.Exp(-logit))
+}
+
+// Score is the personal relevance of a post, 0-100
+func (m *Model) Score(features []string) float64 {
+	return m.Probability(features) * 100
+}
+
+// logRatio is the log ratio of the Laplace-smoothed shares of liked and
+// disliked posts having a feature. Features as common in both say nothing.
+func (m *Model) logRatio(f string) float64 {
+	pLiked := float64(m.Counts[liked][f]+1) / float64(m.Docs[liked]+2)
+	pDisliked := float64(m.Counts[disliked][f]+1) / float64(m.Docs[disliked]+2)
+	return math.Log(pLiked / pDisliked)
+}
+
+// TopFeatures returns the n features seen in at least minPosts posts that
+// point most strongly to a like and to a dislike
+func (m *Model) TopFeatures(n, minPosts int) (likes, dislikes []FeatureWeight) {
+	if !m.Ready() {
+		return nil, nil
+	}
+
+	var weights []FeatureWeight
+	for f, count := range m.Vocab {
+		if count >= minPosts {
+			weights = append(weights, FeatureWeight{f, m.logRatio(f)})
+		}
+	}
+	sort.Slice(weights, func(i, j int) bool {
+		if weights[i].Weight != weights[j].Weight {
+			return weights[i].Weight > weights[j].Weight
+		}
+		return weights[i].Feature < weights[j].Feature
+	})
+
+	for i := 0; i < len(weights) && len(likes) < n && weights[i].Weight > 0; i++ {
+		likes = append(likes, weights[i])
+	}
+	for i := len(weights) - 1; i >= 0 && len(dislikes) < n && weights[i].Weight < 0; i-- {
+		dislikes = append(dislikes, weights[i])
+	}
+	return likes, dislikes
+}
+
+// Accuracy estimates how often the model predicts a rating right, with
+// leave-one-out cross-validation: each post is predicted by a model trained
+// on all the other ratings. It returns 0 when there are too few ratings to
+// hold one out.
+func Accuracy(examples []Example) float64 {
+	m := Train(examples)
+	correct, evaluated := 0, 0
+	for _, ex := range examples {
+		m.add(ex, -1)
+		if m.Ready() {
+			evaluated++
+			if (m.Probability(ex.Features) >= 0.5) == ex.Liked {
+				correct++
+			}
+		}
+		m.add(ex, 1)
+	}
+	if evaluated == 0 {
+		return 0
+	}
+	return float64(correct) / float64(evaluated)
+}
+
+func class(isLiked bool) int {
+	if isLiked {
+		return liked
+	}
+	return disliked
+}
+
+// stopWords are common English words that say nothing about taste
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "but": true, "not": true,
+	"you": true, "all": true, "can": true, "her": true, "was": true, "one": true,
+	"our": true, "out": true, "has": true, "have": true, "had": true, "this": true,
+	"that": true, "with": true, "from": true, "they": true, "will": true, "would": true,
+	"there": true, "their": true, "what": true, "about": true, "which": true, "when": true,
+	"your": true, "than": true, "then": true, "them": true, "these": true, "some": true,
+	"into": true, "just": true, "also": true, "more": true, "its": true, "how": true,
+	"who": true, "been": true, "were": true, "does": true, "very": true, "here": true,
+}
@@ -0,0 +1,76 @@
+package feedback
+
+import (
+	"testing"
+)
+
+func TestFeatures(t *testing.T) {
+	features := Features("Go Generics", "The generics proposal, and generics again.", 3, []string{"Golang"})
+
+	want := []string{"generics", "proposal", "again", "topic:golang", "source:3"}
+	if len(features) != len(want) {
+		t.Fatalf("expected %v, got %v", want, features)
+	}
+	for i := range want {
+		if features[i] != want[i] {
+			t.Errorf("feature %d: expected %q, got %q", i, want[i], features[i])
+		}
+	}
+}
+
+func ratedExamples() []Example {
+	return []Example{
+		{Features: Features("Rust ownership", "borrow checker lifetimes", 1, []string{"rust"}), Liked: true},
+		{Features: Features("Rust async", "tokio futures lifetimes", 1, []string{"rust"}), Liked: true},
+		{Features: Features("Borrow checker tricks", "rust lifetimes borrow", 2, nil), Liked: true},
+		{Features: Features("Crypto prices", "bitcoin market tokens", 3, []string{"crypto"}), Liked: false},
+		{Features: Features("NFT drops", "market hype tokens", 3, []string{"crypto"}), Liked: false},
+		{Features: Features("Bitcoin halving", "bitcoin market miners", 2, nil), Liked: false},
+	}
+}
+
+func TestModelPredictsRatings(t *testing.T) {
+	m := Train(ratedExamples())
+	if !m.Ready() || m.Ratings() != 6 {
+		t.Fatalf("expected a ready model of 6 ratings, got %+v", m.Docs)
+	}
+
+	rust := m.Probability(Features("Rust error handling", "lifetimes and the borrow checker", 1, []string{"rust"}))
+	crypto := m.Probability(Features("Bitcoin ETF", "market tokens", 3, []string{"crypto"}))
+	if rust <= 0.5 || crypto >= 0.5 {
+		t.Errorf("expected rust liked and crypto disliked, got %.2f and %.2f", rust, crypto)
+	}
+	if unknown := m.Probability([]string{"never-seen"}); unknown != 0.5 {
+		t.Errorf("expected unseen features to fall back to the even prior, got %.2f", unknown)
+	}
+
+	likes, dislikes := m.TopFeatures(3, 2)
+	if len(likes) == 0 || len(dislikes) == 0 || likes[0].Weight <= 0 || dislikes[0].Weight >= 0 {
+		t.Errorf("unexpected top features: %v / %v", likes, dislikes)
+	}
+}
+
+func TestModelNeedsBothClasses(t *testing.T) {
+	m := Train(ratedExamples()[:3])
+	if m.Ready() {
+		t.Error("expected a model without dislikes not to be ready")
+	}
+	if p := m.Probability(Features("Rust", "lifetimes", 1, nil)); p != 0.5 {
+		t.Errorf("expected an unready model to be neutral, got %.2f", p)
+	}
+
+	var none *Model
+	if none.Ready() || none.Ratings() != 0 {
+		t.Error("expected a nil model to be empty")
+	}
+}
+
+func TestAccuracy(t *testing.T) {
+	if acc := Accuracy(ratedExamples()); acc != 1 {
+		t.Errorf("expected separable ratings to be predicted right, got %.2f", acc)
+	}
+	// Holding out the only dislike leaves nothing to compare against
+	if acc := Accuracy(ratedExamples()[2:4]); acc != 0 {
+		t.Errorf("expected no accuracy without enough ratings, got %.2f", acc)
+	}
+}
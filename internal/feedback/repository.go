@@ -0,0 +1,167 @@
+package feedback
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+)
+
+// modelName keys the personal relevance model in the models table
+const modelName = "personal_relevance"
+
+// Rating is the user's verdict on a post
+type Rating struct {
+	PostID  int64
+	Liked   bool
+	RatedAt time.Time
+}
+
+// Stats describe the stored model
+type Stats struct {
+	Ratings   int
+	Likes     int
+	Dislikes  int
+	Accuracy  float64 // leave-one-out, 0 when unknown
+	TrainedAt time.Time
+}
+
+type Repository struct {
+	db *database.DB
+}
+
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Rate records whether the user liked a post, replacing an earlier rating
+func (r *Repository) Rate(postID int64, isLiked bool) error {
+	rating := -1
+	if isLiked {
+		rating = 1
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO ratings (post_id, rating, rated_at) VALUES (?, ?, ?)
+		ON CONFLICT(post_id) DO UPDATE SET rating = excluded.rating, rated_at = excluded.rated_at
+	`, postID, rating, time.Now().UTC())
+	return err
+}
+
+// Clear removes the rating of a post. It reports whether there was one.
+func (r *Repository) Clear(postID int64) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM ratings WHERE post_id = ?`, postID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Get returns the rating of a post, or nil when it isn't rated
+func (r *Repository) Get(postID int64) (*Rating, error) {
+	rating := &Rating{PostID: postID}
+	var value int
+	err := r.db.QueryRow(`SELECT rating, rated_at FROM ratings WHERE post_id = ?`, postID).Scan(&value, &rating.RatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rating.Liked = value > 0
+	return rating, nil
+}
+
+// Examples returns the rated posts with their features
+func (r *Repository) Examples() ([]Example, error) {
+	rows, err := r.db.Query(`
+		SELECT p.id, p.title, COALESCE(p.content_clean, ''), p.source_id, r.rating,
+			COALESCE((SELECT GROUP_CONCAT(topic, char(10)) FROM post_topics WHERE post_id = p.id), '')
+		FROM ratings r
+		JOIN posts p ON p.id = r.post_id
+		ORDER BY r.post_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var examples []Example
+	for rows.Next() {
+		var id, sourceID int64
+		var title, content, topics string
+		var rating int
+		if err := rows.Scan(&id, &title, &content, &sourceID, &rating, &topics); err != nil {
+			return nil, err
+		}
+		var topicList []string
+		if topics != "" {
+			topicList = strings.Split(topics, "\n")
+		}
+		examples = append(examples, Example{
+			PostID:   id,
+			Features: Features(title, content, sourceID, topicList),
+			Liked:    rating > 0,
+		})
+	}
+	return examples, rows.Err()
+}
+
+// Retrain fits the model to the current ratings and stores it with its
+// cross-validated accuracy
+func (r *Repository) Retrain() (*Model, Stats, error) {
+	examples, err := r.Examples()
+	if err != nil {
+		return nil, Stats{}, err
+	}
+
+	m := Train(examples)
+	stats := Stats{
+		Ratings:   m.Ratings(),
+		Likes:     m.Docs[liked],
+		Dislikes:  m.Docs[disliked],
+		Accuracy:  Accuracy(examples),
+		TrainedAt: time.Now().UTC(),
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, Stats{}, err
+	}
+	_, err = r.db.Exec(`
+		INSERT INTO models (name, data, examples, accuracy, trained_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			data = excluded.data, examples = excluded.examples,
+			accuracy = excluded.accuracy, trained_at = excluded.trained_at
+	`, modelName, string(data), stats.Ratings, stats.Accuracy, stats.TrainedAt)
+	if err != nil {
+		return nil, Stats{}, err
+	}
+	return m, stats, nil
+}
+
+// Model returns the stored model and its stats, or a nil model when it was
+// never trained
+func (r *Repository) Model() (*Model, Stats, error) {
+	var data string
+	var stats Stats
+	err := r.db.QueryRow(`
+		SELECT data, accuracy, trained_at FROM models WHERE name = ?
+	`, modelName).Scan(&data, &stats.Accuracy, &stats.TrainedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, Stats{}, nil
+	}
+	if err != nil {
+		return nil, Stats{}, err
+	}
+
+	m := &Model{}
+	if err := json.Unmarshal([]byte(data), m); err != nil {
+		return nil, Stats{}, err
+	}
+	stats.Ratings, stats.Likes, stats.Dislikes = m.Ratings(), m.Docs[liked], m.Docs[disliked]
+	return m, stats, nil
+}
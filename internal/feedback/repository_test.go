@@ -0,0 +1,85 @@
+package feedback
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/julienpequegnot/blogmon/internal/source"
+	"github.com/julienpequegnot/blogmon/internal/topic"
+)
+
+func setupTestDB(t *testing.T) (*database.DB, *post.Repository, int64) {
+	tmpDir := t.TempDir()
+	db, err := database.New(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+
+	src, err := source.NewRepository(db).Add("https://test.com", "Test Blog", "https://test.com/feed")
+	if err != nil {
+		t.Fatalf("failed to add source: %v", err)
+	}
+	return db, post.NewRepository(db), src.ID
+}
+
+func TestRateAndRetrain(t *testing.T) {
+	db, postRepo, sourceID := setupTestDB(t)
+	defer db.Close()
+
+	liked, _ := postRepo.Add(sourceID, "https://test.com/rust", "Rust lifetimes", "Author", time.Now(), "")
+	disliked, _ := postRepo.Add(sourceID, "https://test.com/crypto", "Crypto market", "Author", time.Now(), "")
+	postRepo.UpdateContentClean(liked.ID, "The borrow checker and lifetimes.")
+	topic.NewRepository(db).SetForPost(liked.ID, []string{"rust"})
+
+	repo := NewRepository(db)
+	if m, _, err := repo.Model(); err != nil || m != nil {
+		t.Fatalf("expected no model before training, got %v (%v)", m, err)
+	}
+
+	repo.Rate(liked.ID, true)
+	repo.Rate(disliked.ID, true)
+	if err := repo.Rate(disliked.ID, false); err != nil {
+		t.Fatalf("failed to rate: %v", err)
+	}
+
+	r, err := repo.Get(disliked.ID)
+	if err != nil || r == nil || r.Liked {
+		t.Fatalf("expected the later rating to replace the earlier, got %+v (%v)", r, err)
+	}
+
+	examples, _ := repo.Examples()
+	if len(examples) != 2 {
+		t.Fatalf("expected 2 examples, got %d", len(examples))
+	}
+	hasTopic := false
+	for _, f := range examples[0].Features {
+		hasTopic = hasTopic || f == "topic:rust"
+	}
+	if !hasTopic {
+		t.Errorf("expected topics among the features, got %v", examples[0].Features)
+	}
+
+	if _, _, err := repo.Retrain(); err != nil {
+		t.Fatalf("failed to retrain: %v", err)
+	}
+	m, stats, err := repo.Model()
+	if err != nil || !m.Ready() {
+		t.Fatalf("expected a stored model, got %v (%v)", m, err)
+	}
+	if stats.Likes != 1 || stats.Dislikes != 1 || stats.TrainedAt.IsZero() {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if p := m.Probability(Features("Rust", "lifetimes", sourceID, []string{"rust"})); p <= 0.5 {
+		t.Errorf("expected the reloaded model to like rust, got %.2f", p)
+	}
+
+	if cleared, _ := repo.Clear(liked.ID); !cleared {
+		t.Error("expected the rating to be cleared")
+	}
+	if cleared, _ := repo.Clear(liked.ID); cleared {
+		t.Error("expected nothing to clear the second time")
+	}
+}
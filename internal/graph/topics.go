@@ -10,7 +10,9 @@ var techTopics = map[string][]string{
 	"golang":              {"go", "golang", "goroutine", "goroutines"},
 	"rust":                {"rust", "rustlang", "cargo", "ownership"},
 	"python":              {"python", "django", "flask", "pytorch"},
-	"javascript":          {"javascript", "typescript", "nodejs", "react", "vue"},
+	"javascript":          {"javascript", "react", "vue"},
+	"typescript":          {"typescript"},
+	"nodejs":              {"nodejs", "node.js"},
 	"distributed-systems": {"distributed", "consensus", "raft", "paxos", "microservices"},
 	"databases":           {"database", "sql", "postgresql", "mysql", "redis", "mongodb"},
 	"kubernetes":          {"kubernetes", "k8s", "docker", "containers", "helm"},
@@ -24,6 +26,76 @@ var techTopics = map[string][]string{
 	"api":                 {"api", "rest", "graphql", "grpc", "openapi"},
 }
 
+// Alternate spellings of topics, mapped to their canonical tag
+var topicAliases = map[string]string{
+	"go":                    "golang",
+	"go-lang":               "golang",
+	"rustlang":              "rust",
+	"rust-lang":             "rust",
+	"js":                    "javascript",
+	"node-js":               "nodejs",
+	"distributed-system":    "distributed-systems",
+	"distributed-computing": "distributed-systems",
+	"database":              "databases",
+	"db":                    "databases",
+	"k8s":                   "kubernetes",
+	"perf":                  "performance",
+	"ml":                    "machine-learning",
+	"ci-cd":                 "devops",
+	"cicd":                  "devops",
+	"software-architecture": "architecture",
+	"system-design":         "architecture",
+	"tests":                 "testing",
+	"unit-testing":          "testing",
+	"parallelism":           "concurrency",
+	"apis":                  "api",
+	"rest-api":              "api",
+}
+
+var topicSeparators = regexp.MustCompile(`[\s_/]+`)
+
+// NormalizeTopic converts a free-form topic tag into its canonical form
+func NormalizeTopic(topic string) string {
+	t := strings.ToLower(strings.TrimSpace(topic))
+	t = strings.TrimPrefix(t, "#")
+	t = topicSeparators.ReplaceAllString(t, "-")
+	for strings.Contains(t, "--") {
+		t = strings.ReplaceAll(t, "--", "-")
+	}
+	t = strings.Trim(t, "-")
+
+	if canonical, ok := topicAliases[t]; ok {
+		return canonical
+	}
+	return t
+}
+
+// NormalizeTopics normalizes and deduplicates a list of topic tags
+func NormalizeTopics(topics []string) []string {
+	seen := make(map[string]bool)
+	var normalized []string
+
+	for _, topic := range topics {
+		t := NormalizeTopic(topic)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		normalized = append(normalized, t)
+	}
+
+	return normalized
+}
+
+// ResolveTopics returns the stored topics of a post, falling back to
+// keyword detection over its content when none were extracted
+func ResolveTopics(stored []string, content string) []string {
+	if len(stored) > 0 {
+		return stored
+	}
+	return ExtractTopics(content)
+}
+
 // ExtractTopics identifies tech topics from content
 func ExtractTopics(content string) []string {
 	content = strings.ToLower(content)
@@ -26,6 +26,16 @@ func TestExtractTopics(t *testing.T) {
 	}
 }
 
+func TestExtractTopicsKeepsJavaScriptRuntimesApart(t *testing.T) {
+	topics := make(map[string]bool)
+	for _, topic := range ExtractTopics("Migrating a Node.js service to TypeScript") {
+		topics[topic] = true
+	}
+	if !topics["typescript"] || !topics["nodejs"] || topics["javascript"] {
+		t.Errorf("expected typescript and nodejs without javascript, got %v", topics)
+	}
+}
+
 func TestComputeTopicSimilarity(t *testing.T) {
 	topics1 := []string{"golang", "concurrency", "performance"}
 	topics2 := []string{"golang", "performance", "rust"}
@@ -50,3 +60,42 @@ func TestComputeTopicSimilarityNoOverlap(t *testing.T) {
 		t.Errorf("expected 0 similarity for non-overlapping topics, got %f", similarity)
 	}
 }
+
+func TestNormalizeTopic(t *testing.T) {
+	cases := map[string]string{
+		"Go":                  "golang",
+		"Distributed Systems": "distributed-systems",
+		"#k8s":                "kubernetes",
+		"machine_learning":    "machine-learning",
+		"  CI/CD ":            "devops",
+		"observability":       "observability",
+		"TypeScript":          "typescript",
+		"node-js":             "nodejs",
+	}
+
+	for in, want := range cases {
+		if got := NormalizeTopic(in); got != want {
+			t.Errorf("NormalizeTopic(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNormalizeTopicsDeduplicates(t *testing.T) {
+	topics := NormalizeTopics([]string{"Go", "golang", "", "Rust"})
+
+	if len(topics) != 2 {
+		t.Errorf("expected 2 topics, got %v", topics)
+	}
+}
+
+func TestResolveTopicsFallback(t *testing.T) {
+	stored := ResolveTopics([]string{"observability"}, "golang goroutines")
+	if len(stored) != 1 || stored[0] != "observability" {
+		t.Errorf("expected stored topics to win, got %v", stored)
+	}
+
+	extracted := ResolveTopics(nil, "golang goroutines")
+	if len(extracted) == 0 {
+		t.Error("expected fallback to keyword extraction")
+	}
+}
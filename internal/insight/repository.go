@@ -1,16 +1,30 @@
 package insight
 
 import (
+	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/julienpequegnot/blogmon/internal/database"
 )
 
+// Insight types
+const (
+	TypeTakeaway    = "takeaway"
+	TypeCodeExample = "code_example"
+	TypeQuote       = "quote"
+	TypeDefinition  = "definition"
+)
+
+// Types lists every insight type in display order
+var Types = []string{TypeTakeaway, TypeQuote, TypeDefinition, TypeCodeExample}
+
 type Insight struct {
 	ID         int64
 	PostID     int64
 	Type       string // "takeaway", "code_example", "quote", "definition"
 	Content    string
+	Detail     string // quote attribution, defined term, or code language
 	Importance int
 }
 
@@ -23,9 +37,13 @@ func NewRepository(db *database.DB) *Repository {
 }
 
 func (r *Repository) Add(postID int64, insightType, content string, importance int) (*Insight, error) {
+	return r.AddWithDetail(postID, insightType, content, "", importance)
+}
+
+func (r *Repository) AddWithDetail(postID int64, insightType, content, detail string, importance int) (*Insight, error) {
 	result, err := r.db.Exec(
-		`INSERT INTO insights (post_id, type, content, importance) VALUES (?, ?, ?, ?)`,
-		postID, insightType, content, importance,
+		`INSERT INTO insights (post_id, type, content, detail, importance) VALUES (?, ?, ?, ?, ?)`,
+		postID, insightType, content, detail, importance,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert insight: %w", err)
@@ -41,13 +59,14 @@ func (r *Repository) Add(postID int64, insightType, content string, importance i
 		PostID:     postID,
 		Type:       insightType,
 		Content:    content,
+		Detail:     detail,
 		Importance: importance,
 	}, nil
 }
 
 func (r *Repository) ListForPost(postID int64) ([]Insight, error) {
 	rows, err := r.db.Query(
-		`SELECT id, post_id, type, content, importance FROM insights WHERE post_id = ? ORDER BY importance DESC`,
+		`SELECT id, post_id, type, content, COALESCE(detail, ''), importance FROM insights WHERE post_id = ? ORDER BY importance DESC`,
 		postID,
 	)
 	if err != nil {
@@ -55,18 +74,58 @@ func (r *Repository) ListForPost(postID int64) ([]Insight, error) {
 	}
 	defer rows.Close()
 
+	return scanInsights(rows)
+}
+
+// ListByType browses insights across all posts. Empty insightType or topic
+// disables that filter.
+func (r *Repository) ListByType(insightType, topic string, limit int) ([]Insight, error) {
+	var conditions []string
+	var args []any
+	if insightType != "" {
+		conditions = append(conditions, "i.type = ?")
+		args = append(args, insightType)
+	}
+	if topic != "" {
+		conditions = append(conditions, "i.post_id IN (SELECT post_id FROM post_topics WHERE topic = ?)")
+		args = append(args, topic)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, limit)
+
+	rows, err := r.db.Query(fmt.Sprintf(`
+		SELECT i.id, i.post_id, i.type, i.content, COALESCE(i.detail, ''), i.importance
+		FROM insights i
+		JOIN posts p ON i.post_id = p.id
+		%s
+		ORDER BY i.importance DESC, p.published_at DESC
+		LIMIT ?
+	`, where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanInsights(rows)
+}
+
+func (r *Repository) DeleteForPost(postID int64) error {
+	_, err := r.db.Exec(`DELETE FROM insights WHERE post_id = ?`, postID)
+	return err
+}
+
+func scanInsights(rows *sql.Rows) ([]Insight, error) {
 	var insights []Insight
 	for rows.Next() {
 		var i Insight
-		if err := rows.Scan(&i.ID, &i.PostID, &i.Type, &i.Content, &i.Importance); err != nil {
+		if err := rows.Scan(&i.ID, &i.PostID, &i.Type, &i.Content, &i.Detail, &i.Importance); err != nil {
 			return nil, err
 		}
 		insights = append(insights, i)
 	}
 	return insights, rows.Err()
 }
-
-func (r *Repository) DeleteForPost(postID int64) error {
-	_, err := r.db.Exec(`DELETE FROM insights WHERE post_id = ?`, postID)
-	return err
-}
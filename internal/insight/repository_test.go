@@ -61,3 +61,51 @@ func TestListInsightsForPost(t *testing.T) {
 		t.Errorf("expected 2 insights, got %d", len(insights))
 	}
 }
+
+func TestListInsightsByType(t *testing.T) {
+	db, postID := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	repo.Add(postID, TypeTakeaway, "Insight 1", 5)
+	repo.AddWithDetail(postID, TypeDefinition, "A log of changes applied in order", "write-ahead log", 3)
+	repo.AddWithDetail(postID, TypeQuote, "Premature optimization is the root of all evil", "Donald Knuth", 3)
+
+	definitions, err := repo.ListByType(TypeDefinition, "", 10)
+	if err != nil {
+		t.Fatalf("failed to list insights: %v", err)
+	}
+
+	if len(definitions) != 1 {
+		t.Fatalf("expected 1 definition, got %d", len(definitions))
+	}
+	if definitions[0].Detail != "write-ahead log" {
+		t.Errorf("expected term 'write-ahead log', got '%s'", definitions[0].Detail)
+	}
+
+	all, _ := repo.ListByType("", "", 10)
+	if len(all) != 3 {
+		t.Errorf("expected 3 insights, got %d", len(all))
+	}
+}
+
+func TestListInsightsByTopic(t *testing.T) {
+	db, postID := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	repo.AddWithDetail(postID, TypeDefinition, "A log of changes applied in order", "write-ahead log", 3)
+
+	insights, _ := repo.ListByType(TypeDefinition, "databases", 10)
+	if len(insights) != 0 {
+		t.Errorf("expected no insights for untagged post, got %d", len(insights))
+	}
+
+	db.Exec(`INSERT INTO post_topics (post_id, topic) VALUES (?, ?)`, postID, "databases")
+
+	insights, _ = repo.ListByType(TypeDefinition, "databases", 10)
+	if len(insights) != 1 {
+		t.Errorf("expected 1 insight for tagged post, got %d", len(insights))
+	}
+}
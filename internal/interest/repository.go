@@ -0,0 +1,198 @@
+// Package interest stores the topics posts are scored for relevance against,
+// kept in sync with the interests of config.yaml.
+package interest
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/config"
+	"github.com/julienpequegnot/blogmon/internal/database"
+)
+
+// ErrNotFound is returned when changing an interest that does not exist
+var ErrNotFound = errors.New("interest not found")
+
+type Repository struct {
+	db *database.DB
+}
+
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// NormalizeTopic lowercases and trims a topic; relevance matching ignores case
+func NormalizeTopic(topic string) string {
+	return strings.ToLower(strings.TrimSpace(topic))
+}
+
+// List returns the interests, heaviest first
+func (r *Repository) List() ([]config.Interest, error) {
+	rows, err := r.db.Query(`SELECT topic, weight, COALESCE(keywords, '') FROM interests ORDER BY weight DESC, topic`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var interests []config.Interest
+	for rows.Next() {
+		var i config.Interest
+		var keywords string
+		if err := rows.Scan(&i.Topic, &i.Weight, &keywords); err != nil {
+			return nil, err
+		}
+		if keywords != "" {
+			if err := json.Unmarshal([]byte(keywords), &i.Keywords); err != nil {
+				return nil, err
+			}
+		}
+		interests = append(interests, i)
+	}
+	return interests, rows.Err()
+}
+
+// Add stores an interest, replacing the weight and keywords of an existing
+// one with the same topic
+func (r *Repository) Add(i config.Interest) error {
+	var keywords any
+	if len(i.Keywords) > 0 {
+		data, err := json.Marshal(i.Keywords)
+		if err != nil {
+			return err
+		}
+		keywords = string(data)
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO interests (topic, weight, keywords, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(topic) DO UPDATE SET
+			weight = excluded.weight,
+			keywords = excluded.keywords,
+			updated_at = excluded.updated_at
+	`, NormalizeTopic(i.Topic), i.Weight, keywords, time.Now().UTC())
+	return err
+}
+
+// Remove deletes an interest
+func (r *Repository) Remove(topic string) error {
+	result, err := r.db.Exec(`DELETE FROM interests WHERE topic = ?`, NormalizeTopic(topic))
+	if err != nil {
+		return err
+	}
+	return requireRow(result)
+}
+
+// SetWeight changes the weight of an interest
+func (r *Repository) SetWeight(topic string, weight float64) error {
+	result, err := r.db.Exec(`UPDATE interests SET weight = ?, updated_at = ? WHERE topic = ?`,
+		weight, time.Now().UTC(), NormalizeTopic(topic))
+	if err != nil {
+		return err
+	}
+	return requireRow(result)
+}
+
+func requireRow(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Replace swaps every interest for the given ones
+func (r *Repository) Replace(interests []config.Interest) error {
+	return r.db.Tx(func(tx *database.DB) error {
+		if _, err := tx.Exec(`DELETE FROM interests`); err != nil {
+			return err
+		}
+		repo := NewRepository(tx)
+		for _, i := range interests {
+			if err := repo.Add(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// LastChange returns when an interest was last added or changed, or the zero
+// time when no interest has a change recorded
+func (r *Repository) LastChange() (time.Time, error) {
+	var last sql.NullString
+	if err := r.db.QueryRow(`SELECT MAX(updated_at) FROM interests`).Scan(&last); err != nil {
+		return time.Time{}, err
+	}
+	if t := database.ParseTime(last); t != nil {
+		return *t, nil
+	}
+	return time.Time{}, nil
+}
+
+// SyncResult tells which side of a sync changed
+type SyncResult int
+
+const (
+	InSync        SyncResult = iota // the table and the config agree
+	ConfigChanged                   // the table was replaced by the config
+	TableChanged                    // the config must be saved with the table
+)
+
+// Sync reconciles the table with the interests of a config file modified at
+// configModified and returns the interests in effect. The side changed last
+// wins, except that a config without interests never empties the table, so
+// a fresh config.yaml from 'blogmon init' keeps the stored interests.
+func (r *Repository) Sync(configured []config.Interest, configModified time.Time) ([]config.Interest, SyncResult, error) {
+	stored, err := r.List()
+	if err != nil {
+		return nil, InSync, err
+	}
+	if same(stored, configured) {
+		return stored, InSync, nil
+	}
+
+	lastChange, err := r.LastChange()
+	if err != nil {
+		return nil, InSync, err
+	}
+
+	if len(configured) > 0 && (len(stored) == 0 || configModified.After(lastChange)) {
+		if err := r.Replace(configured); err != nil {
+			return nil, InSync, err
+		}
+		stored, err = r.List()
+		return stored, ConfigChanged, err
+	}
+	return stored, TableChanged, nil
+}
+
+// same compares interests regardless of order and topic case
+func same(a, b []config.Interest) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	byTopic := make(map[string]config.Interest, len(a))
+	for _, i := range a {
+		byTopic[NormalizeTopic(i.Topic)] = i
+	}
+	for _, i := range b {
+		other, ok := byTopic[NormalizeTopic(i.Topic)]
+		if !ok || other.Weight != i.Weight || !reflect.DeepEqual(nonNil(other.Keywords), nonNil(i.Keywords)) {
+			return false
+		}
+	}
+	return true
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,95 @@
+package interest
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/config"
+	"github.com/julienpequegnot/blogmon/internal/database"
+)
+
+func setupTestDB(t *testing.T) *database.DB {
+	tmpDir := t.TempDir()
+	db, err := database.New(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	return db
+}
+
+func TestAddRemoveSetWeight(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	if err := repo.Add(config.Interest{Topic: " Rust ", Weight: 0.5, Keywords: []string{"cargo", "borrowck"}}); err != nil {
+		t.Fatalf("failed to add interest: %v", err)
+	}
+	repo.Add(config.Interest{Topic: "golang", Weight: 1.0})
+
+	if err := repo.SetWeight("RUST", 2); err != nil {
+		t.Fatalf("failed to set weight: %v", err)
+	}
+
+	interests, err := repo.List()
+	if err != nil {
+		t.Fatalf("failed to list interests: %v", err)
+	}
+	if len(interests) != 2 || interests[0].Topic != "rust" || interests[0].Weight != 2 || len(interests[0].Keywords) != 2 {
+		t.Fatalf("unexpected interests: %+v", interests)
+	}
+
+	if err := repo.Remove("golang"); err != nil {
+		t.Fatalf("failed to remove interest: %v", err)
+	}
+	if err := repo.Remove("golang"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound removing a missing interest, got %v", err)
+	}
+	if err := repo.SetWeight("python", 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound reweighting a missing interest, got %v", err)
+	}
+}
+
+func TestSync(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	configured := []config.Interest{{Topic: "rust", Weight: 1}}
+
+	// An empty table imports the config
+	interests, result, err := repo.Sync(configured, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if result != ConfigChanged || len(interests) != 1 || interests[0].Topic != "rust" {
+		t.Fatalf("expected the config to be imported, got %+v (result %v)", interests, result)
+	}
+
+	// A change made through the table is written back to an older config
+	repo.SetWeight("rust", 3)
+	interests, result, _ = repo.Sync(configured, time.Now().Add(-time.Hour))
+	if result != TableChanged || interests[0].Weight != 3 {
+		t.Errorf("expected the table to win over an older config, got %+v (result %v)", interests, result)
+	}
+
+	// A config edited by hand after the last change replaces the table
+	edited := []config.Interest{{Topic: "golang", Weight: 1, Keywords: []string{"go"}}}
+	interests, result, _ = repo.Sync(edited, time.Now().Add(time.Hour))
+	if result != ConfigChanged || len(interests) != 1 || interests[0].Topic != "golang" {
+		t.Errorf("expected a newer config to win, got %+v (result %v)", interests, result)
+	}
+
+	// A config without interests never empties the table
+	interests, result, _ = repo.Sync(nil, time.Now().Add(2*time.Hour))
+	if result != TableChanged || len(interests) != 1 {
+		t.Errorf("expected an empty config to be refilled, got %+v (result %v)", interests, result)
+	}
+
+	// Nothing to do when both sides agree
+	if _, result, _ = repo.Sync(edited, time.Now().Add(3*time.Hour)); result != InSync {
+		t.Errorf("expected nothing to do when the config matches the table, got %v", result)
+	}
+}
@@ -0,0 +1,216 @@
+package job
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+)
+
+// Pipeline stages tracked per post
+const (
+	StageExtract = "extract"
+	StageSummary = "summary"
+)
+
+// Job statuses. A post without a job for a stage is pending.
+const (
+	StatusPending = "pending"
+	StatusDone    = "done"
+	StatusFailed  = "failed"  // retried once its backoff elapses
+	StatusSkipped = "skipped" // deliberately left alone, e.g. content too short
+	StatusDead    = "dead"    // gave up after MaxAttempts failures
+)
+
+// MaxAttempts is the number of failures after which a job is dead-lettered
+const MaxAttempts = 5
+
+const (
+	baseBackoff = 15 * time.Minute
+	maxBackoff  = 24 * time.Hour
+)
+
+type Job struct {
+	PostID      int64
+	PostTitle   string
+	Stage       string
+	Status      string
+	Attempts    int
+	LastError   string
+	NextRetryAt *time.Time
+	UpdatedAt   time.Time
+}
+
+// Backoff is the delay before retrying a job that failed attempts times:
+// 15 minutes after the first failure, doubling up to a day
+func Backoff(attempts int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < attempts && d < maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, maxBackoff)
+}
+
+type Repository struct {
+	db *database.DB
+}
+
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Done marks the stage as completed for the post
+func (r *Repository) Done(postID int64, stage string) error {
+	return r.set(postID, stage, StatusDone, 0, "", nil)
+}
+
+// Skip marks the stage as deliberately not run for the post
+func (r *Repository) Skip(postID int64, stage, reason string) error {
+	return r.set(postID, stage, StatusSkipped, 0, reason, nil)
+}
+
+// Fail records a failed attempt and schedules the next retry, or
+// dead-letters the job once it has failed MaxAttempts times
+func (r *Repository) Fail(postID int64, stage string, cause error) (*Job, error) {
+	var j *Job
+	err := r.db.Tx(func(tx *database.DB) error {
+		var attempts int
+		err := tx.QueryRow(`
+			SELECT attempts FROM jobs WHERE post_id = ? AND stage = ? AND status = ?
+		`, postID, stage, StatusFailed).Scan(&attempts)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		attempts++
+
+		j = &Job{PostID: postID, Stage: stage, Status: StatusFailed, Attempts: attempts, LastError: cause.Error()}
+		if attempts >= MaxAttempts {
+			j.Status = StatusDead
+		} else {
+			next := time.Now().Add(Backoff(attempts))
+			j.NextRetryAt = &next
+		}
+		return NewRepository(tx).set(postID, stage, j.Status, attempts, j.LastError, j.NextRetryAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (r *Repository) set(postID int64, stage, status string, attempts int, lastError string, nextRetry *time.Time) error {
+	var next any
+	if nextRetry != nil {
+		next = nextRetry.UTC()
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO jobs (post_id, stage, status, attempts, last_error, next_retry_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(post_id, stage) DO UPDATE SET
+			status = excluded.status,
+			attempts = excluded.attempts,
+			last_error = excluded.last_error,
+			next_retry_at = excluded.next_retry_at,
+			updated_at = excluded.updated_at
+	`, postID, stage, status, attempts, lastError, next, time.Now().UTC())
+	return err
+}
+
+// List returns jobs, most recently updated first. Empty stage or statuses
+// match any.
+func (r *Repository) List(stage string, statuses []string, limit int) ([]Job, error) {
+	where := []string{"1 = 1"}
+	var args []any
+	if stage != "" {
+		where = append(where, "j.stage = ?")
+		args = append(args, stage)
+	}
+	if len(statuses) > 0 {
+		where = append(where, "j.status IN (?"+strings.Repeat(", ?", len(statuses)-1)+")")
+		for _, s := range statuses {
+			args = append(args, s)
+		}
+	}
+	args = append(args, limit)
+
+	rows, err := r.db.Query(`
+		SELECT j.post_id, p.title, j.stage, j.status, j.attempts, COALESCE(j.last_error, ''), j.next_retry_at, j.updated_at
+		FROM jobs j
+		JOIN posts p ON j.post_id = p.id
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY j.updated_at DESC
+		LIMIT ?
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []Job
+	for rows.Next() {
+		var j Job
+		if err := rows.Scan(&j.PostID, &j.PostTitle, &j.Stage, &j.Status, &j.Attempts, &j.LastError,
+			&j.NextRetryAt, &j.UpdatedAt); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// Counts returns the number of jobs per stage and status
+func (r *Repository) Counts() (map[string]map[string]int, error) {
+	rows, err := r.db.Query(`SELECT stage, status, COUNT(*) FROM jobs GROUP BY stage, status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]map[string]int)
+	for rows.Next() {
+		var stage, status string
+		var n int
+		if err := rows.Scan(&stage, &status, &n); err != nil {
+			return nil, err
+		}
+		if counts[stage] == nil {
+			counts[stage] = make(map[string]int)
+		}
+		counts[stage][status] = n
+	}
+	return counts, rows.Err()
+}
+
+// Retry makes jobs with one of the given statuses pending again, with a
+// fresh attempt count. Empty stage or postIDs match any.
+func (r *Repository) Retry(stage string, statuses []string, postIDs []int64) (int64, error) {
+	if len(statuses) == 0 {
+		return 0, fmt.Errorf("no statuses to retry")
+	}
+
+	where := []string{"status IN (?" + strings.Repeat(", ?", len(statuses)-1) + ")"}
+	args := []any{StatusPending, time.Now().UTC()}
+	for _, s := range statuses {
+		args = append(args, s)
+	}
+	if stage != "" {
+		where = append(where, "stage = ?")
+		args = append(args, stage)
+	}
+	if len(postIDs) > 0 {
+		where = append(where, "post_id IN (?"+strings.Repeat(", ?", len(postIDs)-1)+")")
+		for _, id := range postIDs {
+			args = append(args, id)
+		}
+	}
+
+	result, err := r.db.Exec(`
+		UPDATE jobs SET status = ?, attempts = 0, next_retry_at = NULL, updated_at = ?
+		WHERE `+strings.Join(where, " AND "), args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,127 @@
+package job
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/julienpequegnot/blogmon/internal/source"
+)
+
+func setupTestDB(t *testing.T) (*database.DB, *post.Post) {
+	tmpDir := t.TempDir()
+	db, err := database.New(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+
+	srcRepo := source.NewRepository(db)
+	src, _ := srcRepo.Add("https://test.com", "Test", "")
+
+	postRepo := post.NewRepository(db)
+	p, err := postRepo.Add(src.ID, "https://test.com/post", "Post", "Author", time.Now(), "<p>content</p>")
+	if err != nil {
+		t.Fatalf("failed to add post: %v", err)
+	}
+
+	return db, p
+}
+
+func TestBackoff(t *testing.T) {
+	if Backoff(1) != 15*time.Minute || Backoff(3) != time.Hour {
+		t.Errorf("unexpected backoff: %v, %v", Backoff(1), Backoff(3))
+	}
+	if Backoff(20) != 24*time.Hour {
+		t.Errorf("expected backoff capped at a day, got %v", Backoff(20))
+	}
+}
+
+func TestFailBacksOffThenDeadLetters(t *testing.T) {
+	db, p := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	postRepo := post.NewRepository(db)
+
+	if queued, _ := postRepo.GetUnextracted(10); len(queued) != 1 {
+		t.Fatalf("expected the new post to be queued, got %d", len(queued))
+	}
+
+	j, err := repo.Fail(p.ID, StageExtract, errors.New("connection refused"))
+	if err != nil {
+		t.Fatalf("failed to record failure: %v", err)
+	}
+	if j.Status != StatusFailed || j.Attempts != 1 || j.NextRetryAt == nil {
+		t.Fatalf("unexpected job after first failure: %+v", j)
+	}
+
+	// Waiting out its backoff, the post leaves the extraction queue only
+	if queued, _ := postRepo.GetUnextracted(10); len(queued) != 0 {
+		t.Errorf("expected the failed post to wait for its retry, got %d queued", len(queued))
+	}
+	db.Exec(`UPDATE jobs SET next_retry_at = ? WHERE post_id = ?`, time.Now().Add(-time.Minute).UTC(), p.ID)
+	if queued, _ := postRepo.GetUnextracted(10); len(queued) != 1 {
+		t.Errorf("expected the post to be retried once its backoff elapsed, got %d queued", len(queued))
+	}
+
+	for i := 2; i <= MaxAttempts; i++ {
+		j, err = repo.Fail(p.ID, StageExtract, errors.New("connection refused"))
+		if err != nil {
+			t.Fatalf("failed to record failure: %v", err)
+		}
+	}
+	if j.Status != StatusDead || j.Attempts != MaxAttempts || j.NextRetryAt != nil {
+		t.Fatalf("expected the job to be dead-lettered, got %+v", j)
+	}
+
+	dead, err := repo.List(StageExtract, []string{StatusDead}, 10)
+	if err != nil {
+		t.Fatalf("failed to list jobs: %v", err)
+	}
+	if len(dead) != 1 || dead[0].PostTitle != "Post" || dead[0].LastError != "connection refused" {
+		t.Errorf("unexpected dead jobs: %+v", dead)
+	}
+
+	n, err := repo.Retry("", []string{StatusFailed, StatusDead}, []int64{p.ID})
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 job retried, got %d (%v)", n, err)
+	}
+	if queued, _ := postRepo.GetUnextracted(10); len(queued) != 1 {
+		t.Errorf("expected the retried post to be queued, got %d", len(queued))
+	}
+
+	j, _ = repo.Fail(p.ID, StageExtract, errors.New("timeout"))
+	if j.Attempts != 1 {
+		t.Errorf("expected retry to reset attempts, got %d", j.Attempts)
+	}
+}
+
+func TestSkippedUntilContentChanges(t *testing.T) {
+	db, p := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	postRepo := post.NewRepository(db)
+
+	if err := repo.Skip(p.ID, StageExtract, "content too short"); err != nil {
+		t.Fatalf("failed to skip: %v", err)
+	}
+	if queued, _ := postRepo.GetUnextracted(10); len(queued) != 0 {
+		t.Errorf("expected the skipped post to leave the queue, got %d", len(queued))
+	}
+
+	counts, _ := repo.Counts()
+	if counts[StageExtract][StatusSkipped] != 1 {
+		t.Errorf("expected 1 skipped extract job, got %v", counts)
+	}
+
+	if changed, err := postRepo.UpdateContentRaw(p.URL, "<p>a much longer version</p>"); err != nil || !changed {
+		t.Fatalf("failed to update content: %v", err)
+	}
+	if queued, _ := postRepo.GetUnextracted(10); len(queued) != 1 {
+		t.Errorf("expected changed content to requeue the post, got %d", len(queued))
+	}
+}
@@ -0,0 +1,75 @@
+package link
+
+import "strings"
+
+// Relationships classified by the LLM. A link's relationship reads from its
+// second post to its first: "extends" means post B extends post A.
+const (
+	Agrees      = "agrees"
+	Contradicts = "contradicts"
+	Extends     = "extends"
+	RespondsTo  = "responds-to"
+	SameTopic   = "same-topic"
+
+	// Inverses of the directed relationships, stored when the older post
+	// has the larger ID
+	ExtendedBy    = "extended-by"
+	RespondedToBy = "responded-to-by"
+)
+
+// SharedTopics prefixes the relationship of links found by topic overlap,
+// e.g. "shared_topics:golang,performance"
+const SharedTopics = "shared_topics"
+
+// Relationships lists the labels the LLM may choose, in display order
+var Relationships = []string{Contradicts, RespondsTo, Extends, Agrees, SameTopic}
+
+var inverses = map[string]string{
+	Extends:       ExtendedBy,
+	ExtendedBy:    Extends,
+	RespondsTo:    RespondedToBy,
+	RespondedToBy: RespondsTo,
+}
+
+// NormalizeRelationship maps a label returned by the LLM to one of
+// Relationships, or returns "" when it matches none
+func NormalizeRelationship(label string) string {
+	label = strings.ToLower(strings.TrimSpace(label))
+	label = strings.NewReplacer("_", "-", " ", "-").Replace(label)
+	switch label {
+	case "builds-on", "extend":
+		return Extends
+	case "response-to", "responds", "reply-to":
+		return RespondsTo
+	case "agree", "supports":
+		return Agrees
+	case "contradict", "disagrees":
+		return Contradicts
+	}
+	for _, known := range Relationships {
+		if label == known {
+			return label
+		}
+	}
+	return ""
+}
+
+// Inverse returns the relationship read in the other direction
+func Inverse(label string) string {
+	if inv, ok := inverses[label]; ok {
+		return inv
+	}
+	return label
+}
+
+// FormatRelationship stores a label with its detail, the rationale of a
+// classification or the shared topics
+func FormatRelationship(label, detail string) string {
+	return label + ":" + detail
+}
+
+// ParseRelationship splits a stored relationship into its label and detail
+func ParseRelationship(s string) (string, string) {
+	label, detail, _ := strings.Cut(s, ":")
+	return label, detail
+}
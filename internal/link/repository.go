@@ -1,7 +1,9 @@
 package link
 
 import (
+	"database/sql"
 	"fmt"
+	"sort"
 
 	"github.com/julienpequegnot/blogmon/internal/database"
 )
@@ -116,3 +118,129 @@ func (r *Repository) DeleteForPost(postID int64) error {
 	_, err := r.db.Exec(`DELETE FROM links WHERE post_id_a = ? OR post_id_b = ?`, postID, postID)
 	return err
 }
+
+// Classify replaces the LLM classification of a pair of posts. The label
+// reads from post B to post A, e.g. "extends" when B extends A.
+func (r *Repository) Classify(postIDA, postIDB int64, label, rationale string, strength float64) error {
+	if postIDA > postIDB {
+		postIDA, postIDB = postIDB, postIDA
+		label = Inverse(label)
+	}
+
+	return r.db.Tx(func(tx *database.DB) error {
+		if _, err := tx.Exec(`
+			DELETE FROM links WHERE post_id_a = ? AND post_id_b = ? AND relationship NOT LIKE ?
+		`, postIDA, postIDB, SharedTopics+":%"); err != nil {
+			return err
+		}
+		_, err := tx.Exec(
+			`INSERT INTO links (post_id_a, post_id_b, relationship, strength) VALUES (?, ?, ?, ?)`,
+			postIDA, postIDB, FormatRelationship(label, rationale), strength,
+		)
+		return err
+	})
+}
+
+// Candidates returns topic links at least minStrength strong, strongest
+// first. Unless all is set, pairs already classified are left out.
+func (r *Repository) Candidates(minStrength float64, all bool, limit int) ([]Link, error) {
+	rows, err := r.db.Query(`
+		SELECT l.id, l.post_id_a, l.post_id_b, l.relationship, MAX(l.strength)
+		FROM links l
+		WHERE l.relationship LIKE ? AND l.strength >= ?
+		  AND (? OR NOT EXISTS (
+			SELECT 1 FROM links c
+			WHERE c.post_id_a = l.post_id_a AND c.post_id_b = l.post_id_b AND c.relationship NOT LIKE ?
+		  ))
+		GROUP BY l.post_id_a, l.post_id_b
+		ORDER BY MAX(l.strength) DESC
+		LIMIT ?
+	`, SharedTopics+":%", minStrength, all, SharedTopics+":%", limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanLinks(rows)
+}
+
+// ListByRelationship returns the classified links with the label, strongest
+// first
+func (r *Repository) ListByRelationship(label string, limit int) ([]Link, error) {
+	rows, err := r.db.Query(`
+		SELECT id, post_id_a, post_id_b, relationship, strength
+		FROM links
+		WHERE relationship LIKE ?
+		ORDER BY strength DESC
+		LIMIT ?
+	`, label+":%", limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanLinks(rows)
+}
+
+// Related is a post linked to another one
+type Related struct {
+	PostID   int64
+	Label    string // how the other post relates to this one, or SharedTopics
+	Detail   string // rationale or shared topics
+	Strength float64
+}
+
+// Related returns the posts linked to a post, strongest first, with the
+// relationship read from the given post: "extends" means it extends the
+// related post. LLM classifications take precedence over shared topics.
+func (r *Repository) Related(postID int64, limit int) ([]Related, error) {
+	links, err := r.GetForPost(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	byPost := make(map[int64]*Related)
+	var order []int64
+	for _, l := range links {
+		other, label := l.PostIDA, l.Relationship
+		if other == postID {
+			other = l.PostIDB
+		}
+
+		label, detail := ParseRelationship(label)
+		if label != SharedTopics && other == l.PostIDB {
+			label = Inverse(label)
+		}
+
+		rel, ok := byPost[other]
+		if !ok {
+			rel = &Related{PostID: other}
+			byPost[other] = rel
+			order = append(order, other)
+		}
+		if rel.Label == "" || rel.Label == SharedTopics && label != SharedTopics {
+			rel.Label, rel.Detail = label, detail
+		}
+		rel.Strength = max(rel.Strength, l.Strength)
+	}
+
+	related := make([]Related, 0, len(order))
+	for _, id := range order {
+		related = append(related, *byPost[id])
+	}
+	sort.SliceStable(related, func(i, j int) bool { return related[i].Strength > related[j].Strength })
+	if len(related) > limit {
+		related = related[:limit]
+	}
+	return related, nil
+}
+
+func scanLinks(rows *sql.Rows) ([]Link, error) {
+	defer rows.Close()
+
+	var links []Link
+	for rows.Next() {
+		var l Link
+		if err := rows.Scan(&l.ID, &l.PostIDA, &l.PostIDB, &l.Relationship, &l.Strength); err != nil {
+			return nil, err
+		}
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
@@ -80,3 +80,74 @@ func TestUpsertLink(t *testing.T) {
 		t.Errorf("expected strength 0.9, got %f", links[0].Strength)
 	}
 }
+
+func TestNormalizeRelationship(t *testing.T) {
+	tests := map[string]string{
+		"Contradicts": Contradicts,
+		"builds on":   Extends,
+		"responds_to": RespondsTo,
+		"unrelated":   "",
+	}
+	for in, want := range tests {
+		if got := NormalizeRelationship(in); got != want {
+			t.Errorf("NormalizeRelationship(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestClassifyAndRelated(t *testing.T) {
+	db, postA, postB := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	repo.Upsert(postA, postB, FormatRelationship(SharedTopics, "golang"), 0.8)
+
+	candidates, err := repo.Candidates(0.5, false, 10)
+	if err != nil {
+		t.Fatalf("failed to list candidates: %v", err)
+	}
+	if len(candidates) != 1 {
+		t.Fatalf("expected 1 candidate, got %d", len(candidates))
+	}
+
+	// Post A extends post B, whose ID is larger: stored as B extended by A
+	if err := repo.Classify(postB, postA, Extends, "Adds benchmarks", 0.8); err != nil {
+		t.Fatalf("failed to classify: %v", err)
+	}
+	if err := repo.Classify(postB, postA, Contradicts, "Disagrees on pooling", 0.8); err != nil {
+		t.Fatalf("failed to reclassify: %v", err)
+	}
+
+	if candidates, _ := repo.Candidates(0.5, false, 10); len(candidates) != 0 {
+		t.Errorf("expected classified pair to be left out, got %d", len(candidates))
+	}
+	if candidates, _ := repo.Candidates(0.5, true, 10); len(candidates) != 1 {
+		t.Errorf("expected classified pair to be listed with all, got %d", len(candidates))
+	}
+
+	debates, err := repo.ListByRelationship(Contradicts, 10)
+	if err != nil {
+		t.Fatalf("failed to list contradictions: %v", err)
+	}
+	if len(debates) != 1 {
+		t.Fatalf("expected reclassification to replace the first label, got %d contradictions", len(debates))
+	}
+	if _, rationale := ParseRelationship(debates[0].Relationship); rationale != "Disagrees on pooling" {
+		t.Errorf("unexpected rationale: %q", rationale)
+	}
+
+	related, err := repo.Related(postA, 5)
+	if err != nil {
+		t.Fatalf("failed to get related posts: %v", err)
+	}
+	if len(related) != 1 || related[0].PostID != postB || related[0].Label != Contradicts {
+		t.Errorf("expected the classification to win over shared topics, got %+v", related)
+	}
+
+	repo.Classify(postB, postA, Extends, "Adds benchmarks", 0.8)
+	fromA, _ := repo.Related(postA, 5)
+	fromB, _ := repo.Related(postB, 5)
+	if fromA[0].Label != Extends || fromB[0].Label != ExtendedBy {
+		t.Errorf("expected A extends B and B extended by A, got %q and %q", fromA[0].Label, fromB[0].Label)
+	}
+}
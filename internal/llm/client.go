@@ -7,12 +7,17 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/prompt"
 )
 
 type Client struct {
 	baseURL    string
 	model      string
+	prompts    *prompt.Set
+	recorder   Recorder
 	httpClient *http.Client
 }
 
@@ -23,11 +28,14 @@ type GenerateRequest struct {
 }
 
 type GenerateResponse struct {
-	Response string `json:"response"`
-	Done     bool   `json:"done"`
+	Response        string `json:"response"`
+	Done            bool   `json:"done"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
 }
 
 type ExtractionResult struct {
+	Summary    string   `json:"summary"`
 	Takeaways  []string `json:"takeaways"`
 	References []struct {
 		URL     string `json:"url"`
@@ -35,35 +43,86 @@ type ExtractionResult struct {
 		Context string `json:"context"`
 	} `json:"references"`
 	Topics []string `json:"topics"`
+	Quotes []struct {
+		Text        string `json:"text"`
+		Attribution string `json:"attribution"`
+	} `json:"quotes"`
+	Definitions []struct {
+		Term       string `json:"term"`
+		Definition string `json:"definition"`
+	} `json:"definitions"`
+	CodeExamples []struct {
+		Language string `json:"language"`
+		Code     string `json:"code"`
+	} `json:"code_examples"`
+	Entities []struct {
+		Name    string   `json:"name"`
+		Type    string   `json:"type"`
+		Aliases []string `json:"aliases"`
+	} `json:"entities"`
 }
 
 func NewClient(baseURL, model string, timeout time.Duration) *Client {
 	return &Client{
 		baseURL: baseURL,
 		model:   model,
+		prompts: prompt.Default(),
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
 	}
 }
 
-func (c *Client) BuildExtractionPrompt(title, content string) string {
-	return fmt.Sprintf(`Analyze this blog post and extract structured information.
+func (c *Client) Model() string {
+	return c.model
+}
 
-Title: %s
+// SetPrompts replaces the built-in prompt templates
+func (c *Client) SetPrompts(prompts *prompt.Set) {
+	c.prompts = prompts
+}
 
-Content:
-%s
+// PromptVersion identifies the template used for the named prompt
+func (c *Client) PromptVersion(name string) string {
+	return c.prompts.Version(name)
+}
 
-Return a JSON object with:
-1. "takeaways": Array of 3-5 key insights (short sentences)
-2. "references": Array of objects with "url", "title", "context" for any links mentioned
-3. "topics": Array of 2-4 topic tags (e.g., "golang", "distributed-systems", "performance")
+func (c *Client) BuildExtractionPrompt(title, content string) (string, error) {
+	return c.prompts.Render(prompt.Extraction, prompt.Data{Title: title, Content: content})
+}
 
-Return ONLY valid JSON, no other text.`, title, content)
+func (c *Client) BuildSummaryPrompt(title, content string) (string, error) {
+	return c.prompts.Render(prompt.Summary, prompt.Data{Title: title, Content: content})
 }
 
+func (c *Client) BuildAnswerPrompt(question string, sources []prompt.Source) (string, error) {
+	return c.prompts.Render(prompt.Answer, prompt.Data{Question: question, Sources: sources})
+}
+
+// Generate sends a raw prompt to the model and returns its response
 func (c *Client) Generate(ctx context.Context, prompt string) (string, error) {
+	return c.run(ctx, StageGenerate, prompt, nil)
+}
+
+// run generates a response and records the call. validate rejects responses
+// the caller cannot use, which are recorded as invalid.
+func (c *Client) run(ctx context.Context, stage, text string, validate func(response string) error) (string, error) {
+	start := time.Now()
+	result, err := c.generate(ctx, text)
+	if err == nil && validate != nil {
+		if verr := validate(result.Response); verr != nil {
+			err = &classError{class: ErrorInvalid, err: verr}
+		}
+	}
+	c.record(stage, start, result, err)
+
+	if err != nil {
+		return "", err
+	}
+	return result.Response, nil
+}
+
+func (c *Client) generate(ctx context.Context, prompt string) (*GenerateResponse, error) {
 	reqBody := GenerateRequest{
 		Model:  c.model,
 		Prompt: prompt,
@@ -72,56 +131,171 @@ func (c *Client) Generate(ctx context.Context, prompt string) (string, error) {
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/generate", bytes.NewBuffer(jsonBody))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, &classError{class: ErrorConnection, err: fmt.Errorf("failed to send request: %w", err)}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("LLM API error %d: %s", resp.StatusCode, string(body))
+		return nil, &classError{class: ErrorHTTP, err: fmt.Errorf("LLM API error %d: %s", resp.StatusCode, string(body))}
 	}
 
 	var result GenerateResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return nil, &classError{class: ErrorDecode, err: fmt.Errorf("failed to decode response: %w", err)}
 	}
 
-	return result.Response, nil
+	return &result, nil
 }
 
 func (c *Client) ExtractInsights(ctx context.Context, title, content string) (*ExtractionResult, error) {
-	prompt := c.BuildExtractionPrompt(title, content)
+	text, err := c.BuildExtractionPrompt(title, content)
+	if err != nil {
+		return nil, err
+	}
 
-	response, err := c.Generate(ctx, prompt)
+	var result *ExtractionResult
+	_, err = c.run(ctx, prompt.Extraction, text, func(response string) error {
+		var perr error
+		result, perr = ParseExtraction(response)
+		return perr
+	})
 	if err != nil {
 		return nil, err
 	}
+	return result, nil
+}
 
-	// Try to parse JSON from response
+// ParseExtraction decodes an extraction result from a raw LLM response
+func ParseExtraction(response string) (*ExtractionResult, error) {
 	var result ExtractionResult
-	if err := json.Unmarshal([]byte(response), &result); err != nil {
+	if err := decodeJSON(response, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// decodeJSON decodes the JSON object in a response, ignoring any text the
+// model added around it
+func decodeJSON(response string, v any) error {
+	if err := json.Unmarshal([]byte(response), v); err != nil {
 		// Try to find JSON in response
 		start := bytes.IndexByte([]byte(response), '{')
 		end := bytes.LastIndexByte([]byte(response), '}')
 		if start >= 0 && end > start {
-			if err := json.Unmarshal([]byte(response[start:end+1]), &result); err != nil {
-				return nil, fmt.Errorf("failed to parse LLM response as JSON: %w", err)
+			if err := json.Unmarshal([]byte(response[start:end+1]), v); err != nil {
+				return fmt.Errorf("failed to parse LLM response as JSON: %w", err)
 			}
 		} else {
-			return nil, fmt.Errorf("no JSON found in LLM response")
+			return fmt.Errorf("no JSON found in LLM response")
 		}
 	}
+	return nil
+}
 
-	return &result, nil
+func (c *Client) Summarize(ctx context.Context, title, content string) (string, error) {
+	text, err := c.BuildSummaryPrompt(title, content)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := c.run(ctx, prompt.Summary, text, requireText("summary"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(response), nil
+}
+
+// Answer writes an answer to the question grounded in the numbered sources
+func (c *Client) Answer(ctx context.Context, question string, sources []prompt.Source) (string, error) {
+	text, err := c.BuildAnswerPrompt(question, sources)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := c.run(ctx, prompt.Answer, text, requireText("answer"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(response), nil
+}
+
+// Relation is how a post relates to an earlier one
+type Relation struct {
+	Relationship string `json:"relationship"`
+	Rationale    string `json:"rationale"`
+}
+
+// Relate classifies how the newer post relates to the older one
+func (c *Client) Relate(ctx context.Context, older, newer prompt.Source) (*Relation, error) {
+	older.Number, newer.Number = 1, 2
+	text, err := c.prompts.Render(prompt.Relation, prompt.Data{Sources: []prompt.Source{older, newer}})
+	if err != nil {
+		return nil, err
+	}
+
+	var rel Relation
+	_, err = c.run(ctx, prompt.Relation, text, func(response string) error {
+		if err := decodeJSON(response, &rel); err != nil {
+			return err
+		}
+		if strings.TrimSpace(rel.Relationship) == "" {
+			return fmt.Errorf("no relationship in LLM response")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+// Card is a flashcard question and answer
+type Card struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// Flashcard turns a takeaway of a post into a question and answer
+func (c *Client) Flashcard(ctx context.Context, title, takeaway string) (*Card, error) {
+	text, err := c.prompts.Render(prompt.Flashcard, prompt.Data{Title: title, Content: takeaway})
+	if err != nil {
+		return nil, err
+	}
+
+	var card Card
+	_, err = c.run(ctx, prompt.Flashcard, text, func(response string) error {
+		if err := decodeJSON(response, &card); err != nil {
+			return err
+		}
+		if strings.TrimSpace(card.Question) == "" || strings.TrimSpace(card.Answer) == "" {
+			return fmt.Errorf("incomplete flashcard in LLM response")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	card.Question, card.Answer = strings.TrimSpace(card.Question), strings.TrimSpace(card.Answer)
+	return &card, nil
+}
+
+func requireText(what string) func(string) error {
+	return func(response string) error {
+		if strings.TrimSpace(response) == "" {
+			return fmt.Errorf("empty %s in LLM response", what)
+		}
+		return nil
+	}
 }
@@ -1,8 +1,15 @@
 package llm
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/prompt"
 )
 
 func TestNewClient(t *testing.T) {
@@ -19,9 +26,120 @@ func TestNewClient(t *testing.T) {
 func TestGeneratePrompt(t *testing.T) {
 	client := NewClient("http://localhost:11434", "llama3.2", 30*time.Second)
 
-	prompt := client.BuildExtractionPrompt("Test Title", "Test content about Go programming.")
+	prompt, err := client.BuildExtractionPrompt("Test Title", "Test content about Go programming.")
+	if err != nil {
+		t.Fatalf("failed to build prompt: %v", err)
+	}
 
 	if prompt == "" {
 		t.Error("expected non-empty prompt")
 	}
 }
+
+func TestExtractionResultParsesInsightTypes(t *testing.T) {
+	raw := `{
+		"takeaways": ["Measure before optimizing"],
+		"quotes": [{"text": "Premature optimization is the root of all evil", "attribution": "Donald Knuth"}],
+		"definitions": [{"term": "p99", "definition": "The latency under which 99% of requests complete"}],
+		"code_examples": [{"language": "go", "code": "ctx, cancel := context.WithTimeout(ctx, time.Second)"}]
+	}`
+
+	result, err := ParseExtraction(raw)
+	if err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+
+	if len(result.Quotes) != 1 || result.Quotes[0].Attribution != "Donald Knuth" {
+		t.Errorf("expected quote attributed to Donald Knuth, got %+v", result.Quotes)
+	}
+	if len(result.Definitions) != 1 || result.Definitions[0].Term != "p99" {
+		t.Errorf("expected definition of p99, got %+v", result.Definitions)
+	}
+	if len(result.CodeExamples) != 1 || result.CodeExamples[0].Language != "go" {
+		t.Errorf("expected go code example, got %+v", result.CodeExamples)
+	}
+}
+
+func TestParseExtractionSurroundingText(t *testing.T) {
+	result, err := ParseExtraction("Here is the JSON:\n{\"takeaways\": [\"one\"]}\nHope this helps!")
+	if err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+	if len(result.Takeaways) != 1 {
+		t.Errorf("expected 1 takeaway, got %d", len(result.Takeaways))
+	}
+
+	if _, err := ParseExtraction("no json here"); err == nil {
+		t.Error("expected error when response has no JSON")
+	}
+}
+
+func TestBuildAnswerPrompt(t *testing.T) {
+	client := NewClient("http://localhost:11434", "llama3.2", 30*time.Second)
+
+	text, err := client.BuildAnswerPrompt("How big should a pool be?", []prompt.Source{
+		{Number: 1, Title: "Pools", Text: "Use Little's law."},
+		{Number: 2, Title: "Queues", Text: "Latency grows near saturation."},
+	})
+	if err != nil {
+		t.Fatalf("failed to build prompt: %v", err)
+	}
+
+	for _, want := range []string{"How big should a pool be?", "[1] Pools\nUse Little's law.", "[2] Queues"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected prompt to contain %q", want)
+		}
+	}
+}
+
+func TestRelate(t *testing.T) {
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		prompt = string(body)
+		w.Write([]byte(`{"response": "Sure: {\"relationship\": \"contradicts\", \"rationale\": \"Argues pools should be small.\"}", "done": true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "llama3.2", 5*time.Second)
+	rel, err := client.Relate(context.Background(),
+		promptSource("Big pools", "Use large pools."), promptSource("Small pools", "Use small pools."))
+	if err != nil {
+		t.Fatalf("relate failed: %v", err)
+	}
+
+	if rel.Relationship != "contradicts" || rel.Rationale != "Argues pools should be small." {
+		t.Errorf("unexpected relation: %+v", rel)
+	}
+	first, second := strings.Index(prompt, "Post 1: Big pools"), strings.Index(prompt, "Post 2: Small pools")
+	if first < 0 || second < first {
+		t.Errorf("expected the older post first in the prompt: %s", prompt)
+	}
+}
+
+func TestFlashcard(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"response": "{\"question\": \"How do you size a pool?\", \"answer\": \"With Little's law.\"}", "done": true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "llama3.2", 5*time.Second)
+	card, err := client.Flashcard(context.Background(), "Pools", "Size pools with Little's law")
+	if err != nil {
+		t.Fatalf("flashcard failed: %v", err)
+	}
+	if card.Question != "How do you size a pool?" || card.Answer != "With Little's law." {
+		t.Errorf("unexpected card: %+v", card)
+	}
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"response": "{\"question\": \"How?\"}", "done": true}`))
+	})
+	if _, err := client.Flashcard(context.Background(), "Pools", "Size pools"); err == nil {
+		t.Error("expected an error for a card without an answer")
+	}
+}
+
+func promptSource(title, text string) prompt.Source {
+	return prompt.Source{Title: title, Text: text}
+}
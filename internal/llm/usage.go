@@ -0,0 +1,100 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// StageGenerate is recorded for raw Generate calls; other calls record the
+// name of their prompt template as stage
+const StageGenerate = "generate"
+
+// Error classes recorded for failed calls
+const (
+	ErrorTimeout    = "timeout"
+	ErrorCanceled   = "canceled"
+	ErrorConnection = "connection"
+	ErrorHTTP       = "http"
+	ErrorDecode     = "decode"
+	ErrorInvalid    = "invalid" // the model answered, but not in a usable form
+	ErrorOther      = "other"
+)
+
+// Call describes one request to the LLM
+type Call struct {
+	Stage          string
+	Model          string
+	PromptTokens   int
+	ResponseTokens int
+	Latency        time.Duration
+	ErrorClass     string // empty on success
+	Error          string
+	At             time.Time
+}
+
+// Recorder stores LLM calls for usage statistics
+type Recorder interface {
+	Record(call Call) error
+}
+
+// SetRecorder records every subsequent call with r
+func (c *Client) SetRecorder(r Recorder) {
+	c.recorder = r
+}
+
+// Failures to record a call are ignored: accounting must never break the
+// pipeline it observes
+func (c *Client) record(stage string, start time.Time, result *GenerateResponse, err error) {
+	if c.recorder == nil {
+		return
+	}
+
+	call := Call{
+		Stage:   stage,
+		Model:   c.model,
+		Latency: time.Since(start),
+		At:      start,
+	}
+	if result != nil {
+		call.PromptTokens = result.PromptEvalCount
+		call.ResponseTokens = result.EvalCount
+	}
+	if err != nil {
+		call.ErrorClass = ErrorClass(err)
+		call.Error = err.Error()
+	}
+	c.recorder.Record(call)
+}
+
+// ErrorClass categorizes an error returned by the client
+func ErrorClass(err error) string {
+	var ce *classError
+	var ne net.Error
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &ne) && ne.Timeout():
+		return ErrorTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorCanceled
+	case errors.As(err, &ce):
+		return ce.class
+	default:
+		return ErrorOther
+	}
+}
+
+type classError struct {
+	class string
+	err   error
+}
+
+func (e *classError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classError) Unwrap() error {
+	return e.err
+}
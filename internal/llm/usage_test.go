@@ -0,0 +1,88 @@
+package llm
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type callLog struct {
+	calls []Call
+}
+
+func (l *callLog) Record(call Call) error {
+	l.calls = append(l.calls, call)
+	return nil
+}
+
+func TestClientRecordsCalls(t *testing.T) {
+	response := `{"response": "A short summary.", "done": true, "prompt_eval_count": 120, "eval_count": 30}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "llama3.2", 5*time.Second)
+	log := &callLog{}
+	client.SetRecorder(log)
+
+	if _, err := client.Summarize(context.Background(), "Title", "Content"); err != nil {
+		t.Fatalf("summarize failed: %v", err)
+	}
+
+	// Not JSON: the extraction fails as invalid, tokens are still counted
+	if _, err := client.ExtractInsights(context.Background(), "Title", "Content"); err == nil {
+		t.Fatal("expected extraction of a non-JSON response to fail")
+	}
+
+	if len(log.calls) != 2 {
+		t.Fatalf("expected 2 recorded calls, got %d", len(log.calls))
+	}
+
+	summary := log.calls[0]
+	if summary.Stage != "summary" || summary.Model != "llama3.2" || summary.ErrorClass != "" {
+		t.Errorf("unexpected summary call: %+v", summary)
+	}
+	if summary.PromptTokens != 120 || summary.ResponseTokens != 30 {
+		t.Errorf("expected 120/30 tokens, got %d/%d", summary.PromptTokens, summary.ResponseTokens)
+	}
+
+	extraction := log.calls[1]
+	if extraction.Stage != "extract" || extraction.ErrorClass != ErrorInvalid || extraction.ResponseTokens != 30 {
+		t.Errorf("unexpected extraction call: %+v", extraction)
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "slow") {
+			time.Sleep(200 * time.Millisecond)
+		}
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "missing", 5*time.Second)
+	_, err := client.Generate(context.Background(), "hi")
+	if got := ErrorClass(err); got != ErrorHTTP {
+		t.Errorf("expected %s for a 404, got %s (%v)", ErrorHTTP, got, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.Generate(ctx, "slow")
+	if got := ErrorClass(err); got != ErrorTimeout {
+		t.Errorf("expected %s for a deadline, got %s (%v)", ErrorTimeout, got, err)
+	}
+
+	client.baseURL = "http://127.0.0.1:1"
+	_, err = client.Generate(context.Background(), "hi")
+	if got := ErrorClass(err); got != ErrorConnection {
+		t.Errorf("expected %s for a refused connection, got %s (%v)", ErrorConnection, got, err)
+	}
+}
@@ -0,0 +1,382 @@
+// Package markdown converts post HTML into clean Markdown, keeping the
+// document structure that matters for reading, search and extraction.
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Elements that never carry post content
+var droppedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Iframe: true, atom.Svg: true, atom.Form: true, atom.Button: true,
+	atom.Nav: true, atom.Header: true, atom.Footer: true, atom.Aside: true,
+	atom.Img: true, atom.Picture: true, atom.Video: true, atom.Audio: true,
+}
+
+// Class or id tokens marking site chrome around the article
+var boilerplateTokens = []string{
+	"nav", "navbar", "menu", "breadcrumb", "breadcrumbs", "sidebar", "footer",
+	"share", "sharing", "social", "comments", "comment", "related", "newsletter", "subscribe",
+}
+
+var (
+	spaceRun     = regexp.MustCompile(`[ \t\r\n\f]+`)
+	blankLines   = regexp.MustCompile(`\n{3,}`)
+	trailingWS   = regexp.MustCompile(`[ \t]+\n`)
+	codeLanguage = regexp.MustCompile(`(?:^|\s)(?:language|lang|highlight|sourceCode)-([A-Za-z0-9_+#-]+)`)
+)
+
+// FromHTML converts an HTML document or fragment to Markdown. Headings,
+// paragraphs, lists, block quotes, code blocks and links are preserved;
+// scripts, styles, images and navigation boilerplate are dropped and
+// entities are decoded.
+func FromHTML(s string) string {
+	doc, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		return strings.TrimSpace(spaceRun.ReplaceAllString(s, " "))
+	}
+
+	w := &writer{}
+	w.children(doc)
+	return tidy(w.String())
+}
+
+// Text converts HTML to a single line of plain text, for previews and
+// other places where Markdown syntax would be noise.
+func Text(s string) string {
+	doc, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		return strings.TrimSpace(spaceRun.ReplaceAllString(s, " "))
+	}
+
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && skip(n) {
+			return
+		}
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return strings.TrimSpace(spaceRun.ReplaceAllString(b.String(), " "))
+}
+
+// writer accumulates Markdown, deferring line breaks until the next write so
+// that consecutive block boundaries collapse into a single blank line
+type writer struct {
+	b       strings.Builder
+	pending int  // line breaks owed before the next write
+	bol     bool // at the beginning of a line
+}
+
+func (w *writer) String() string {
+	return w.b.String()
+}
+
+// block ends the current line and leaves one blank line before what follows
+func (w *writer) block() {
+	if w.b.Len() > 0 {
+		w.pending = 2
+	}
+}
+
+func (w *writer) newline() {
+	if w.b.Len() > 0 && w.pending < 1 {
+		w.pending = 1
+	}
+}
+
+func (w *writer) write(s string) {
+	if s == "" {
+		return
+	}
+	if w.pending > 0 {
+		w.b.WriteString(strings.Repeat("\n", w.pending))
+		w.pending = 0
+		w.bol = true
+	}
+	w.b.WriteString(s)
+	w.bol = strings.HasSuffix(s, "\n")
+}
+
+// text writes inline text with whitespace collapsed
+func (w *writer) text(s string) {
+	s = spaceRun.ReplaceAllString(s, " ")
+	if w.b.Len() == 0 || w.pending > 0 || w.bol || strings.HasSuffix(w.b.String(), " ") {
+		s = strings.TrimLeft(s, " ")
+	}
+	w.write(s)
+}
+
+func (w *writer) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.node(c)
+	}
+}
+
+func (w *writer) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		w.children(n)
+		return
+	}
+
+	if skip(n) {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		if heading := inline(n); heading != "" {
+			w.block()
+			w.write(strings.Repeat("#", level) + " " + heading)
+			w.block()
+		}
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Figure,
+		atom.Figcaption, atom.Dl, atom.Dd, atom.Details, atom.Summary, atom.Table:
+		w.block()
+		w.children(n)
+		w.block()
+	case atom.Dt, atom.Tr:
+		w.newline()
+		w.children(n)
+		w.newline()
+	case atom.Td, atom.Th:
+		if n.PrevSibling != nil {
+			w.write(" | ")
+		}
+		w.text(inline(n))
+	case atom.Br:
+		w.newline()
+	case atom.Hr:
+		w.block()
+		w.write("---")
+		w.block()
+	case atom.Pre:
+		w.block()
+		w.write("```" + preLanguage(n) + "\n")
+		w.write(strings.Trim(textContent(n), "\n"))
+		w.write("\n```")
+		w.block()
+	case atom.Code, atom.Kbd, atom.Samp:
+		if code := strings.TrimSpace(textContent(n)); code != "" {
+			w.write("`" + code + "`")
+		}
+	case atom.Strong, atom.B:
+		w.emphasis(n, "**")
+	case atom.Em, atom.I:
+		w.emphasis(n, "*")
+	case atom.A:
+		w.link(n)
+	case atom.Ul, atom.Ol:
+		w.list(n)
+	case atom.Blockquote:
+		quoted := render(n)
+		if quoted == "" {
+			return
+		}
+		w.block()
+		for i, line := range strings.Split(quoted, "\n") {
+			if i > 0 {
+				w.write("\n")
+			}
+			w.write(strings.TrimRight("> "+line, " "))
+		}
+		w.block()
+	default:
+		w.children(n)
+	}
+}
+
+func (w *writer) emphasis(n *html.Node, marker string) {
+	text := inline(n)
+	if text == "" {
+		return
+	}
+	w.write(marker + text + marker)
+}
+
+func (w *writer) link(n *html.Node) {
+	text := inline(n)
+	href := strings.TrimSpace(attr(n, "href"))
+	if text == "" {
+		return
+	}
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		w.write(text)
+		return
+	}
+	w.write("[" + text + "](" + href + ")")
+}
+
+func (w *writer) list(n *html.Node) {
+	w.block()
+	ordered := n.DataAtom == atom.Ol
+	index := 1
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+			continue
+		}
+		item := render(c)
+		if !strings.Contains(item, "```") {
+			item = blankLines.ReplaceAllString(strings.ReplaceAll(item, "\n\n", "\n"), "\n")
+		}
+
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(index) + ". "
+			index++
+		}
+		indent := strings.Repeat(" ", len(marker))
+		lines := strings.Split(item, "\n")
+		w.newline()
+		w.write(marker + lines[0])
+		for _, line := range lines[1:] {
+			if line != "" {
+				line = indent + line
+			}
+			w.write("\n" + line)
+		}
+	}
+	w.block()
+}
+
+// render converts the children of n as a standalone document
+func render(n *html.Node) string {
+	sub := &writer{}
+	sub.children(n)
+	return tidy(sub.String())
+}
+
+// inline renders the children of n on a single line
+func inline(n *html.Node) string {
+	return strings.TrimSpace(spaceRun.ReplaceAllString(render(n), " "))
+}
+
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// preLanguage reads the language from the class of a <pre> or its <code> child
+func preLanguage(n *html.Node) string {
+	classes := attr(n, "class")
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.DataAtom == atom.Code {
+			classes += " " + attr(c, "class")
+		}
+	}
+	if m := codeLanguage.FindStringSubmatch(classes); m != nil {
+		return strings.ToLower(m[1])
+	}
+	return ""
+}
+
+// skip reports whether an element is dropped along with its children
+func skip(n *html.Node) bool {
+	if droppedTags[n.DataAtom] || hasAttr(n, "hidden") || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	switch n.DataAtom {
+	case atom.Html, atom.Body, atom.Main, atom.Article:
+		// Page wrappers often carry layout classes such as "has-sidebar"
+		return false
+	}
+	for _, token := range strings.Fields(strings.ToLower(attr(n, "class") + " " + attr(n, "id"))) {
+		for _, b := range boilerplateTokens {
+			if token == b || strings.HasPrefix(token, b+"-") || strings.HasSuffix(token, "-"+b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func tidy(s string) string {
+	s = trailingWS.ReplaceAllString(s, "\n")
+	s = blankLines.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// TruncateBytes cuts s to at most maxLen bytes without splitting a UTF-8
+// character
+func TruncateBytes(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	cut := maxLen
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+// FindWords returns the byte ranges of the case-insensitive occurrences of
+// word in text that are not part of a longer word. Letters, digits and
+// underscores join words; punctuation such as "’" or "—" doesn't.
+func FindWords(text, word string) [][]int {
+	if word == "" {
+		return nil
+	}
+	re := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(word))
+	var ranges [][]int
+	for _, loc := range re.FindAllStringIndex(text, -1) {
+		before, _ := utf8.DecodeLastRuneInString(text[:loc[0]])
+		after, _ := utf8.DecodeRuneInString(text[loc[1]:])
+		if !isWordRune(before) && !isWordRune(after) {
+			ranges = append(ranges, loc)
+		}
+	}
+	return ranges
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,89 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFromHTMLStructure(t *testing.T) {
+	html := `<h2>Sizing &amp; tuning</h2>
+<p>Use <strong>pgbouncer</strong> and read <a href="https://example.com/pools">the docs</a>.</p>
+<ul><li>measure p99</li><li>apply <code>SetMaxOpenConns</code></li></ul>
+<blockquote><p>Little's law applies.</p></blockquote>`
+
+	want := "## Sizing & tuning\n\n" +
+		"Use **pgbouncer** and read [the docs](https://example.com/pools).\n\n" +
+		"- measure p99\n" +
+		"- apply `SetMaxOpenConns`\n\n" +
+		"> Little's law applies."
+
+	if got := FromHTML(html); got != want {
+		t.Errorf("unexpected markdown:\n%s\n\nwant:\n%s", got, want)
+	}
+}
+
+func TestFromHTMLCodeBlock(t *testing.T) {
+	html := "<p>Example:</p><pre><code class=\"language-go\">func main() {\n\tfmt.Println(&quot;hi&quot;)\n}\n</code></pre>"
+
+	want := "Example:\n\n```go\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n```"
+	if got := FromHTML(html); got != want {
+		t.Errorf("unexpected markdown:\n%s\n\nwant:\n%s", got, want)
+	}
+}
+
+func TestFromHTMLDropsBoilerplate(t *testing.T) {
+	html := `<body class="has-sidebar">
+<nav><a href="/">Home</a></nav>
+<script>track()</script><style>p { color: red }</style>
+<article><p>The actual post.</p><div class="share-buttons">Share on Twitter</div></article>
+<footer>Copyright</footer>
+</body>`
+
+	got := FromHTML(html)
+	if got != "The actual post." {
+		t.Errorf("expected only the post body, got %q", got)
+	}
+}
+
+func TestFromHTMLNestedLists(t *testing.T) {
+	got := FromHTML(`<ol><li>first<ul><li>detail</li></ul></li><li>second</li></ol>`)
+
+	want := "1. first\n   - detail\n2. second"
+	if got != want {
+		t.Errorf("unexpected markdown:\n%s\n\nwant:\n%s", got, want)
+	}
+}
+
+func TestText(t *testing.T) {
+	got := Text(`<h1>Title</h1><p>Cats &amp; dogs</p><script>x()</script>`)
+	if got != "Title Cats & dogs" {
+		t.Errorf("unexpected text: %q", got)
+	}
+	if strings.Contains(got, "x()") {
+		t.Error("script content should be dropped")
+	}
+}
+
+func TestTruncateBytes(t *testing.T) {
+	if got := TruncateBytes("short", 10); got != "short" {
+		t.Errorf("expected short text untouched, got %q", got)
+	}
+	// "é" is two bytes; cutting at 2 would split it
+	if got := TruncateBytes("aé", 2); got != "a" {
+		t.Errorf("expected the cut to back off to a rune boundary, got %q", got)
+	}
+}
+
+func TestFindWords(t *testing.T) {
+	text := "PostgreSQL’s planner, «postgresql» and PostgreSQLx"
+	found := FindWords(text, "PostgreSQL")
+	if len(found) != 2 {
+		t.Fatalf("expected 2 whole-word matches, got %v", found)
+	}
+	if text[found[1][0]:found[1][1]] != "postgresql" {
+		t.Errorf("expected ranges in the original text, got %q", text[found[1][0]:found[1][1]])
+	}
+	if FindWords("gopher", "go") != nil {
+		t.Error("expected no match inside a longer word")
+	}
+}
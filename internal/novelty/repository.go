@@ -0,0 +1,245 @@
+// Package novelty keeps a persistent TF-IDF index of posts, so novelty is
+// scored against the whole corpus without rebuilding it on every run.
+package novelty
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/scorer"
+)
+
+const (
+	// updateBatch is the number of posts indexed per transaction
+	updateBatch = 500
+	// queryTerms is the number of most distinctive terms of a post used to
+	// find candidates in the full-text index
+	queryTerms = 16
+	// maxCandidates is the number of best full-text matches compared in full
+	maxCandidates = 200
+)
+
+type Repository struct {
+	db *database.DB
+}
+
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Update indexes the posts missing from the index: new posts, and posts
+// whose title or cleaned content changed since they were indexed. It returns
+// the number of posts indexed.
+func (r *Repository) Update() (int, error) {
+	indexed := 0
+	for {
+		n, err := r.updateBatch()
+		indexed += n
+		if err != nil || n < updateBatch {
+			return indexed, err
+		}
+	}
+}
+
+func (r *Repository) updateBatch() (int, error) {
+	rows, err := r.db.Query(`
+		SELECT p.id, p.title, COALESCE(p.content_clean, '')
+		FROM posts p
+		LEFT JOIN novelty_docs d ON d.post_id = p.id
+		WHERE d.post_id IS NULL
+		ORDER BY p.id
+		LIMIT ?
+	`, updateBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	type doc struct {
+		id    int64
+		terms map[string]float64
+	}
+	var docs []doc
+	for rows.Next() {
+		var id int64
+		var title, content string
+		if err := rows.Scan(&id, &title, &content); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if content == "" {
+			content = title
+		}
+		docs = append(docs, doc{id, scorer.TermFrequencies(content)})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	err = r.db.Tx(func(tx *database.DB) error {
+		for _, d := range docs {
+			data, err := json.Marshal(d.terms)
+			if err != nil {
+				return err
+			}
+			if d.terms == nil {
+				data = []byte("{}") // indexed, but without terms to compare
+			}
+			if _, err := tx.Exec(`INSERT INTO novelty_docs (post_id, terms, indexed_at) VALUES (?, ?, ?)`, d.id, string(data), now); err != nil {
+				return err
+			}
+			for term := range d.terms {
+				if _, err := tx.Exec(`
+					INSERT INTO novelty_terms (term, doc_freq) VALUES (?, 1)
+					ON CONFLICT(term) DO UPDATE SET doc_freq = doc_freq + 1
+				`, term); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to index posts for novelty: %w", err)
+	}
+	return len(docs), nil
+}
+
+// Rebuild drops the index and indexes every post again
+func (r *Repository) Rebuild() (int, error) {
+	err := r.db.Tx(func(tx *database.DB) error {
+		if _, err := tx.Exec(`DELETE FROM novelty_docs`); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`DELETE FROM novelty_terms`)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return r.Update()
+}
+
+// Scorer scores novelty against the index as it is now. Document
+// frequencies count the whole index, later posts included; posts indexed
+// after the scorer was loaded don't change them.
+type Scorer struct {
+	db       *database.DB
+	docCount int
+	docFreq  map[string]int
+}
+
+// Scorer loads the document frequencies of the index
+func (r *Repository) Scorer() (*Scorer, error) {
+	s := &Scorer{db: r.db, docFreq: make(map[string]int)}
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM novelty_docs WHERE terms != '{}'`).Scan(&s.docCount); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`SELECT term, doc_freq FROM novelty_terms WHERE doc_freq > 0`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var term string
+		var df int
+		if err := rows.Scan(&term, &df); err != nil {
+			return nil, err
+		}
+		s.docFreq[term] = df
+	}
+	return s, rows.Err()
+}
+
+func (s *Scorer) idf(term string) float64 {
+	return scorer.IDF(s.docCount, s.docFreq[term])
+}
+
+// Score is the novelty of a post published at posted, 0-100: one minus the
+// highest TF-IDF cosine similarity of its content to a post published
+// before it. The post itself and later posts are left out, so a post is as
+// novel as it was when it came out. Only the posts the full-text index ranks
+// best for the most distinctive terms of content are compared, which keeps
+// scoring fast on large corpora.
+func (s *Scorer) Score(postID int64, posted time.Time, content string) (float64, error) {
+	tf := scorer.TermFrequencies(content)
+	if s.docCount == 0 || len(tf) == 0 {
+		return 100, nil
+	}
+
+	candidates, err := s.candidates(tf, postID, posted)
+	if err != nil {
+		return 0, err
+	}
+
+	maxSimilarity := 0.0
+	for _, docTF := range candidates {
+		maxSimilarity = max(maxSimilarity, scorer.CosineSimilarity(tf, docTF, s.idf))
+	}
+	return (1 - maxSimilarity) * 100, nil
+}
+
+// candidates returns the term frequencies of the indexed posts published
+// before posted, other than postID, that best match the most distinctive
+// terms of tf in the full-text index
+func (s *Scorer) candidates(tf map[string]float64, postID int64, posted time.Time) ([]map[string]float64, error) {
+	type weighted struct {
+		term   string
+		weight float64
+	}
+	var terms []weighted
+	for term, f := range tf {
+		if idf := s.idf(term); idf > 0 {
+			terms = append(terms, weighted{term, f * idf})
+		}
+	}
+	if len(terms) == 0 {
+		return nil, nil // no term in common with the corpus
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].weight != terms[j].weight {
+			return terms[i].weight > terms[j].weight
+		}
+		return terms[i].term < terms[j].term
+	})
+
+	var query []string
+	for i := 0; i < len(terms) && i < queryTerms; i++ {
+		query = append(query, `"`+terms[i].term+`"`)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT d.terms
+		FROM posts_fts f
+		JOIN posts p ON p.id = f.rowid
+		JOIN novelty_docs d ON d.post_id = p.id
+		WHERE posts_fts MATCH ? AND p.id != ?
+			AND datetime(COALESCE(p.published_at, p.fetched_at)) < datetime(?)
+		ORDER BY f.rank
+		LIMIT ?
+	`, strings.Join(query, " OR "), postID, posted.UTC(), maxCandidates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var docs []map[string]float64
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var docTF map[string]float64
+		if err := json.Unmarshal([]byte(data), &docTF); err != nil {
+			return nil, err
+		}
+		docs = append(docs, docTF)
+	}
+	return docs, rows.Err()
+}
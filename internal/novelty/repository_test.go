@@ -0,0 +1,145 @@
+package novelty
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/julienpequegnot/blogmon/internal/source"
+)
+
+func setupTestDB(t *testing.T) (*database.DB, *post.Repository, int64) {
+	tmpDir := t.TempDir()
+	db, err := database.New(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+
+	src, err := source.NewRepository(db).Add("https://test.com", "Test Blog", "https://test.com/feed")
+	if err != nil {
+		t.Fatalf("failed to add source: %v", err)
+	}
+	return db, post.NewRepository(db), src.ID
+}
+
+func docFreq(t *testing.T, db *database.DB, term string) int {
+	var df int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(doc_freq), 0) FROM novelty_terms WHERE term = ?`, term).Scan(&df); err != nil {
+		t.Fatalf("failed to read doc_freq: %v", err)
+	}
+	return df
+}
+
+func TestUpdateIsIncremental(t *testing.T) {
+	db, postRepo, sourceID := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	first, _ := postRepo.Add(sourceID, "https://test.com/1", "Goroutines and channels", "Author", time.Now(), "")
+	postRepo.Add(sourceID, "https://test.com/2", "Channels in practice", "Author", time.Now(), "")
+
+	if n, err := repo.Update(); err != nil || n != 2 {
+		t.Fatalf("expected 2 posts indexed, got %d (%v)", n, err)
+	}
+	if n, _ := repo.Update(); n != 0 {
+		t.Errorf("expected nothing left to index, got %d", n)
+	}
+	if df := docFreq(t, db, "channels"); df != 2 {
+		t.Errorf("expected channels in 2 posts, got %d", df)
+	}
+
+	// Changed content drops the post from the index until it is indexed again
+	postRepo.UpdateContentClean(first.ID, "Mutexes and condition variables")
+	if df := docFreq(t, db, "channels"); df != 1 {
+		t.Errorf("expected the changed post to be removed from doc_freq, got %d", df)
+	}
+	if n, _ := repo.Update(); n != 1 {
+		t.Errorf("expected the changed post to be indexed again, got %d", n)
+	}
+	if df := docFreq(t, db, "mutexes"); df != 1 {
+		t.Errorf("expected the new terms to be indexed, got %d", df)
+	}
+
+	// Metrics updates leave the text, and the index, alone
+	postRepo.UpdateMetrics(first.ID, post.Metrics{WordCount: 4})
+	if n, _ := repo.Update(); n != 0 {
+		t.Errorf("expected unchanged text to stay indexed, got %d", n)
+	}
+
+	if n, err := repo.Rebuild(); err != nil || n != 2 {
+		t.Errorf("expected 2 posts after rebuild, got %d (%v)", n, err)
+	}
+	if df := docFreq(t, db, "channels"); df != 1 {
+		t.Errorf("expected doc_freq to be recounted on rebuild, got %d", df)
+	}
+}
+
+func TestScore(t *testing.T) {
+	db, postRepo, sourceID := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	empty, err := repo.Scorer()
+	if err != nil {
+		t.Fatalf("failed to load scorer: %v", err)
+	}
+	if score, _ := empty.Score(0, time.Now(), "anything at all"); score != 100 {
+		t.Errorf("expected 100 novelty for an empty index, got %f", score)
+	}
+
+	earlier := time.Now().Add(-48 * time.Hour)
+	a, _ := postRepo.Add(sourceID, "https://test.com/1", "Go", "Author", earlier, "")
+	b, _ := postRepo.Add(sourceID, "https://test.com/2", "Rust", "Author", earlier, "")
+	postRepo.UpdateContentClean(a.ID, "golang programming concurrency goroutines")
+	postRepo.UpdateContentClean(b.ID, "rust memory safety ownership")
+	repo.Update()
+
+	s, err := repo.Scorer()
+	if err != nil {
+		t.Fatalf("failed to load scorer: %v", err)
+	}
+	similar, err := s.Score(0, time.Now(), "golang programming goroutines concurrency")
+	if err != nil {
+		t.Fatalf("failed to score: %v", err)
+	}
+	different, _ := s.Score(0, time.Now(), "machine learning neural networks tensorflow")
+	if similar >= different || different != 100 {
+		t.Errorf("expected similar content (%f) to be less novel than unrelated content (%f)", similar, different)
+	}
+	if similar > 1 {
+		t.Errorf("expected near zero novelty for the same terms, got %f", similar)
+	}
+}
+
+func TestScoreComparesOnlyEarlierPosts(t *testing.T) {
+	db, postRepo, sourceID := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	original, _ := postRepo.Add(sourceID, "https://test.com/1", "Go", "Author", now.Add(-72*time.Hour), "")
+	followUp, _ := postRepo.Add(sourceID, "https://test.com/2", "Go again", "Author", now.Add(-24*time.Hour), "")
+	other, _ := postRepo.Add(sourceID, "https://test.com/3", "Rust", "Author", now.Add(-48*time.Hour), "")
+	postRepo.UpdateContentClean(original.ID, "golang programming concurrency goroutines")
+	postRepo.UpdateContentClean(followUp.ID, "golang programming concurrency goroutines channels")
+	postRepo.UpdateContentClean(other.ID, "rust memory safety ownership")
+
+	repo := NewRepository(db)
+	repo.Update()
+	s, _ := repo.Scorer()
+
+	// The original post doesn't match itself or the follow-up published later
+	first, err := s.Score(original.ID, now.Add(-72*time.Hour), "golang programming concurrency goroutines")
+	if err != nil {
+		t.Fatalf("failed to score: %v", err)
+	}
+	if first != 100 {
+		t.Errorf("expected the first post on a subject to be fully novel, got %f", first)
+	}
+
+	second, _ := s.Score(followUp.ID, now.Add(-24*time.Hour), "golang programming concurrency goroutines channels")
+	if second >= 50 {
+		t.Errorf("expected the follow-up to be compared with the original, got %f", second)
+	}
+}
@@ -0,0 +1,123 @@
+package post
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Reading speeds used for the reading time estimate
+const (
+	wordsPerMinute     = 230
+	codeLinesPerMinute = 20
+)
+
+// Metrics describe the length, depth and difficulty of a post, computed from
+// its cleaned Markdown
+type Metrics struct {
+	WordCount      int     // prose words, outside code blocks
+	ReadingMinutes float64 // prose at 230 words and code at 20 lines per minute
+	CodeRatio      float64 // share of the text in code blocks, 0-1
+	Headings       int
+	Readability    float64 // Flesch reading ease of the prose, 0 (hard) to 100 (easy)
+}
+
+var (
+	headingLine  = regexp.MustCompile(`^#{1,6}\s`)
+	markdownLink = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	listMarker   = regexp.MustCompile(`^(?:[-*+>]|\d+\.)\s+`)
+	sentenceEnd  = regexp.MustCompile(`[.!?]+(?:\s|$)`)
+	vowelGroup   = regexp.MustCompile(`[aeiouy]+`)
+)
+
+// Measure computes the metrics of cleaned Markdown content. Fenced code
+// blocks count as code, everything else except headings as prose.
+func Measure(content string) Metrics {
+	var m Metrics
+	var prose []string
+	codeLines, codeChars, proseChars := 0, 0, 0
+	inFence := false
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		switch {
+		case inFence:
+			if trimmed != "" {
+				codeLines++
+				codeChars += len(trimmed)
+			}
+		case headingLine.MatchString(trimmed):
+			m.Headings++
+		default:
+			text := listMarker.ReplaceAllString(trimmed, "")
+			text = markdownLink.ReplaceAllString(text, "$1")
+			text = strings.NewReplacer("`", "", "**", "", "__", "").Replace(text)
+			if text == "" {
+				// Blank lines end paragraphs, which ends a sentence too
+				prose = append(prose, "\n")
+				continue
+			}
+			proseChars += len(text)
+			prose = append(prose, text)
+		}
+	}
+
+	text := strings.Join(prose, " ")
+	words := proseWords(text)
+	m.WordCount = len(words)
+	m.ReadingMinutes = float64(m.WordCount)/wordsPerMinute + float64(codeLines)/codeLinesPerMinute
+	if codeChars+proseChars > 0 {
+		m.CodeRatio = float64(codeChars) / float64(codeChars+proseChars)
+	}
+	m.Readability = readability(text, words)
+	return m
+}
+
+// proseWords returns the tokens of text containing a letter or digit
+func proseWords(text string) []string {
+	var words []string
+	for _, field := range strings.Fields(text) {
+		if strings.IndexFunc(field, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			words = append(words, field)
+		}
+	}
+	return words
+}
+
+// readability is the Flesch reading ease of the text, clamped to 0-100
+func readability(text string, words []string) float64 {
+	if len(words) == 0 {
+		return 0
+	}
+
+	sentences := 0
+	for _, s := range sentenceEnd.Split(strings.ReplaceAll(text, "\n", ". "), -1) {
+		if len(proseWords(s)) > 0 {
+			sentences++
+		}
+	}
+	sentences = max(sentences, 1)
+
+	syllableCount := 0
+	for _, w := range words {
+		syllableCount += syllables(w)
+	}
+
+	score := 206.835 - 1.015*float64(len(words))/float64(sentences) - 84.6*float64(syllableCount)/float64(len(words))
+	return math.Max(0, math.Min(100, score))
+}
+
+// syllables estimates the syllables of an English word from its vowel groups
+func syllables(word string) int {
+	word = strings.ToLower(strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) }))
+	n := len(vowelGroup.FindAllString(word, -1))
+	if n > 1 && strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") {
+		n-- // silent e
+	}
+	return max(n, 1)
+}
@@ -0,0 +1,52 @@
+package post
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMeasure(t *testing.T) {
+	content := "# Pools\n\n" +
+		"Connection pools need sizing. Use [pgbouncer](https://pgbouncer.org) and measure latency.\n\n" +
+		"```go\ndb.SetMaxOpenConns(20)\ndb.SetMaxIdleConns(5)\n```\n\n" +
+		"## Results\n\n" +
+		"- Smaller pools were faster.\n"
+
+	m := Measure(content)
+	if m.WordCount != 13 {
+		t.Errorf("expected 13 prose words, got %d", m.WordCount)
+	}
+	if m.Headings != 2 {
+		t.Errorf("expected 2 headings, got %d", m.Headings)
+	}
+	if m.CodeRatio <= 0 || m.CodeRatio >= 0.5 {
+		t.Errorf("expected a minority of code, got %f", m.CodeRatio)
+	}
+	wantMinutes := 13.0/wordsPerMinute + 2.0/codeLinesPerMinute
+	if math.Abs(m.ReadingMinutes-wantMinutes) > 1e-9 {
+		t.Errorf("expected %f minutes, got %f", wantMinutes, m.ReadingMinutes)
+	}
+	if m.Readability <= 0 || m.Readability > 100 {
+		t.Errorf("expected a readability score, got %f", m.Readability)
+	}
+}
+
+func TestReadabilityOrdersText(t *testing.T) {
+	easy := Measure("The cat sat on the mat. It was a good day. We had fun.")
+	hard := Measure("Organizational considerations regarding infrastructure virtualization necessitate comprehensive architectural evaluation.")
+	if easy.Readability <= hard.Readability {
+		t.Errorf("expected simple prose to read easier: %f <= %f", easy.Readability, hard.Readability)
+	}
+	if hard.Readability != 0 {
+		t.Errorf("expected very hard prose to clamp to 0, got %f", hard.Readability)
+	}
+}
+
+func TestSyllables(t *testing.T) {
+	tests := map[string]int{"cat": 1, "make": 1, "table": 2, "latency": 3, "p99": 1, "Postgres,": 2}
+	for word, want := range tests {
+		if got := syllables(word); got != want {
+			t.Errorf("syllables(%q) = %d, want %d", word, got, want)
+		}
+	}
+}
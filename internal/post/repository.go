@@ -2,8 +2,11 @@
 package post
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/julienpequegnot/blogmon/internal/database"
@@ -20,8 +23,50 @@ type Post struct {
 	FetchedAt    time.Time
 	ContentRaw   string
 	ContentClean string
-	WordCount    int
+	Summary      string
 	FinalScore   *float64
+
+	// Provenance of the last extraction
+	ExtractModel         string
+	ExtractPromptVersion string
+	ExtractHash          string
+
+	Metrics
+}
+
+// ExtractionCondition selects posts by how they were last extracted,
+// e.g. "model!=llama3.2" or "version=2"
+type ExtractionCondition struct {
+	Column string
+	Op     string
+	Value  string
+}
+
+var extractionColumns = map[string]string{
+	"model":   "extract_model",
+	"version": "extract_prompt_version",
+}
+
+func ParseExtractionCondition(expr string) (ExtractionCondition, error) {
+	for _, op := range []string{"!=", "="} {
+		field, value, ok := strings.Cut(expr, op)
+		if !ok {
+			continue
+		}
+
+		column, valid := extractionColumns[strings.TrimSpace(field)]
+		if !valid {
+			return ExtractionCondition{}, fmt.Errorf("unknown field in %q (valid fields: model, version)", expr)
+		}
+		return ExtractionCondition{Column: column, Op: op, Value: strings.TrimSpace(value)}, nil
+	}
+	return ExtractionCondition{}, fmt.Errorf("invalid condition %q (expected field=value or field!=value)", expr)
+}
+
+// ContentHash fingerprints content so derived data can be refreshed when it changes
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
 }
 
 type Repository struct {
@@ -63,10 +108,16 @@ func (r *Repository) Exists(url string) (bool, error) {
 	return count > 0, err
 }
 
+func (r *Repository) GetIDByURL(url string) (int64, error) {
+	var id int64
+	err := r.db.QueryRow(`SELECT id FROM posts WHERE url = ?`, url).Scan(&id)
+	return id, err
+}
+
 func (r *Repository) List(limit, offset int) ([]Post, error) {
 	rows, err := r.db.Query(`
 		SELECT p.id, p.source_id, s.name, p.url, p.title, p.author, p.published_at, p.fetched_at,
-		       COALESCE(sc.final_score, 0) as final_score
+		       COALESCE(p.summary, ''), COALESCE(sc.final_score, 0) as final_score
 		FROM posts p
 		JOIN sources s ON p.source_id = s.id
 		LEFT JOIN scores sc ON p.id = sc.post_id
@@ -82,7 +133,7 @@ func (r *Repository) List(limit, offset int) ([]Post, error) {
 	for rows.Next() {
 		var p Post
 		var score sql.NullFloat64
-		if err := rows.Scan(&p.ID, &p.SourceID, &p.SourceName, &p.URL, &p.Title, &p.Author, &p.PublishedAt, &p.FetchedAt, &score); err != nil {
+		if err := rows.Scan(&p.ID, &p.SourceID, &p.SourceName, &p.URL, &p.Title, &p.Author, &p.PublishedAt, &p.FetchedAt, &p.Summary, &score); err != nil {
 			return nil, err
 		}
 		if score.Valid {
@@ -93,28 +144,100 @@ func (r *Repository) List(limit, offset int) ([]Post, error) {
 	return posts, rows.Err()
 }
 
-func (r *Repository) ListSorted(limit, offset int, sortBy string) ([]Post, error) {
+// ListContent returns every post with its raw and cleaned content, newest first
+func (r *Repository) ListContent() ([]Post, error) {
+	rows, err := r.db.Query(`
+		SELECT id, source_id, url, title, COALESCE(content_raw, ''), COALESCE(content_clean, '')
+		FROM posts
+		ORDER BY published_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []Post
+	for rows.Next() {
+		var p Post
+		if err := rows.Scan(&p.ID, &p.SourceID, &p.URL, &p.Title, &p.ContentRaw, &p.ContentClean); err != nil {
+			return nil, err
+		}
+		posts = append(posts, p)
+	}
+	return posts, rows.Err()
+}
+
+// IDsMentioning returns the IDs of the posts whose title or cleaned content
+// contains any of the terms, ignoring ASCII case. The text searched is the
+// one keyword relevance is scored on.
+func (r *Repository) IDsMentioning(terms []string) ([]int64, error) {
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	conditions := make([]string, len(terms))
+	args := make([]any, len(terms))
+	for i, term := range terms {
+		conditions[i] = "instr(lower(title || ' ' || COALESCE(NULLIF(content_clean, ''), title)), ?) > 0"
+		args[i] = strings.ToLower(term)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT id FROM posts
+		WHERE `+strings.Join(conditions, " OR ")+`
+		ORDER BY id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ListSorted returns posts by date, score, source or reading time, shortest
+// first. A positive maxMinutes keeps only posts measured to read in that time.
+func (r *Repository) ListSorted(limit, offset int, sortBy string, maxMinutes float64) ([]Post, error) {
 	orderClause := "ORDER BY p.published_at DESC"
 	switch sortBy {
 	case "score":
 		orderClause = "ORDER BY COALESCE(sc.final_score, 0) DESC, p.published_at DESC"
 	case "source":
 		orderClause = "ORDER BY s.name ASC, p.published_at DESC"
+	case "reading_time":
+		orderClause = "ORDER BY p.reading_minutes IS NULL, p.reading_minutes ASC, p.published_at DESC"
 	case "date":
 		orderClause = "ORDER BY p.published_at DESC"
 	}
 
+	whereClause := ""
+	var args []any
+	if maxMinutes > 0 {
+		whereClause = "WHERE p.reading_minutes <= ?"
+		args = append(args, maxMinutes)
+	}
+
 	query := fmt.Sprintf(`
 		SELECT p.id, p.source_id, s.name, p.url, p.title, p.author, p.published_at, p.fetched_at,
-		       COALESCE(sc.final_score, 0) as final_score
+		       COALESCE(p.summary, ''), COALESCE(sc.final_score, 0) as final_score,
+		       COALESCE(p.word_count, 0), COALESCE(p.reading_minutes, 0)
 		FROM posts p
 		JOIN sources s ON p.source_id = s.id
 		LEFT JOIN scores sc ON p.id = sc.post_id
 		%s
+		%s
 		LIMIT ? OFFSET ?
-	`, orderClause)
+	`, whereClause, orderClause)
 
-	rows, err := r.db.Query(query, limit, offset)
+	rows, err := r.db.Query(query, append(args, limit, offset)...)
 	if err != nil {
 		return nil, err
 	}
@@ -124,7 +247,8 @@ func (r *Repository) ListSorted(limit, offset int, sortBy string) ([]Post, error
 	for rows.Next() {
 		var p Post
 		var score sql.NullFloat64
-		if err := rows.Scan(&p.ID, &p.SourceID, &p.SourceName, &p.URL, &p.Title, &p.Author, &p.PublishedAt, &p.FetchedAt, &score); err != nil {
+		if err := rows.Scan(&p.ID, &p.SourceID, &p.SourceName, &p.URL, &p.Title, &p.Author, &p.PublishedAt, &p.FetchedAt, &p.Summary, &score,
+			&p.WordCount, &p.ReadingMinutes); err != nil {
 			return nil, err
 		}
 		if score.Valid {
@@ -140,14 +264,16 @@ func (r *Repository) Get(id int64) (*Post, error) {
 	var score sql.NullFloat64
 	err := r.db.QueryRow(`
 		SELECT p.id, p.source_id, s.name, p.url, p.title, p.author, p.published_at, p.fetched_at,
-		       p.content_raw, p.content_clean, COALESCE(p.word_count, 0),
-		       COALESCE(sc.final_score, 0)
+		       p.content_raw, COALESCE(p.content_clean, ''), COALESCE(p.word_count, 0),
+		       COALESCE(p.reading_minutes, 0), COALESCE(p.code_ratio, 0), COALESCE(p.headings, 0), COALESCE(p.readability, 0),
+		       COALESCE(p.summary, ''), COALESCE(sc.final_score, 0)
 		FROM posts p
 		JOIN sources s ON p.source_id = s.id
 		LEFT JOIN scores sc ON p.id = sc.post_id
 		WHERE p.id = ?
 	`, id).Scan(&p.ID, &p.SourceID, &p.SourceName, &p.URL, &p.Title, &p.Author, &p.PublishedAt, &p.FetchedAt,
-		&p.ContentRaw, &p.ContentClean, &p.WordCount, &score)
+		&p.ContentRaw, &p.ContentClean, &p.WordCount, &p.ReadingMinutes, &p.CodeRatio, &p.Headings, &p.Readability,
+		&p.Summary, &score)
 	if err != nil {
 		return nil, err
 	}
@@ -163,10 +289,11 @@ func (r *Repository) GetUnextracted(limit int) ([]Post, error) {
 		       p.content_raw, COALESCE(p.content_clean, '') as content_clean, COALESCE(p.word_count, 0)
 		FROM posts p
 		JOIN sources s ON p.source_id = s.id
-		WHERE p.content_clean IS NULL OR p.content_clean = ''
+		WHERE (p.content_clean IS NULL OR p.content_clean = '')
+		  AND `+runnable("extract")+`
 		ORDER BY p.published_at DESC
 		LIMIT ?
-	`, limit)
+	`, time.Now().UTC(), limit)
 	if err != nil {
 		return nil, err
 	}
@@ -184,10 +311,206 @@ func (r *Repository) GetUnextracted(limit int) ([]Post, error) {
 	return posts, rows.Err()
 }
 
-func (r *Repository) UpdateContentClean(id int64, contentClean string, wordCount int) error {
+// UpdateContentClean stores the cleaned content of a post with its metrics.
+// A summary generated from other content goes stale.
+func (r *Repository) UpdateContentClean(id int64, contentClean string) error {
+	m := Measure(contentClean)
+	_, err := r.db.Exec(`
+		UPDATE posts SET content_clean = ?, word_count = ?, reading_minutes = ?, code_ratio = ?, headings = ?, readability = ?,
+			summary_hash = CASE WHEN summary_hash = ? THEN summary_hash END
+		WHERE id = ?
+	`, contentClean, m.WordCount, m.ReadingMinutes, m.CodeRatio, m.Headings, m.Readability, ContentHash(contentClean), id)
+	return err
+}
+
+// GetUnmeasured returns the posts with cleaned content but no metrics, or
+// every post with cleaned content when all is set
+func (r *Repository) GetUnmeasured(all bool) ([]Post, error) {
+	rows, err := r.db.Query(`
+		SELECT id, title, content_clean
+		FROM posts
+		WHERE COALESCE(content_clean, '') != '' AND (? OR reading_minutes IS NULL)
+		ORDER BY id
+	`, all)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []Post
+	for rows.Next() {
+		var p Post
+		if err := rows.Scan(&p.ID, &p.Title, &p.ContentClean); err != nil {
+			return nil, err
+		}
+		posts = append(posts, p)
+	}
+	return posts, rows.Err()
+}
+
+// UpdateMetrics stores recomputed metrics without touching the content
+func (r *Repository) UpdateMetrics(id int64, m Metrics) error {
+	_, err := r.db.Exec(`
+		UPDATE posts SET word_count = ?, reading_minutes = ?, code_ratio = ?, headings = ?, readability = ?
+		WHERE id = ?
+	`, m.WordCount, m.ReadingMinutes, m.CodeRatio, m.Headings, m.Readability, id)
+	return err
+}
+
+// UpdateContentRaw stores refreshed feed content for an existing post. When the
+// content changed, the summary is marked stale so it gets regenerated, and
+// failed or skipped pipeline jobs get a fresh start.
+func (r *Repository) UpdateContentRaw(url, contentRaw string) (bool, error) {
+	result, err := r.db.Exec(
+		`UPDATE posts SET content_raw = ?, summary_hash = NULL WHERE url = ? AND COALESCE(content_raw, '') != ?`,
+		contentRaw, url, contentRaw,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+
+	_, err = r.db.Exec(`DELETE FROM jobs WHERE post_id IN (SELECT id FROM posts WHERE url = ?)`, url)
+	return true, err
+}
+
+// runnable is the condition on post p that its job for the stage is not
+// skipped, dead-lettered or waiting for a retry; its single argument is the
+// current time. Job states are managed by the job package.
+func runnable(stage string) string {
+	return `NOT EXISTS (
+		SELECT 1 FROM jobs j WHERE j.post_id = p.id AND j.stage = '` + stage + `'
+		  AND (j.status IN ('skipped', 'dead') OR (j.status = 'failed' AND j.next_retry_at > ?))
+	)`
+}
+
+func (r *Repository) UpdateSummary(id int64, summary, contentHash string) error {
 	_, err := r.db.Exec(
-		`UPDATE posts SET content_clean = ?, word_count = ? WHERE id = ?`,
-		contentClean, wordCount, id,
+		`UPDATE posts SET summary = ?, summary_hash = ? WHERE id = ?`,
+		summary, contentHash, id,
 	)
 	return err
 }
+
+// GetStaleSummaries returns extracted posts whose summary is missing or was
+// generated from content that has since changed. The summary hash is cleared
+// when the raw content changes, or the cleaned content no longer matches it.
+func (r *Repository) GetStaleSummaries(limit int) ([]Post, error) {
+	rows, err := r.db.Query(`
+		SELECT p.id, p.source_id, s.name, p.url, p.title, p.author, p.published_at, p.fetched_at,
+		       p.content_raw, p.content_clean, COALESCE(p.word_count, 0), COALESCE(p.summary, '')
+		FROM posts p
+		JOIN sources s ON p.source_id = s.id
+		WHERE p.content_clean IS NOT NULL AND p.content_clean != ''
+		  AND (p.summary_hash IS NULL OR p.summary_hash = '')
+		  AND `+runnable("summary")+`
+		ORDER BY p.published_at DESC
+		LIMIT ?
+	`, time.Now().UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []Post
+	for rows.Next() {
+		var p Post
+		if err := rows.Scan(&p.ID, &p.SourceID, &p.SourceName, &p.URL, &p.Title, &p.Author,
+			&p.PublishedAt, &p.FetchedAt, &p.ContentRaw, &p.ContentClean, &p.WordCount, &p.Summary); err != nil {
+			return nil, err
+		}
+		posts = append(posts, p)
+	}
+	return posts, rows.Err()
+}
+
+// MarkExtracted records which model, prompt version and content an extraction used
+func (r *Repository) MarkExtracted(id int64, model, promptVersion, contentHash string) error {
+	_, err := r.db.Exec(`
+		UPDATE posts SET extracted_at = CURRENT_TIMESTAMP, extract_model = ?, extract_prompt_version = ?, extract_hash = ?
+		WHERE id = ?
+	`, model, promptVersion, contentHash, id)
+	return err
+}
+
+// GetForReextract returns already extracted posts matching all conditions,
+// newest first. A nil since includes posts regardless of publication date, a
+// negative limit returns every match. Posts for which skip reports true, e.g.
+// because they are up to date, are passed over before the limit applies so
+// they don't crowd out older posts; a nil skip keeps every post.
+func (r *Repository) GetForReextract(conditions []ExtractionCondition, since *time.Time, limit int, skip func(Post) bool) ([]Post, error) {
+	if limit < 0 {
+		all, err := r.reextractPage(conditions, since, -1, 0)
+		if err != nil || skip == nil {
+			return all, err
+		}
+		var posts []Post
+		for _, p := range all {
+			if !skip(p) {
+				posts = append(posts, p)
+			}
+		}
+		return posts, nil
+	}
+
+	var posts []Post
+	for offset := 0; len(posts) < limit; offset += limit {
+		page, err := r.reextractPage(conditions, since, limit, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range page {
+			if len(posts) < limit && (skip == nil || !skip(p)) {
+				posts = append(posts, p)
+			}
+		}
+		if len(page) < limit {
+			break
+		}
+	}
+	return posts, nil
+}
+
+func (r *Repository) reextractPage(conditions []ExtractionCondition, since *time.Time, limit, offset int) ([]Post, error) {
+	where := []string{"p.content_clean IS NOT NULL", "p.content_clean != ''"}
+	var args []any
+	for _, c := range conditions {
+		where = append(where, fmt.Sprintf("COALESCE(p.%s, '') %s ?", c.Column, c.Op))
+		args = append(args, c.Value)
+	}
+	if since != nil {
+		where = append(where, "p.published_at >= ?")
+		args = append(args, *since)
+	}
+	args = append(args, limit, offset)
+
+	rows, err := r.db.Query(fmt.Sprintf(`
+		SELECT p.id, p.source_id, s.name, p.url, p.title, p.author, p.published_at, p.fetched_at,
+		       p.content_raw, p.content_clean, COALESCE(p.word_count, 0),
+		       COALESCE(p.extract_model, ''), COALESCE(p.extract_prompt_version, ''), COALESCE(p.extract_hash, '')
+		FROM posts p
+		JOIN sources s ON p.source_id = s.id
+		WHERE %s
+		ORDER BY p.published_at DESC, p.id DESC
+		LIMIT ? OFFSET ?
+	`, strings.Join(where, " AND ")), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []Post
+	for rows.Next() {
+		var p Post
+		if err := rows.Scan(&p.ID, &p.SourceID, &p.SourceName, &p.URL, &p.Title, &p.Author,
+			&p.PublishedAt, &p.FetchedAt, &p.ContentRaw, &p.ContentClean, &p.WordCount,
+			&p.ExtractModel, &p.ExtractPromptVersion, &p.ExtractHash); err != nil {
+			return nil, err
+		}
+		posts = append(posts, p)
+	}
+	return posts, rows.Err()
+}
@@ -2,7 +2,9 @@
 package post
 
 import (
+	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -85,3 +87,214 @@ func TestPostExists(t *testing.T) {
 		t.Error("expected post to not exist")
 	}
 }
+
+func TestSummaryGoesStaleWhenContentChanges(t *testing.T) {
+	db, src := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	p, _ := repo.Add(src.ID, "https://test.com/post1", "Post 1", "Author", time.Now(), "<p>Original</p>")
+	if err := repo.UpdateContentClean(p.ID, "Original"); err != nil {
+		t.Fatalf("failed to update clean content: %v", err)
+	}
+
+	stale, err := repo.GetStaleSummaries(10)
+	if err != nil {
+		t.Fatalf("failed to get stale summaries: %v", err)
+	}
+	if len(stale) != 1 {
+		t.Fatalf("expected unsummarized post to be stale, got %d", len(stale))
+	}
+
+	repo.UpdateSummary(p.ID, "A short summary.", ContentHash("Original"))
+	stale, _ = repo.GetStaleSummaries(10)
+	if len(stale) != 0 {
+		t.Errorf("expected no stale summaries, got %d", len(stale))
+	}
+
+	changed, err := repo.UpdateContentRaw("https://test.com/post1", "<p>Original</p>")
+	if err != nil {
+		t.Fatalf("failed to update content: %v", err)
+	}
+	if changed {
+		t.Error("expected identical content to be a no-op")
+	}
+
+	changed, _ = repo.UpdateContentRaw("https://test.com/post1", "<p>Revised</p>")
+	if !changed {
+		t.Error("expected revised content to be reported as changed")
+	}
+
+	stale, _ = repo.GetStaleSummaries(10)
+	if len(stale) != 1 {
+		t.Errorf("expected summary to be stale after content change, got %d", len(stale))
+	}
+
+	got, _ := repo.Get(p.ID)
+	if got.Summary != "A short summary." {
+		t.Errorf("expected previous summary to be kept until regenerated, got %q", got.Summary)
+	}
+
+	repo.UpdateSummary(p.ID, "A revised summary.", ContentHash("Revised"))
+	repo.UpdateContentClean(p.ID, "Revised")
+	if stale, _ = repo.GetStaleSummaries(10); len(stale) != 0 {
+		t.Errorf("expected summary of the current cleaned content to be fresh, got %d stale", len(stale))
+	}
+
+	repo.UpdateContentClean(p.ID, "Revised, converted again")
+	if stale, _ = repo.GetStaleSummaries(10); len(stale) != 1 {
+		t.Errorf("expected summary to be stale after cleaned content change, got %d", len(stale))
+	}
+}
+
+func TestParseExtractionCondition(t *testing.T) {
+	c, err := ParseExtractionCondition("model!=llama3.2")
+	if err != nil {
+		t.Fatalf("failed to parse condition: %v", err)
+	}
+	if c.Column != "extract_model" || c.Op != "!=" || c.Value != "llama3.2" {
+		t.Errorf("unexpected condition: %+v", c)
+	}
+
+	if _, err := ParseExtractionCondition("author=someone"); err == nil {
+		t.Error("expected error for unknown field")
+	}
+	if _, err := ParseExtractionCondition("model"); err == nil {
+		t.Error("expected error for missing operator")
+	}
+}
+
+func TestGetForReextract(t *testing.T) {
+	db, src := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	old, _ := repo.Add(src.ID, "https://test.com/post1", "Post 1", "Author", time.Now(), "")
+	current, _ := repo.Add(src.ID, "https://test.com/post2", "Post 2", "Author", time.Now(), "")
+	legacy, _ := repo.Add(src.ID, "https://test.com/post3", "Post 3", "Author", time.Now(), "")
+	repo.Add(src.ID, "https://test.com/post4", "Unextracted", "Author", time.Now(), "")
+
+	for _, id := range []int64{old.ID, current.ID, legacy.ID} {
+		repo.UpdateContentClean(id, "content")
+	}
+	repo.MarkExtracted(old.ID, "mistral", "2", ContentHash("content"))
+	repo.MarkExtracted(current.ID, "llama3.2", "2", ContentHash("content"))
+
+	cond, _ := ParseExtractionCondition("model!=llama3.2")
+	posts, err := repo.GetForReextract([]ExtractionCondition{cond}, nil, 10, nil)
+	if err != nil {
+		t.Fatalf("failed to get posts: %v", err)
+	}
+
+	// The mistral post and the legacy post without provenance
+	if len(posts) != 2 {
+		t.Fatalf("expected 2 posts, got %d", len(posts))
+	}
+	for _, p := range posts {
+		if p.ID == current.ID {
+			t.Error("expected post extracted with llama3.2 to be excluded")
+		}
+	}
+
+	future := time.Now().Add(time.Hour)
+	posts, _ = repo.GetForReextract(nil, &future, 10, nil)
+	if len(posts) != 0 {
+		t.Errorf("expected no posts published after now, got %d", len(posts))
+	}
+}
+
+func TestGetForReextractSkipsBeforeLimit(t *testing.T) {
+	db, src := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	// The five newest posts are up to date, the three oldest are not
+	for i := 0; i < 8; i++ {
+		p, _ := repo.Add(src.ID, fmt.Sprintf("https://test.com/post%d", i), fmt.Sprintf("Post %d", i), "Author",
+			time.Now().Add(-time.Duration(i)*time.Hour), "")
+		repo.UpdateContentClean(p.ID, "content")
+		model := "llama3.2"
+		if i >= 5 {
+			model = "mistral"
+		}
+		repo.MarkExtracted(p.ID, model, "2", ContentHash("content"))
+	}
+
+	upToDate := func(p Post) bool { return p.ExtractModel == "llama3.2" }
+	posts, err := repo.GetForReextract(nil, nil, 2, upToDate)
+	if err != nil {
+		t.Fatalf("failed to get posts: %v", err)
+	}
+	if len(posts) != 2 || posts[0].Title != "Post 5" || posts[1].Title != "Post 6" {
+		t.Errorf("expected the two newest outdated posts, got %+v", posts)
+	}
+
+	if posts, _ := repo.GetForReextract(nil, nil, -1, upToDate); len(posts) != 3 {
+		t.Errorf("expected all 3 outdated posts without a limit, got %d", len(posts))
+	}
+}
+
+func TestListSortedByReadingTime(t *testing.T) {
+	db, src := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	long, _ := repo.Add(src.ID, "https://test.com/long", "Long", "Author", time.Now(), "")
+	short, _ := repo.Add(src.ID, "https://test.com/short", "Short", "Author", time.Now().Add(-time.Hour), "")
+	repo.Add(src.ID, "https://test.com/new", "Unmeasured", "Author", time.Now(), "")
+
+	repo.UpdateContentClean(long.ID, strings.Repeat("word ", 1000))
+	repo.UpdateContentClean(short.ID, "A short post.")
+
+	posts, err := repo.ListSorted(10, 0, "reading_time", 0)
+	if err != nil {
+		t.Fatalf("failed to list posts: %v", err)
+	}
+	if len(posts) != 3 || posts[0].ID != short.ID || posts[1].ID != long.ID {
+		t.Fatalf("expected shortest first and unmeasured last, got %+v", posts)
+	}
+	if posts[0].WordCount != 3 {
+		t.Errorf("expected 3 words, got %d", posts[0].WordCount)
+	}
+
+	posts, _ = repo.ListSorted(10, 0, "date", 2)
+	if len(posts) != 1 || posts[0].ID != short.ID {
+		t.Errorf("expected only the short post within 2 minutes, got %+v", posts)
+	}
+
+	unmeasured, _ := repo.GetUnmeasured(false)
+	if len(unmeasured) != 0 {
+		t.Errorf("expected measured posts to be skipped, got %d", len(unmeasured))
+	}
+	all, _ := repo.GetUnmeasured(true)
+	if len(all) != 2 {
+		t.Errorf("expected 2 posts with content, got %d", len(all))
+	}
+}
+
+func TestIDsMentioning(t *testing.T) {
+	db, src := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	rust, _ := repo.Add(src.ID, "https://test.com/rust", "Ownership", "Author", time.Now(), "")
+	repo.UpdateContentClean(rust.ID, "Why RUST borrows")
+	title, _ := repo.Add(src.ID, "https://test.com/go", "Go generics", "Author", time.Now(), "")
+	repo.Add(src.ID, "https://test.com/other", "Gardening", "Author", time.Now(), "")
+
+	ids, err := repo.IDsMentioning([]string{"rust", "Generics"})
+	if err != nil {
+		t.Fatalf("failed to find posts: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != rust.ID || ids[1] != title.ID {
+		t.Errorf("expected posts %d and %d, got %v", rust.ID, title.ID, ids)
+	}
+
+	if ids, _ := repo.IDsMentioning(nil); len(ids) != 0 {
+		t.Errorf("expected no posts without terms, got %v", ids)
+	}
+}
@@ -0,0 +1,222 @@
+package prompt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Template names. Custom templates are read from <dir>/<name>.tmpl.
+const (
+	Extraction = "extract"
+	Summary    = "summary"
+	Answer     = "ask"
+	Relation   = "relate"
+	Flashcard  = "card"
+)
+
+// Data is passed to every template. Post prompts use Title and Content, the
+// answer prompt uses Question and Sources, the relate prompt uses Sources
+// for the two posts, oldest first, and the card prompt uses Title for the
+// post and Content for the takeaway.
+type Data struct {
+	Title    string
+	Content  string
+	Question string
+	Sources  []Source
+}
+
+// Source is a numbered passage an answer may cite
+type Source struct {
+	Number int
+	Title  string
+	URL    string
+	Text   string
+}
+
+type builtin struct {
+	text    string
+	version string
+}
+
+// Bump a version whenever its built-in text changes so old extractions can
+// be selected for a redo
+var builtins = map[string]builtin{
+	Extraction: {version: "4", text: `Analyze this blog post and extract structured information.
+
+Title: {{.Title}}
+
+Content:
+{{.Content}}
+
+Return a JSON object with:
+1. "summary": A one-paragraph TL;DR (2-4 sentences) of the whole post
+2. "takeaways": Array of 3-5 key insights (short sentences)
+3. "references": Array of objects with "url", "title", "context" for any links mentioned
+4. "topics": Array of 2-4 topic tags (e.g., "golang", "distributed-systems", "performance")
+5. "quotes": Array of objects with "text" and "attribution" for notable quotes (attribution is who said it)
+6. "definitions": Array of objects with "term" and "definition" for technical terms the post explains
+7. "code_examples": Array of objects with "language" and "code" for the most instructive code snippets
+8. "entities": Array of objects with "name", "type" and "aliases" for the people, projects, libraries and companies the post discusses. "type" is one of "person", "project", "library", "company"; "name" is the canonical name (e.g. "PostgreSQL") and "aliases" lists other names the post uses for it (e.g. ["Postgres"])
+
+Use an empty array when the post has nothing for a field.
+Return ONLY valid JSON, no other text.`},
+	Summary: {version: "1", text: `Write a one-paragraph TL;DR (2-4 sentences) of this blog post for a developer deciding whether to read it.
+
+Title: {{.Title}}
+
+Content:
+{{.Content}}
+
+Return ONLY the summary paragraph, no preamble.`},
+	Answer: {version: "1", text: `Answer the question using ONLY the numbered sources below, which are excerpts from blog posts.
+Cite the sources supporting each statement inline as [1], [2], etc.
+If the sources do not answer the question, say so instead of guessing.
+
+Question: {{.Question}}
+
+Sources:
+{{range .Sources}}
+[{{.Number}}] {{.Title}}
+{{.Text}}
+{{end}}
+Answer:`},
+	Relation: {version: "1", text: `Classify how post 2 relates to post 1. Post 1 was published first.
+{{range .Sources}}
+Post {{.Number}}: {{.Title}}
+{{.Text}}
+{{end}}
+Choose exactly one relationship:
+- "agrees": post 2 makes the same argument or reaches the same conclusion as post 1
+- "contradicts": post 2 disagrees with post 1 or reaches the opposite conclusion
+- "extends": post 2 builds on the ideas of post 1 and takes them further
+- "responds-to": post 2 is a direct reply or reaction to post 1
+- "same-topic": both cover the same subject without engaging with each other's claims
+
+Return a JSON object with "relationship" (one of the values above) and "rationale" (one sentence explaining the choice).
+Return ONLY valid JSON, no other text.`},
+	Flashcard: {version: "1", text: `Turn this takeaway from a blog post into a flashcard for spaced-repetition review.
+
+Post: {{.Title}}
+
+Takeaway: {{.Content}}
+
+Write a question that can only be answered by recalling the takeaway, without giving the answer away, and a short answer (one sentence).
+Return a JSON object with "question" and "answer".
+Return ONLY valid JSON, no other text.`},
+}
+
+type entry struct {
+	tmpl    *template.Template
+	text    string
+	version string
+	path    string // empty for built-in templates
+}
+
+// Set holds the templates in effect: built-ins overridden by custom files
+type Set struct {
+	entries map[string]entry
+}
+
+// Dir returns the directory custom templates are loaded from
+func Dir(home string) string {
+	return filepath.Join(home, "prompts")
+}
+
+// Default returns the built-in templates
+func Default() *Set {
+	s := &Set{entries: make(map[string]entry)}
+	for name, b := range builtins {
+		s.entries[name] = entry{
+			tmpl:    template.Must(template.New(name).Parse(b.text)),
+			text:    b.text,
+			version: b.version,
+		}
+	}
+	return s
+}
+
+// Load returns the built-in templates with any <name>.tmpl in dir taking
+// precedence. A missing directory is not an error.
+func Load(dir string) (*Set, error) {
+	s := Default()
+	for name := range builtins {
+		path := filepath.Join(dir, name+".tmpl")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		text := string(data)
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prompt template %s: %w", path, err)
+		}
+
+		// Catch references to fields that don't exist before any LLM call
+		if err := tmpl.Execute(&strings.Builder{}, Data{}); err != nil {
+			return nil, fmt.Errorf("invalid prompt template %s: %w", path, err)
+		}
+
+		// An unedited copy of a built-in keeps its version
+		version := builtins[name].version
+		if text != builtins[name].text {
+			sum := sha256.Sum256(data)
+			version = "custom-" + hex.EncodeToString(sum[:])[:8]
+		}
+
+		s.entries[name] = entry{
+			tmpl:    tmpl,
+			text:    text,
+			version: version,
+			path:    path,
+		}
+	}
+	return s, nil
+}
+
+func (s *Set) Render(name string, data Data) (string, error) {
+	e, ok := s.entries[name]
+	if !ok {
+		return "", fmt.Errorf("unknown prompt template: %s", name)
+	}
+
+	var b strings.Builder
+	if err := e.tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %w", name, err)
+	}
+	return b.String(), nil
+}
+
+// Version identifies the template text, so results can record which prompt produced them
+func (s *Set) Version(name string) string {
+	return s.entries[name].version
+}
+
+// Path returns the file a custom template was loaded from, or "" for a built-in
+func (s *Set) Path(name string) string {
+	return s.entries[name].path
+}
+
+// Names lists the known templates in alphabetical order
+func Names() []string {
+	var names []string
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Builtin returns the default text of a template
+func Builtin(name string) string {
+	return builtins[name].text
+}
@@ -0,0 +1,78 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultRender(t *testing.T) {
+	s := Default()
+
+	out, err := s.Render(Extraction, Data{Title: "Test Title", Content: "Test content"})
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+
+	if !strings.Contains(out, "Test Title") || !strings.Contains(out, "Test content") {
+		t.Error("expected title and content in rendered prompt")
+	}
+	if s.Version(Extraction) != builtins[Extraction].version {
+		t.Errorf("expected built-in version, got %s", s.Version(Extraction))
+	}
+}
+
+func TestLoadCustomTemplate(t *testing.T) {
+	dir := t.TempDir()
+	custom := "List operational lessons from {{.Title}}:\n{{.Content}}"
+	if err := os.WriteFile(filepath.Join(dir, Extraction+".tmpl"), []byte(custom), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	out, _ := s.Render(Extraction, Data{Title: "Outage", Content: "body"})
+	if out != "List operational lessons from Outage:\nbody" {
+		t.Errorf("unexpected render: %q", out)
+	}
+	if !strings.HasPrefix(s.Version(Extraction), "custom-") {
+		t.Errorf("expected custom version, got %s", s.Version(Extraction))
+	}
+
+	// Templates without a custom file keep the built-in
+	if s.Path(Summary) != "" {
+		t.Error("expected summary template to be built-in")
+	}
+}
+
+func TestLoadMissingDir(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing")); err != nil {
+		t.Errorf("expected missing directory to fall back to defaults, got %v", err)
+	}
+}
+
+func TestLoadRejectsUnknownField(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, Summary+".tmpl"), []byte("{{.Author}}"), 0644)
+
+	if _, err := Load(dir); err == nil {
+		t.Error("expected error for template referencing unknown field")
+	}
+}
+
+func TestLoadUneditedCopyKeepsVersion(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, Summary+".tmpl"), []byte(Builtin(Summary)), 0644)
+
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+	if s.Version(Summary) != builtins[Summary].version {
+		t.Errorf("expected built-in version for unedited copy, got %s", s.Version(Summary))
+	}
+}
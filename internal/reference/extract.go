@@ -0,0 +1,190 @@
+package reference
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/julienpequegnot/blogmon/internal/markdown"
+)
+
+// Reference origins
+const (
+	OriginHTML = "html" // anchor found in the post content
+	OriginLLM  = "llm"  // only reported by the LLM
+	OriginBoth = "both" // anchor that the LLM also reported
+)
+
+// Elements whose links are site chrome rather than citations
+var boilerplateSelector = "nav, header, footer, aside, " +
+	"[class*=nav], [class*=menu], [class*=footer], [class*=sidebar], [class*=share], [class*=comment], " +
+	"[id*=nav], [id*=menu], [id*=footer], [id*=sidebar], [id*=comment]"
+
+var blockSelector = "p, li, blockquote, dd, td, figcaption, h1, h2, h3, h4, h5, h6"
+
+var sentenceEnd = regexp.MustCompile(`[.!?]["')\]]?\s+`)
+
+// ExtractLinks returns every outbound link in the post HTML: links to other
+// sites, with relative URLs resolved against postURL. Anchor text becomes the
+// title and the sentence around the link becomes the context.
+func ExtractLinks(html, postURL string) []Reference {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil
+	}
+
+	base, err := url.Parse(postURL)
+	if err != nil {
+		return nil
+	}
+	site := normalizeHost(base.Host)
+	seen := make(map[string]bool)
+	var refs []Reference
+
+	doc.Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+		if a.Closest(boilerplateSelector).Length() > 0 {
+			return
+		}
+		if rel, _ := a.Attr("rel"); strings.Contains(rel, "tag") {
+			return
+		}
+
+		href, _ := a.Attr("href")
+		resolved, ok := resolveURL(base, href)
+		if !ok {
+			return
+		}
+
+		// Links within the blog, such as the previous post, are not references
+		if u, err := url.Parse(resolved); err != nil || normalizeHost(u.Host) == site {
+			return
+		}
+
+		key := NormalizeURL(resolved)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+
+		title := collapseSpace(a.Text())
+		refs = append(refs, Reference{
+			URL:     resolved,
+			Title:   title,
+			Context: sentenceAround(a, title),
+			Origin:  OriginHTML,
+		})
+	})
+
+	return refs
+}
+
+// MergeLinks combines anchors found in the HTML with references reported by
+// the LLM. Anchors win on conflicts and are marked OriginBoth when the LLM
+// reported them too; LLM references without a matching anchor keep OriginLLM.
+func MergeLinks(anchors, llmRefs []Reference) []Reference {
+	index := make(map[string]int)
+	merged := make([]Reference, 0, len(anchors)+len(llmRefs))
+
+	for _, ref := range anchors {
+		index[NormalizeURL(ref.URL)] = len(merged)
+		ref.Origin = OriginHTML
+		merged = append(merged, ref)
+	}
+
+	for _, ref := range llmRefs {
+		if ref.URL == "" {
+			continue
+		}
+
+		key := NormalizeURL(ref.URL)
+		if i, ok := index[key]; ok {
+			merged[i].Origin = OriginBoth
+			if merged[i].Title == "" {
+				merged[i].Title = ref.Title
+			}
+			continue
+		}
+
+		index[key] = len(merged)
+		ref.Origin = OriginLLM
+		merged = append(merged, ref)
+	}
+
+	return merged
+}
+
+// NormalizeURL reduces a URL to a form suitable for deduplication
+func NormalizeURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return raw
+	}
+	u.Fragment = ""
+	u.Host = normalizeHost(u.Host)
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme == "http" {
+		u.Scheme = "https"
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return u.String()
+}
+
+// normalizeHost makes hosts differing only by case or a www. prefix equal
+func normalizeHost(host string) string {
+	return strings.TrimPrefix(strings.ToLower(host), "www.")
+}
+
+func resolveURL(base *url.URL, href string) (string, bool) {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") {
+		return "", false
+	}
+
+	ref, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+	if base != nil {
+		ref = base.ResolveReference(ref)
+	}
+	if ref.Scheme != "http" && ref.Scheme != "https" {
+		return "", false
+	}
+
+	ref.Fragment = ""
+	return ref.String(), true
+}
+
+// sentenceAround returns the sentence of the enclosing block that contains
+// the anchor text, falling back to the start of the block
+func sentenceAround(a *goquery.Selection, anchorText string) string {
+	block := a.Closest(blockSelector)
+	if block.Length() == 0 {
+		block = a.Parent()
+	}
+
+	text := collapseSpace(block.Text())
+	if anchorText != "" {
+		if i := strings.Index(text, anchorText); i >= 0 {
+			start := 0
+			for _, loc := range sentenceEnd.FindAllStringIndex(text[:i], -1) {
+				start = loc[1]
+			}
+			end := len(text)
+			if loc := sentenceEnd.FindStringIndex(text[i+len(anchorText):]); loc != nil {
+				end = i + len(anchorText) + loc[0] + 1
+			}
+			text = text[start:end]
+		}
+	}
+
+	if len(text) > 300 {
+		text = markdown.TruncateBytes(text, 297) + "..."
+	}
+	return text
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,63 @@
+package reference
+
+import (
+	"testing"
+)
+
+const samplePost = `
+<nav><a href="/about">About</a></nav>
+<article>
+  <p>Queues are everywhere. As <a href="https://brooker.co.za/blog/2023/01/queues.html">Marc Brooker explains</a>, latency grows fast near saturation. See also the <a href="../notes/littles-law">notes on Little's law</a> and <a href="https://lamport.azurewebsites.net/pubs/time-clocks.pdf">Lamport's paper</a>.</p>
+  <p>Previously: <a href="https://www.example.com/posts/backpressure/">backpressure</a>.</p>
+  <p><a href="#footnote-1">1</a> <a href="mailto:me@example.com">Email me</a></p>
+  <p>Tagged <a rel="tag" href="/tags/queues">queues</a>.</p>
+</article>
+<footer><a href="https://twitter.com/someone">Twitter</a></footer>
+`
+
+func TestExtractLinks(t *testing.T) {
+	refs := ExtractLinks(samplePost, "https://example.com/posts/queues/")
+
+	// Links to the blog itself are left out, relative or not
+	if len(refs) != 2 {
+		t.Fatalf("expected 2 outbound links, got %d: %+v", len(refs), refs)
+	}
+
+	brooker := refs[0]
+	if brooker.URL != "https://brooker.co.za/blog/2023/01/queues.html" {
+		t.Errorf("unexpected URL: %s", brooker.URL)
+	}
+	if brooker.Title != "Marc Brooker explains" {
+		t.Errorf("expected anchor text as title, got %q", brooker.Title)
+	}
+	if brooker.Context != "As Marc Brooker explains, latency grows fast near saturation." {
+		t.Errorf("expected surrounding sentence as context, got %q", brooker.Context)
+	}
+	if brooker.Origin != OriginHTML {
+		t.Errorf("expected html origin, got %s", brooker.Origin)
+	}
+
+	if refs[1].URL != "https://lamport.azurewebsites.net/pubs/time-clocks.pdf" {
+		t.Errorf("unexpected URL: %s", refs[1].URL)
+	}
+}
+
+func TestMergeLinks(t *testing.T) {
+	anchors := []Reference{{URL: "https://brooker.co.za/blog/queues.html", Title: "Brooker"}}
+	llmRefs := []Reference{
+		{URL: "http://www.brooker.co.za/blog/queues.html#intro", Title: "Queues"},
+		{URL: "https://made-up.example/paper", Title: "A paper"},
+	}
+
+	merged := MergeLinks(anchors, llmRefs)
+
+	if len(merged) != 2 {
+		t.Fatalf("expected 2 references, got %d", len(merged))
+	}
+	if merged[0].Origin != OriginBoth || merged[0].Title != "Brooker" {
+		t.Errorf("expected anchor confirmed by LLM, got %+v", merged[0])
+	}
+	if merged[1].Origin != OriginLLM {
+		t.Errorf("expected LLM-only origin, got %s", merged[1].Origin)
+	}
+}
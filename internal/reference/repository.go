@@ -1,6 +1,7 @@
 package reference
 
 import (
+	"database/sql"
 	"fmt"
 
 	"github.com/julienpequegnot/blogmon/internal/database"
@@ -13,6 +14,7 @@ type Reference struct {
 	Title   string
 	Context string
 	IsBlog  bool
+	Origin  string // "html", "llm", or "both"
 }
 
 type Repository struct {
@@ -24,9 +26,13 @@ func NewRepository(db *database.DB) *Repository {
 }
 
 func (r *Repository) Add(postID int64, url, title, context string, isBlog bool) (*Reference, error) {
+	return r.AddWithOrigin(postID, url, title, context, isBlog, OriginLLM)
+}
+
+func (r *Repository) AddWithOrigin(postID int64, url, title, context string, isBlog bool, origin string) (*Reference, error) {
 	result, err := r.db.Exec(
-		`INSERT INTO refs (post_id, url, title, context, is_blog) VALUES (?, ?, ?, ?, ?)`,
-		postID, url, title, context, isBlog,
+		`INSERT INTO refs (post_id, url, title, context, is_blog, origin) VALUES (?, ?, ?, ?, ?, ?)`,
+		postID, url, title, context, isBlog, origin,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert reference: %w", err)
@@ -44,12 +50,13 @@ func (r *Repository) Add(postID int64, url, title, context string, isBlog bool)
 		Title:   title,
 		Context: context,
 		IsBlog:  isBlog,
+		Origin:  origin,
 	}, nil
 }
 
 func (r *Repository) ListForPost(postID int64) ([]Reference, error) {
 	rows, err := r.db.Query(
-		`SELECT id, post_id, url, title, context, is_blog FROM refs WHERE post_id = ?`,
+		`SELECT id, post_id, url, title, context, is_blog, COALESCE(origin, 'llm') FROM refs WHERE post_id = ?`,
 		postID,
 	)
 	if err != nil {
@@ -57,15 +64,7 @@ func (r *Repository) ListForPost(postID int64) ([]Reference, error) {
 	}
 	defer rows.Close()
 
-	var refs []Reference
-	for rows.Next() {
-		var ref Reference
-		if err := rows.Scan(&ref.ID, &ref.PostID, &ref.URL, &ref.Title, &ref.Context, &ref.IsBlog); err != nil {
-			return nil, err
-		}
-		refs = append(refs, ref)
-	}
-	return refs, rows.Err()
+	return scanReferences(rows)
 }
 
 func (r *Repository) DeleteForPost(postID int64) error {
@@ -74,16 +73,35 @@ func (r *Repository) DeleteForPost(postID int64) error {
 }
 
 func (r *Repository) ListBlogReferences() ([]Reference, error) {
-	rows, err := r.db.Query(`SELECT id, post_id, url, title, context, is_blog FROM refs WHERE is_blog = TRUE`)
+	rows, err := r.db.Query(`SELECT id, post_id, url, title, context, is_blog, COALESCE(origin, 'llm') FROM refs WHERE is_blog = TRUE`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	return scanReferences(rows)
+}
+
+// ListCitedBlogReferences returns blog references backed by an actual link
+// in the post content, excluding those only reported by the LLM
+func (r *Repository) ListCitedBlogReferences() ([]Reference, error) {
+	rows, err := r.db.Query(`
+		SELECT id, post_id, url, title, context, is_blog, COALESCE(origin, 'llm')
+		FROM refs WHERE is_blog = TRUE AND COALESCE(origin, 'llm') != 'llm'
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanReferences(rows)
+}
+
+func scanReferences(rows *sql.Rows) ([]Reference, error) {
 	var refs []Reference
 	for rows.Next() {
 		var ref Reference
-		if err := rows.Scan(&ref.ID, &ref.PostID, &ref.URL, &ref.Title, &ref.Context, &ref.IsBlog); err != nil {
+		if err := rows.Scan(&ref.ID, &ref.PostID, &ref.URL, &ref.Title, &ref.Context, &ref.IsBlog, &ref.Origin); err != nil {
 			return nil, err
 		}
 		refs = append(refs, ref)
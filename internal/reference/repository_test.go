@@ -63,3 +63,27 @@ func TestListReferencesForPost(t *testing.T) {
 		t.Errorf("expected 2 references, got %d", len(refs))
 	}
 }
+
+func TestListCitedBlogReferences(t *testing.T) {
+	db, postID := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	repo.AddWithOrigin(postID, "https://cited.blog/post", "Cited", "", true, OriginHTML)
+	repo.AddWithOrigin(postID, "https://both.blog/post", "Both", "", true, OriginBoth)
+	repo.Add(postID, "https://maybe-hallucinated.blog/post", "LLM only", "", true)
+
+	all, _ := repo.ListBlogReferences()
+	if len(all) != 3 {
+		t.Errorf("expected 3 blog references, got %d", len(all))
+	}
+
+	cited, err := repo.ListCitedBlogReferences()
+	if err != nil {
+		t.Fatalf("failed to list references: %v", err)
+	}
+	if len(cited) != 2 {
+		t.Errorf("expected 2 cited references, got %d", len(cited))
+	}
+}
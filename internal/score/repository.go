@@ -13,6 +13,10 @@ type Score struct {
 	NoveltyScore   float64
 	FinalScore     float64
 	ScoredAt       time.Time
+
+	// Publication time of the post, or its fetch time when the feed had
+	// none. Only set by All.
+	PostedAt time.Time
 }
 
 type Repository struct {
@@ -37,6 +41,46 @@ func (r *Repository) Upsert(postID int64, community, relevance, novelty, final f
 	return err
 }
 
+// UpdateCommunity replaces the community and final scores of a scored post,
+// keeping its relevance and novelty
+func (r *Repository) UpdateCommunity(postID int64, community, final float64) error {
+	_, err := r.db.Exec(`
+		UPDATE scores SET community_score = ?, final_score = ?, scored_at = CURRENT_TIMESTAMP
+		WHERE post_id = ?
+	`, community, final, postID)
+	return err
+}
+
+// All returns the scores of every scored post
+func (r *Repository) All() ([]Score, error) {
+	rows, err := r.db.Query(`
+		SELECT s.post_id, s.community_score, s.relevance_score, s.novelty_score, s.final_score, s.scored_at,
+		       p.published_at, p.fetched_at
+		FROM scores s
+		JOIN posts p ON s.post_id = p.id
+		ORDER BY s.post_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scores []Score
+	for rows.Next() {
+		var s Score
+		var publishedAt *time.Time
+		if err := rows.Scan(&s.PostID, &s.CommunityScore, &s.RelevanceScore, &s.NoveltyScore, &s.FinalScore, &s.ScoredAt,
+			&publishedAt, &s.PostedAt); err != nil {
+			return nil, err
+		}
+		if publishedAt != nil {
+			s.PostedAt = *publishedAt
+		}
+		scores = append(scores, s)
+	}
+	return scores, rows.Err()
+}
+
 func (r *Repository) Get(postID int64) (*Score, error) {
 	var s Score
 	err := r.db.QueryRow(`
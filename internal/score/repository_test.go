@@ -62,3 +62,27 @@ func TestGetUnscored(t *testing.T) {
 		t.Errorf("expected 1 unscored post, got %d", len(unscored))
 	}
 }
+
+func TestAllIncludesPostTime(t *testing.T) {
+	db, postID := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	repo.Upsert(postID, 10, 20, 30, 21)
+	repo.UpdateCommunity(postID, 40, 30)
+
+	scores, err := repo.All()
+	if err != nil {
+		t.Fatalf("failed to list scores: %v", err)
+	}
+	if len(scores) != 1 {
+		t.Fatalf("expected 1 score, got %d", len(scores))
+	}
+	s := scores[0]
+	if s.CommunityScore != 40 || s.RelevanceScore != 20 || s.FinalScore != 30 {
+		t.Errorf("unexpected score: %+v", s)
+	}
+	if time.Since(s.PostedAt) > time.Minute {
+		t.Errorf("expected the publication time of the post, got %v", s.PostedAt)
+	}
+}
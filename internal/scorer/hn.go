@@ -6,6 +6,7 @@ import (
 	"math"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -22,20 +23,38 @@ type HNHit struct {
 }
 
 type HNScorer struct {
-	client *http.Client
+	client  *http.Client
+	baseURL string
 }
 
-func NewHNScorer() *HNScorer {
+// NewHNScorer queries the HN Algolia API at baseURL, e.g. https://hn.algolia.com
+func NewHNScorer(baseURL string) *HNScorer {
 	return &HNScorer{
-		client: &http.Client{Timeout: 10 * time.Second},
+		client:  &http.Client{Timeout: 10 * time.Second},
+		baseURL: strings.TrimRight(baseURL, "/"),
 	}
 }
 
+func (s *HNScorer) Name() string {
+	return ProviderHN
+}
+
+// Lookup returns the points and comments of the best HN submission of a URL
+func (s *HNScorer) Lookup(postURL string) (Signal, error) {
+	signal := Signal{Provider: ProviderHN}
+	hit, err := s.SearchByURL(postURL)
+	if err != nil || hit == nil {
+		return signal, err
+	}
+	signal.Points, signal.Comments, signal.Submissions = hit.Points, hit.NumComments, 1
+	return signal, nil
+}
+
 func (s *HNScorer) SearchByURL(postURL string) (*HNHit, error) {
 	// HN Algolia API
 	searchURL := fmt.Sprintf(
-		"https://hn.algolia.com/api/v1/search?query=%s&restrictSearchableAttributes=url",
-		url.QueryEscape(postURL),
+		"%s/api/v1/search?query=%s&restrictSearchableAttributes=url",
+		s.baseURL, url.QueryEscape(postURL),
 	)
 
 	resp, err := s.client.Get(searchURL)
@@ -69,14 +88,17 @@ func (s *HNScorer) SearchByURL(postURL string) (*HNHit, error) {
 }
 
 func CalculateCommunityScore(hnPoints, hnComments, redditScore int) float64 {
-	if hnPoints == 0 && hnComments == 0 && redditScore == 0 {
+	return communityScore(hnPoints*2 + hnComments*3 + redditScore)
+}
+
+func communityScore(raw int) float64 {
+	if raw <= 0 {
 		return 0
 	}
 
 	// Weighted log scale to prevent viral posts from dominating
 	// Formula: log(1 + hn_points*2 + hn_comments*3 + reddit_score) * 10
-	rawScore := float64(hnPoints*2 + hnComments*3 + redditScore)
-	score := math.Log(1+rawScore) * 10
+	score := math.Log(1+float64(raw)) * 10
 
 	// Cap at 100
 	if score > 100 {
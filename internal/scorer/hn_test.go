@@ -1,6 +1,8 @@
 package scorer
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -23,3 +25,36 @@ func TestCalculateCommunityScoreZero(t *testing.T) {
 		t.Errorf("expected 0 score for no signals, got %f", score)
 	}
 }
+
+func TestCommunityScoreFromSignals(t *testing.T) {
+	signals := []Signal{
+		{Provider: ProviderHN, Points: 100, Comments: 50},
+		{Provider: ProviderReddit, Points: 40, Comments: 10},
+	}
+	if got, want := CommunityScoreFromSignals(signals), CalculateCommunityScore(100, 50, 50); got != want {
+		t.Errorf("expected signals to match CalculateCommunityScore, got %f want %f", got, want)
+	}
+
+	withLobsters := append(signals, Signal{Provider: ProviderLobsters, Points: 10, Comments: 5})
+	if CommunityScoreFromSignals(withLobsters) <= CommunityScoreFromSignals(signals) {
+		t.Error("expected Lobsters signals to raise the score")
+	}
+	if CommunityScoreFromSignals(nil) != 0 {
+		t.Error("expected 0 without signals")
+	}
+}
+
+func TestHNLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"hits": [{"objectID": "1", "points": 10, "num_comments": 2}, {"objectID": "2", "points": 90, "num_comments": 40}]}`))
+	}))
+	defer server.Close()
+
+	signal, err := NewHNScorer(server.URL).Lookup("https://blog.test/post")
+	if err != nil {
+		t.Fatalf("lookup failed: %v", err)
+	}
+	if signal != (Signal{Provider: ProviderHN, Points: 90, Comments: 40, Submissions: 1}) {
+		t.Errorf("expected the best hit, got %+v", signal)
+	}
+}
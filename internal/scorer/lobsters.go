@@ -0,0 +1,74 @@
+package scorer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type LobstersStory struct {
+	ShortID      string `json:"short_id"`
+	Title        string `json:"title"`
+	URL          string `json:"url"`
+	Score        int    `json:"score"`
+	CommentCount int    `json:"comment_count"`
+}
+
+type LobstersScorer struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewLobstersScorer queries Lobsters at baseURL, e.g. https://lobste.rs
+func NewLobstersScorer(baseURL string) *LobstersScorer {
+	return &LobstersScorer{
+		client:  &http.Client{Timeout: 10 * time.Second},
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (s *LobstersScorer) Name() string {
+	return ProviderLobsters
+}
+
+// Lookup returns the summed score and comments of a URL's Lobsters stories
+func (s *LobstersScorer) Lookup(postURL string) (Signal, error) {
+	signal := Signal{Provider: ProviderLobsters}
+	stories, err := s.SearchByURL(postURL)
+	if err != nil {
+		return signal, err
+	}
+	for _, story := range stories {
+		signal.Points += story.Score
+		signal.Comments += story.CommentCount
+	}
+	signal.Submissions = len(stories)
+	return signal, nil
+}
+
+// SearchByURL returns the stories submitted with a URL
+func (s *LobstersScorer) SearchByURL(postURL string) ([]LobstersStory, error) {
+	searchURL := fmt.Sprintf("%s/stories/url/all.json?url=%s", s.baseURL, url.QueryEscape(postURL))
+
+	resp, err := s.client.Get(searchURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Lobsters API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil // Not found on Lobsters
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Lobsters API returned %d", resp.StatusCode)
+	}
+
+	var stories []LobstersStory
+	if err := json.NewDecoder(resp.Body).Decode(&stories); err != nil {
+		return nil, fmt.Errorf("failed to decode Lobsters response: %w", err)
+	}
+	return stories, nil
+}
@@ -0,0 +1,37 @@
+package scorer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLobstersLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stories/url/all.json" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("url") != "https://blog.test/post" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(`[{"short_id": "abc", "score": 25, "comment_count": 8, "url": "https://blog.test/post"},
+			{"short_id": "def", "score": 3, "comment_count": 0, "url": "https://blog.test/post"}]`))
+	}))
+	defer server.Close()
+
+	var provider CommunitySignal = NewLobstersScorer(server.URL)
+	signal, err := provider.Lookup("https://blog.test/post")
+	if err != nil {
+		t.Fatalf("lookup failed: %v", err)
+	}
+	if signal != (Signal{Provider: ProviderLobsters, Points: 28, Comments: 8, Submissions: 2}) {
+		t.Errorf("unexpected signal: %+v", signal)
+	}
+
+	signal, err = provider.Lookup("https://blog.test/other")
+	if err != nil || signal.Submissions != 0 || signal.Provider != ProviderLobsters {
+		t.Errorf("expected an empty signal, got %+v (%v)", signal, err)
+	}
+}
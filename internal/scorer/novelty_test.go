@@ -4,29 +4,21 @@ import (
 	"testing"
 )
 
-func TestNoveltyScorer(t *testing.T) {
-	scorer := NewNoveltyScorer()
+func TestCosineSimilarity(t *testing.T) {
+	idf := func(string) float64 { return 1 }
+	golang := TermFrequencies("golang programming concurrency")
 
-	// Add some existing content
-	scorer.AddDocument(1, "golang programming concurrency")
-	scorer.AddDocument(2, "rust memory safety ownership")
+	// Similar content should be closer than different content
+	similar := CosineSimilarity(golang, TermFrequencies("golang programming goroutines concurrency"), idf)
+	different := CosineSimilarity(golang, TermFrequencies("machine learning neural networks tensorflow"), idf)
 
-	// Similar content should have low novelty
-	similarScore := scorer.Score("golang programming goroutines concurrency")
-
-	// Different content should have high novelty
-	differentScore := scorer.Score("machine learning neural networks tensorflow")
-
-	if similarScore >= differentScore {
-		t.Errorf("expected similar score (%f) < different score (%f)", similarScore, differentScore)
+	if similar <= different {
+		t.Errorf("expected similar content (%f) > different content (%f)", similar, different)
 	}
 }
 
-func TestNoveltyEmptyCorpus(t *testing.T) {
-	scorer := NewNoveltyScorer()
-	score := scorer.Score("any content here")
-
-	if score != 100.0 {
-		t.Errorf("expected 100 novelty for empty corpus, got %f", score)
+func TestTermFrequenciesEmpty(t *testing.T) {
+	if tf := TermFrequencies("a an of"); tf != nil {
+		t.Errorf("expected no terms from short words, got %v", tf)
 	}
 }
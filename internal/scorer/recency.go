@@ -0,0 +1,15 @@
+package scorer
+
+import (
+	"math"
+	"time"
+)
+
+// RecencyFactor is the share of a score a post keeps at the given age when
+// scores halve every halfLifeDays. A half-life of 0 disables decay.
+func RecencyFactor(age time.Duration, halfLifeDays float64) float64 {
+	if halfLifeDays <= 0 || age <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, age.Hours()/24/halfLifeDays)
+}
@@ -0,0 +1,28 @@
+package scorer
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRecencyFactor(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		age      time.Duration
+		halfLife float64
+		want     float64
+	}{
+		{30 * day, 0, 1},
+		{-day, 30, 1},
+		{0, 30, 1},
+		{30 * day, 30, 0.5},
+		{60 * day, 30, 0.25},
+		{15 * day, 30, math.Sqrt(0.5)},
+	}
+	for _, tt := range tests {
+		if got := RecencyFactor(tt.age, tt.halfLife); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("RecencyFactor(%v, %v) = %f, want %f", tt.age, tt.halfLife, got, tt.want)
+		}
+	}
+}
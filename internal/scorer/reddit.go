@@ -0,0 +1,160 @@
+package scorer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RedditListing is the envelope of Reddit's JSON listings
+type RedditListing struct {
+	Data struct {
+		Children []struct {
+			Kind string           `json:"kind"`
+			Data RedditSubmission `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
+
+type RedditSubmission struct {
+	ID          string `json:"id"`
+	Subreddit   string `json:"subreddit"`
+	Title       string `json:"title"`
+	Permalink   string `json:"permalink"`
+	URL         string `json:"url"`
+	Score       int    `json:"score"`
+	NumComments int    `json:"num_comments"`
+}
+
+// RedditResult sums the submissions of a URL
+type RedditResult struct {
+	Submissions []RedditSubmission
+	Score       int
+	Comments    int
+}
+
+// Total is the value passed as redditScore to CalculateCommunityScore
+func (r *RedditResult) Total() int {
+	return r.Score + r.Comments
+}
+
+type RedditScorer struct {
+	client     *http.Client
+	baseURL    string
+	subreddits []string
+	userAgent  string
+}
+
+// NewRedditScorer queries the Reddit API at baseURL, e.g.
+// https://www.reddit.com. Reddit rejects requests without a user agent.
+func NewRedditScorer(baseURL string, subreddits []string, userAgent string) *RedditScorer {
+	return &RedditScorer{
+		client:     &http.Client{Timeout: 10 * time.Second},
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		subreddits: subreddits,
+		userAgent:  userAgent,
+	}
+}
+
+func (s *RedditScorer) Name() string {
+	return ProviderReddit
+}
+
+// Lookup returns the summed score and comments of a URL's Reddit submissions
+func (s *RedditScorer) Lookup(postURL string) (Signal, error) {
+	signal := Signal{Provider: ProviderReddit}
+	result, err := s.SearchByURL(postURL)
+	if err != nil || result == nil {
+		return signal, err
+	}
+	signal.Points, signal.Comments, signal.Submissions = result.Score, result.Comments, len(result.Submissions)
+	return signal, nil
+}
+
+// SearchByURL finds the submissions of a post across Reddit with
+// /api/info, which only matches the exact URL, then falls back to searching
+// the configured subreddits for variants of it. It returns nil when the post
+// was never submitted.
+func (s *RedditScorer) SearchByURL(postURL string) (*RedditResult, error) {
+	submissions, err := s.listing(fmt.Sprintf("%s/api/info.json?url=%s&limit=100", s.baseURL, url.QueryEscape(postURL)))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(submissions) == 0 && len(s.subreddits) > 0 {
+		searchURL := fmt.Sprintf("%s/r/%s/search.json?q=%s&restrict_sr=on&limit=100",
+			s.baseURL, strings.Join(s.subreddits, "+"), url.QueryEscape("url:"+postURL))
+		found, err := s.listing(searchURL)
+		if err != nil {
+			return nil, err
+		}
+		// Search matches loosely, so keep the submissions of this post only
+		for _, sub := range found {
+			if sameURL(sub.URL, postURL) {
+				submissions = append(submissions, sub)
+			}
+		}
+	}
+
+	if len(submissions) == 0 {
+		return nil, nil // Not found on Reddit
+	}
+
+	result := &RedditResult{}
+	seen := make(map[string]bool)
+	for _, sub := range submissions {
+		if seen[sub.ID] {
+			continue
+		}
+		seen[sub.ID] = true
+		result.Submissions = append(result.Submissions, sub)
+		result.Score += max(sub.Score, 0)
+		result.Comments += sub.NumComments
+	}
+	return result, nil
+}
+
+func (s *RedditScorer) listing(listingURL string) ([]RedditSubmission, error) {
+	req, err := http.NewRequest(http.MethodGet, listingURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", s.userAgent)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Reddit API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Reddit API returned %d", resp.StatusCode)
+	}
+
+	var listing RedditListing
+	if err := json.NewDecoder(resp.Body).Decode(&listing); err != nil {
+		return nil, fmt.Errorf("failed to decode Reddit response: %w", err)
+	}
+
+	var submissions []RedditSubmission
+	for _, child := range listing.Data.Children {
+		if child.Kind == "t3" { // link submissions
+			submissions = append(submissions, child.Data)
+		}
+	}
+	return submissions, nil
+}
+
+// sameURL compares URLs ignoring the scheme, a www. prefix and a trailing slash
+func sameURL(a, b string) bool {
+	normalize := func(u string) string {
+		u = strings.ToLower(strings.TrimSpace(u))
+		u = strings.TrimPrefix(strings.TrimPrefix(u, "https://"), "http://")
+		u = strings.TrimPrefix(u, "www.")
+		return strings.TrimRight(u, "/")
+	}
+	return normalize(a) == normalize(b)
+}
@@ -0,0 +1,85 @@
+package scorer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedditSearchByURL(t *testing.T) {
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		if r.URL.Path != "/api/info.json" || r.URL.Query().Get("url") != "https://blog.test/post" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"data": {"children": [
+			{"kind": "t3", "data": {"id": "a", "subreddit": "golang", "score": 120, "num_comments": 30, "url": "https://blog.test/post"}},
+			{"kind": "t3", "data": {"id": "b", "subreddit": "programming", "score": 40, "num_comments": 10, "url": "https://blog.test/post"}},
+			{"kind": "t1", "data": {"id": "c", "score": 999}}
+		]}}`))
+	}))
+	defer server.Close()
+
+	result, err := NewRedditScorer(server.URL+"/", nil, "blogmon/test").SearchByURL("https://blog.test/post")
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if result == nil || len(result.Submissions) != 2 {
+		t.Fatalf("expected 2 submissions, got %+v", result)
+	}
+	if result.Score != 160 || result.Comments != 40 || result.Total() != 200 {
+		t.Errorf("unexpected totals: %+v", result)
+	}
+	if userAgent != "blogmon/test" {
+		t.Errorf("expected the user agent to be sent, got %q", userAgent)
+	}
+}
+
+func TestRedditSearchFallsBackToSubreddits(t *testing.T) {
+	var searched string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/info.json":
+			w.Write([]byte(`{"data": {"children": []}}`))
+		case "/r/golang+programming/search.json":
+			searched = r.URL.Query().Get("q")
+			w.Write([]byte(`{"data": {"children": [
+				{"kind": "t3", "data": {"id": "a", "score": 12, "num_comments": 3, "url": "http://www.blog.test/post/"}},
+				{"kind": "t3", "data": {"id": "b", "score": 50, "num_comments": 5, "url": "https://blog.test/other"}}
+			]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	scorer := NewRedditScorer(server.URL, []string{"golang", "programming"}, "blogmon/test")
+	result, err := scorer.SearchByURL("https://blog.test/post")
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if searched != "url:https://blog.test/post" {
+		t.Errorf("unexpected search query %q", searched)
+	}
+	if result == nil || result.Total() != 15 {
+		t.Fatalf("expected only the matching submission, got %+v", result)
+	}
+
+	result, err = NewRedditScorer(server.URL, nil, "blogmon/test").SearchByURL("https://blog.test/post")
+	if err != nil || result != nil {
+		t.Errorf("expected no result without subreddits, got %+v (%v)", result, err)
+	}
+}
+
+func TestRedditSearchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	if _, err := NewRedditScorer(server.URL, nil, "blogmon/test").SearchByURL("https://blog.test/post"); err == nil {
+		t.Error("expected an error for a rate-limited request")
+	}
+}
@@ -0,0 +1,42 @@
+package scorer
+
+// Community signal providers
+const (
+	ProviderHN       = "hn"
+	ProviderReddit   = "reddit"
+	ProviderLobsters = "lobsters"
+)
+
+// Providers lists the known community signal providers
+var Providers = []string{ProviderHN, ProviderReddit, ProviderLobsters}
+
+// Signal is a snapshot of how a post was received on a community site
+type Signal struct {
+	Provider    string
+	Points      int
+	Comments    int
+	Submissions int
+}
+
+// CommunitySignal looks up how a post URL was received on a community site.
+// Lookup returns a zero signal when the URL was never submitted.
+type CommunitySignal interface {
+	Name() string
+	Lookup(postURL string) (Signal, error)
+}
+
+// CommunityScoreFromSignals combines the latest signal of each provider on
+// the CalculateCommunityScore scale. Lobsters counts like HN: it is smaller,
+// but its votes and comments are as deliberate.
+func CommunityScoreFromSignals(signals []Signal) float64 {
+	raw := 0
+	for _, s := range signals {
+		switch s.Provider {
+		case ProviderHN, ProviderLobsters:
+			raw += s.Points*2 + s.Comments*3
+		case ProviderReddit:
+			raw += s.Points + s.Comments
+		}
+	}
+	return communityScore(raw)
+}
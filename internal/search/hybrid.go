@@ -0,0 +1,203 @@
+package search
+
+import (
+	"database/sql"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/julienpequegnot/blogmon/internal/embedding"
+)
+
+// rrfK dampens the weight of top ranks in reciprocal rank fusion; 60 is the
+// value from the original paper and works well without tuning
+const rrfK = 60
+
+// Number of candidates taken from each ranking before fusion
+const minCandidates = 50
+
+const excerptLen = 240
+
+var sentenceSplit = regexp.MustCompile(`(?:[.!?])\s+|\n+`)
+
+// Semantic returns the posts whose embedded chunks are closest to the query
+// vector, with the best matching chunk as snippet. Rank is the cosine
+// similarity, higher is better.
+func (r *Repository) Semantic(query string, vector []float32, model string, opts Options) ([]SearchResult, error) {
+	matches, err := embedding.NewRepository(r.db).Nearest(vector, model, embedding.KindPost, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	for _, m := range matches {
+		if len(results) == opts.Limit {
+			break
+		}
+
+		sr, err := r.postResult(m.OwnerID, opts.Topic)
+		if err == sql.ErrNoRows {
+			continue // filtered out by topic
+		}
+		if err != nil {
+			return nil, err
+		}
+		sr.Snippet = Excerpt(m.Text, query, excerptLen)
+		sr.Rank = m.Similarity
+		results = append(results, *sr)
+	}
+	return results, nil
+}
+
+// Hybrid combines BM25 and semantic rankings with reciprocal rank fusion.
+// Snippets come from the best matching chunk when the post has one. Rank is
+// the fused score, higher is better.
+func (r *Repository) Hybrid(query string, vector []float32, model string, opts Options) ([]SearchResult, error) {
+	candidates := opts
+	candidates.Limit = max(opts.Limit*3, minCandidates)
+	candidates.Ranked = false
+
+	// Free text such as "what's p99?" is not valid FTS syntax, so the
+	// lexical ranking matches its quoted keywords instead
+	var lexical []SearchResult
+	if keywords := Keywords(query); len(keywords) > 0 {
+		var err error
+		lexical, err = r.Query(matchAny(keywords), candidates)
+		if err != nil {
+			return nil, err
+		}
+	}
+	semantic, err := r.Semantic(query, vector, model, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	fused := FuseRRF(semantic, lexical)
+	if len(fused) > opts.Limit {
+		fused = fused[:opts.Limit]
+	}
+	return fused, nil
+}
+
+// Words too common to help find posts
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "how": true, "what": true, "why": true,
+	"does": true, "did": true, "who": true, "when": true, "where": true, "which": true, "with": true,
+	"about": true, "people": true, "you": true, "your": true, "can": true, "should": true, "this": true,
+	"that": true, "from": true, "into": true, "there": true, "their": true, "them": true, "they": true,
+	"have": true, "has": true, "its": true, "use": true, "using": true, "was": true, "were": true,
+}
+
+// Keywords returns the distinct lowercase words of a query worth searching
+// for
+func Keywords(query string) []string {
+	seen := make(map[string]bool)
+	var keywords []string
+	for _, w := range strings.Fields(strings.ToLower(query)) {
+		w = strings.Trim(w, `.,;:!?"'()[]{}`)
+		w = strings.ReplaceAll(w, `"`, "")
+		if len(w) < 3 || stopWords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		keywords = append(keywords, w)
+	}
+	return keywords
+}
+
+// matchAny is an FTS5 query matching posts that contain any of the keywords
+func matchAny(keywords []string) string {
+	quoted := make([]string, len(keywords))
+	for i, k := range keywords {
+		quoted[i] = `"` + k + `"`
+	}
+	return strings.Join(quoted, " OR ")
+}
+
+// FuseRRF merges rankings of the same posts, scoring each post by the sum
+// of 1/(k+rank) over the rankings it appears in. For posts found in several
+// rankings, the first ranking that has a snippet provides it.
+func FuseRRF(rankings ...[]SearchResult) []SearchResult {
+	scores := make(map[int64]float64)
+	byID := make(map[int64]SearchResult)
+
+	for _, ranking := range rankings {
+		for i, sr := range ranking {
+			scores[sr.PostID] += 1.0 / float64(rrfK+i+1)
+			if existing, ok := byID[sr.PostID]; !ok || existing.Snippet == "" {
+				byID[sr.PostID] = sr
+			}
+		}
+	}
+
+	fused := make([]SearchResult, 0, len(byID))
+	for id, sr := range byID {
+		sr.Rank = scores[id]
+		fused = append(fused, sr)
+	}
+	sort.Slice(fused, func(i, j int) bool {
+		if fused[i].Rank != fused[j].Rank {
+			return fused[i].Rank > fused[j].Rank
+		}
+		return fused[i].PostID < fused[j].PostID
+	})
+	return fused
+}
+
+// Excerpt picks the sentence of text sharing the most words with the query,
+// cut to maxLen bytes
+func Excerpt(text, query string, maxLen int) string {
+	terms := make(map[string]bool)
+	for _, t := range strings.Fields(strings.ToLower(query)) {
+		terms[strings.Trim(t, `.,;:!?"'()`)] = true
+	}
+
+	best, bestHits := "", -1
+	for _, sentence := range sentenceSplit.Split(text, -1) {
+		sentence = strings.Join(strings.Fields(sentence), " ")
+		if sentence == "" {
+			continue
+		}
+		hits := 0
+		for _, w := range strings.Fields(strings.ToLower(sentence)) {
+			if terms[strings.Trim(w, `.,;:!?"'()*#`+"`")] {
+				hits++
+			}
+		}
+		if hits > bestHits {
+			best, bestHits = sentence, hits
+		}
+	}
+
+	if len(best) > maxLen {
+		cut := strings.LastIndex(best[:maxLen], " ")
+		if cut <= 0 {
+			cut = maxLen
+		}
+		best = best[:cut] + "..."
+	}
+	return best
+}
+
+// postResult loads the listing details of a post, or sql.ErrNoRows when the
+// post does not carry the topic
+func (r *Repository) postResult(postID int64, topic string) (*SearchResult, error) {
+	args := []any{postID}
+	topicClause := ""
+	if topic != "" {
+		topicClause = "AND p.id IN (SELECT post_id FROM post_topics WHERE topic = ?)"
+		args = append(args, topic)
+	}
+
+	var sr SearchResult
+	err := r.db.QueryRow(`
+		SELECT p.id, p.title, s.name, p.published_at, COALESCE(sc.final_score, 0)
+		FROM posts p
+		JOIN sources s ON p.source_id = s.id
+		LEFT JOIN scores sc ON p.id = sc.post_id
+		WHERE p.id = ? `+topicClause, args...).Scan(&sr.PostID, &sr.Title, &sr.SourceName, &sr.PublishedAt, &sr.FinalScore)
+	if err != nil {
+		return nil, err
+	}
+	return &sr, nil
+}
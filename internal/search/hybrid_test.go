@@ -0,0 +1,168 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/julienpequegnot/blogmon/internal/embedding"
+	"github.com/julienpequegnot/blogmon/internal/insight"
+)
+
+func embedTestPosts(t *testing.T, repo *embedding.Repository) {
+	vectors := map[int64][]float32{1: {1, 0, 0}, 2: {0, 1, 0}, 3: {0, 0, 1}}
+	texts := map[int64]string{
+		1: "Goroutines are cheap. Channels connect them.",
+		2: "Ownership rules prevent data races.",
+		3: "Pandas loads tabular data. Numpy does the arithmetic fast.",
+	}
+	for id, v := range vectors {
+		if err := repo.Replace(embedding.KindPost, id, "test-model", "", []string{texts[id]}, [][]float32{v}); err != nil {
+			t.Fatalf("failed to store embedding: %v", err)
+		}
+	}
+}
+
+func TestSemanticSearch(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	embedTestPosts(t, embedding.NewRepository(db))
+
+	repo := NewRepository(db)
+	results, err := repo.Semantic("fast arithmetic", []float32{0.1, 0.2, 1}, "test-model", Options{Limit: 2})
+	if err != nil {
+		t.Fatalf("semantic search failed: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Title != "Python Data Science" {
+		t.Errorf("expected nearest post first, got %s", results[0].Title)
+	}
+	if results[0].Snippet != "Numpy does the arithmetic fast." {
+		t.Errorf("expected best matching sentence as snippet, got %q", results[0].Snippet)
+	}
+
+	results, _ = repo.Semantic("fast arithmetic", []float32{0.1, 0.2, 1}, "other-model", Options{Limit: 2})
+	if len(results) != 0 {
+		t.Errorf("expected no results for a model without embeddings, got %d", len(results))
+	}
+}
+
+func TestHybridSearch(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	embedTestPosts(t, embedding.NewRepository(db))
+
+	repo := NewRepository(db)
+	results, err := repo.Hybrid("memory", []float32{0.2, 0.1, 1}, "test-model", Options{Limit: 2})
+	if err != nil {
+		t.Fatalf("hybrid search failed: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Title != "Rust Memory Safety" {
+		t.Errorf("expected the post found by both rankings first, got %s", results[0].Title)
+	}
+	if results[1].Title != "Python Data Science" {
+		t.Errorf("expected the semantic top match second, got %s", results[1].Title)
+	}
+}
+
+func TestHybridSearchQuotesFreeText(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	embedTestPosts(t, embedding.NewRepository(db))
+
+	results, err := NewRepository(db).Hybrid("what's numpy?", []float32{0, 0, 1}, "test-model", Options{Limit: 1})
+	if err != nil {
+		t.Fatalf("expected free text to be quoted for full-text search, got %v", err)
+	}
+	if len(results) != 1 || results[0].Title != "Python Data Science" {
+		t.Errorf("unexpected results: %+v", results)
+	}
+}
+
+func TestFuseRRF(t *testing.T) {
+	a := []SearchResult{{PostID: 1, Snippet: "chunk"}, {PostID: 2}}
+	b := []SearchResult{{PostID: 2, Snippet: "fts"}, {PostID: 3}}
+
+	fused := FuseRRF(a, b)
+	if len(fused) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(fused))
+	}
+	if fused[0].PostID != 2 {
+		t.Errorf("expected post in both rankings first, got %d", fused[0].PostID)
+	}
+	if fused[0].Snippet != "fts" {
+		t.Errorf("expected snippet from the ranking that has one, got %q", fused[0].Snippet)
+	}
+	if fused[1].PostID != 1 || fused[2].PostID != 3 {
+		t.Errorf("unexpected order: %+v", fused)
+	}
+}
+
+func TestPassagesLexical(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	passages, err := NewRepository(db).Passages("How does Rust handle ownership?", nil, "", 5)
+	if err != nil {
+		t.Fatalf("failed to retrieve passages: %v", err)
+	}
+
+	if len(passages) != 1 {
+		t.Fatalf("expected 1 passage, got %d", len(passages))
+	}
+	p := passages[0]
+	if p.Title != "Rust Memory Safety" || p.URL != "https://test.com/p2" {
+		t.Errorf("unexpected passage source: %+v", p)
+	}
+	if p.Text != "Understanding ownership and borrowing in Rust" || p.Kind != PassageChunk {
+		t.Errorf("unexpected passage: %+v", p)
+	}
+}
+
+func TestPassagesSemanticWithInsights(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	embedRepo := embedding.NewRepository(db)
+	embedTestPosts(t, embedRepo)
+
+	ins, err := insight.NewRepository(db).Add(3, insight.TypeTakeaway, "Vectorize with numpy", 5)
+	if err != nil {
+		t.Fatalf("failed to add insight: %v", err)
+	}
+	embedRepo.Replace(embedding.KindInsight, ins.ID, "test-model", "", []string{ins.Content}, [][]float32{{0, 0, 1}})
+
+	passages, err := NewRepository(db).Passages("speed up tabular maths", []float32{0, 0.1, 1}, "test-model", 2)
+	if err != nil {
+		t.Fatalf("failed to retrieve passages: %v", err)
+	}
+
+	if len(passages) != 3 {
+		t.Fatalf("expected 2 chunks and 1 insight, got %d", len(passages))
+	}
+	if passages[0].PostID != 3 {
+		t.Errorf("expected nearest post first, got %d", passages[0].PostID)
+	}
+	last := passages[2]
+	if last.Kind != PassageInsight || last.PostID != 3 || last.Text != "Vectorize with numpy" {
+		t.Errorf("unexpected insight passage: %+v", last)
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	got := Keywords(`How do people size "connection" pools in Postgres? Pools!`)
+	want := []string{"size", "connection", "pools", "postgres"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	}
+}
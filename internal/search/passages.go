@@ -0,0 +1,125 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/julienpequegnot/blogmon/internal/embedding"
+)
+
+// Passage kinds
+const (
+	PassageChunk   = "chunk"
+	PassageInsight = "insight"
+)
+
+// Passage is an excerpt of a post retrieved to answer a question
+type Passage struct {
+	PostID int64
+	Title  string
+	URL    string
+	Kind   string
+	Text   string
+}
+
+// Passages retrieves up to limit post excerpts relevant to a question, plus
+// up to limit/2 extracted insights when embeddings are available. A nil
+// vector retrieves by full-text match only. Each post contributes at most
+// one excerpt: its best matching chunk.
+func (r *Repository) Passages(question string, vector []float32, model string, limit int) ([]Passage, error) {
+	var semantic []SearchResult
+	var insights []embedding.Match
+	embedRepo := embedding.NewRepository(r.db)
+
+	if vector != nil {
+		matches, err := embedRepo.Nearest(vector, model, embedding.KindPost, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			semantic = append(semantic, SearchResult{PostID: m.OwnerID, Snippet: m.Text})
+		}
+
+		insights, err = embedRepo.Nearest(vector, model, embedding.KindInsight, limit/2)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lexical, err := r.lexicalChunks(question, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var passages []Passage
+	for _, sr := range FuseRRF(semantic, lexical) {
+		if len(passages) == limit {
+			break
+		}
+		p := Passage{PostID: sr.PostID, Kind: PassageChunk, Text: sr.Snippet}
+		if err := r.db.QueryRow(`SELECT title, url FROM posts WHERE id = ?`, sr.PostID).Scan(&p.Title, &p.URL); err != nil {
+			return nil, err
+		}
+		passages = append(passages, p)
+	}
+
+	for _, m := range insights {
+		p := Passage{Kind: PassageInsight, Text: m.Text}
+		if err := r.db.QueryRow(`
+			SELECT p.id, p.title, p.url FROM insights i JOIN posts p ON i.post_id = p.id WHERE i.id = ?
+		`, m.OwnerID).Scan(&p.PostID, &p.Title, &p.URL); err != nil {
+			continue // insight replaced by a newer extraction
+		}
+		passages = append(passages, p)
+	}
+
+	return passages, nil
+}
+
+// lexicalChunks finds posts containing any keyword of the question and
+// returns each with the chunk of its content matching the most keywords
+func (r *Repository) lexicalChunks(question string, limit int) ([]SearchResult, error) {
+	keywords := Keywords(question)
+	if len(keywords) == 0 {
+		return nil, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT p.id, COALESCE(NULLIF(p.content_clean, ''), posts_fts.content)
+		FROM posts_fts
+		JOIN posts p ON posts_fts.rowid = p.id
+		WHERE posts_fts MATCH ?
+		ORDER BY bm25(posts_fts)
+		LIMIT ?
+	`, matchAny(keywords), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var sr SearchResult
+		var content string
+		if err := rows.Scan(&sr.PostID, &content); err != nil {
+			return nil, err
+		}
+		sr.Snippet = bestChunk(content, keywords)
+		results = append(results, sr)
+	}
+	return results, rows.Err()
+}
+
+func bestChunk(content string, keywords []string) string {
+	best, bestHits := "", -1
+	for _, chunk := range embedding.Chunk(content, embedding.ChunkWords) {
+		lower := strings.ToLower(chunk)
+		hits := 0
+		for _, k := range keywords {
+			hits += strings.Count(lower, k)
+		}
+		if hits > bestHits {
+			best, bestHits = chunk, hits
+		}
+	}
+	return best
+}
@@ -1,6 +1,7 @@
 package search
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/julienpequegnot/blogmon/internal/database"
@@ -24,42 +25,36 @@ func NewRepository(db *database.DB) *Repository {
 	return &Repository{db: db}
 }
 
+// Options controls filtering and ranking of a search
+type Options struct {
+	Limit  int
+	Ranked bool   // rank by combined BM25 and final score
+	Topic  string // restrict to posts tagged with this topic
+}
+
 func (r *Repository) Search(query string, limit int) ([]SearchResult, error) {
-	rows, err := r.db.Query(`
-		SELECT
-			p.id,
-			p.title,
-			s.name,
-			p.published_at,
-			snippet(posts_fts, -1, '<b>', '</b>', '...', 32) as snippet,
-			bm25(posts_fts) as rank,
-			COALESCE(sc.final_score, 0) as final_score
-		FROM posts_fts
-		JOIN posts p ON posts_fts.rowid = p.id
-		JOIN sources s ON p.source_id = s.id
-		LEFT JOIN scores sc ON p.id = sc.post_id
-		WHERE posts_fts MATCH ?
-		ORDER BY bm25(posts_fts)
-		LIMIT ?
-	`, query, limit)
-	if err != nil {
-		return nil, err
+	return r.Query(query, Options{Limit: limit})
+}
+
+func (r *Repository) SearchWithScore(query string, limit int) ([]SearchResult, error) {
+	return r.Query(query, Options{Limit: limit, Ranked: true})
+}
+
+func (r *Repository) Query(query string, opts Options) ([]SearchResult, error) {
+	orderClause := "ORDER BY bm25(posts_fts)"
+	if opts.Ranked {
+		orderClause = "ORDER BY (COALESCE(sc.final_score, 0) * 0.3 - bm25(posts_fts) * 0.7) DESC"
 	}
-	defer rows.Close()
 
-	var results []SearchResult
-	for rows.Next() {
-		var sr SearchResult
-		if err := rows.Scan(&sr.PostID, &sr.Title, &sr.SourceName, &sr.PublishedAt, &sr.Snippet, &sr.Rank, &sr.FinalScore); err != nil {
-			return nil, err
-		}
-		results = append(results, sr)
+	args := []any{query}
+	topicClause := ""
+	if opts.Topic != "" {
+		topicClause = "AND p.id IN (SELECT post_id FROM post_topics WHERE topic = ?)"
+		args = append(args, opts.Topic)
 	}
-	return results, rows.Err()
-}
+	args = append(args, opts.Limit)
 
-func (r *Repository) SearchWithScore(query string, limit int) ([]SearchResult, error) {
-	rows, err := r.db.Query(`
+	rows, err := r.db.Query(fmt.Sprintf(`
 		SELECT
 			p.id,
 			p.title,
@@ -73,9 +68,10 @@ func (r *Repository) SearchWithScore(query string, limit int) ([]SearchResult, e
 		JOIN sources s ON p.source_id = s.id
 		LEFT JOIN scores sc ON p.id = sc.post_id
 		WHERE posts_fts MATCH ?
-		ORDER BY (COALESCE(sc.final_score, 0) * 0.3 - bm25(posts_fts) * 0.7) DESC
+		%s
+		%s
 		LIMIT ?
-	`, query, limit)
+	`, topicClause, orderClause), args...)
 	if err != nil {
 		return nil, err
 	}
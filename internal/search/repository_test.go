@@ -8,6 +8,7 @@ import (
 	"github.com/julienpequegnot/blogmon/internal/database"
 	"github.com/julienpequegnot/blogmon/internal/post"
 	"github.com/julienpequegnot/blogmon/internal/source"
+	"github.com/julienpequegnot/blogmon/internal/topic"
 )
 
 func setupTestDB(t *testing.T) *database.DB {
@@ -26,9 +27,13 @@ func setupTestDB(t *testing.T) *database.DB {
 	p3, _ := postRepo.Add(src.ID, "https://test.com/p3", "Python Data Science", "Author", time.Now(), "Using pandas and numpy for data analysis")
 
 	// Update posts with clean content to trigger FTS index update
-	postRepo.UpdateContentClean(p1.ID, "This post covers goroutines and channels in Go programming", 10)
-	postRepo.UpdateContentClean(p2.ID, "Understanding ownership and borrowing in Rust", 7)
-	postRepo.UpdateContentClean(p3.ID, "Using pandas and numpy for data analysis", 8)
+	postRepo.UpdateContentClean(p1.ID, "This post covers goroutines and channels in Go programming")
+	postRepo.UpdateContentClean(p2.ID, "Understanding ownership and borrowing in Rust")
+	postRepo.UpdateContentClean(p3.ID, "Using pandas and numpy for data analysis")
+
+	topicRepo := topic.NewRepository(db)
+	topicRepo.SetForPost(p1.ID, []string{"golang", "concurrency"})
+	topicRepo.SetForPost(p2.ID, []string{"rust"})
 
 	// Manually rebuild FTS index since external content mode may have issues
 	searchRepo := NewRepository(db)
@@ -90,3 +95,22 @@ func TestSearchWithSnippet(t *testing.T) {
 		t.Error("expected snippet to be populated")
 	}
 }
+
+func TestSearchTopicFilter(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	results, err := repo.Query("ownership OR goroutines", Options{Limit: 10, Topic: "rust"})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result for topic 'rust', got %d", len(results))
+	}
+	if results[0].Title != "Rust Memory Safety" {
+		t.Errorf("expected Rust post, got %s", results[0].Title)
+	}
+}
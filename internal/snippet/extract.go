@@ -0,0 +1,142 @@
+package snippet
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/julienpequegnot/blogmon/internal/markdown"
+)
+
+const maxCaptionLen = 300
+
+var classLanguage = regexp.MustCompile(`(?:^|\s)(?:language|lang|highlight|sourceCode|brush:)-?\s*([A-Za-z0-9_+#-]+)`)
+
+// Language aliases mapped to the name stored in the snippets table
+var languageAliases = map[string]string{
+	"golang":        "go",
+	"js":            "javascript",
+	"jsx":           "javascript",
+	"ts":            "typescript",
+	"tsx":           "typescript",
+	"py":            "python",
+	"python3":       "python",
+	"rs":            "rust",
+	"rb":            "ruby",
+	"sh":            "shell",
+	"bash":          "shell",
+	"zsh":           "shell",
+	"console":       "shell",
+	"shell-session": "shell",
+	"yml":           "yaml",
+	"c++":           "cpp",
+	"cc":            "cpp",
+	"cs":            "csharp",
+	"c#":            "csharp",
+	"kt":            "kotlin",
+	"psql":          "sql",
+	"postgresql":    "sql",
+	"dockerfile":    "docker",
+}
+
+// Content signatures used when the markup does not name the language,
+// checked in order
+var languageSignatures = []struct {
+	language string
+	pattern  *regexp.Regexp
+}{
+	{"go", regexp.MustCompile(`(?m)^package \w+$|\bfunc (\(\w+ \*?\w+\) )?\w+\(|:= |\bif err != nil\b`)},
+	{"rust", regexp.MustCompile(`\bfn \w+\(|\blet mut\b|\bimpl\b.*\{|println!\(`)},
+	{"python", regexp.MustCompile(`(?m)^\s*def \w+\(.*\):|^\s*(from \w+ )?import \w+$|\bself\.\w+|print\(`)},
+	{"typescript", regexp.MustCompile(`\binterface \w+ \{|: (string|number|boolean)\b`)},
+	{"javascript", regexp.MustCompile(`\b(const|let) \w+ = |=> \{|\bfunction \w*\(|console\.log\(`)},
+	{"java", regexp.MustCompile(`\bpublic (static )?(class|void)\b|System\.out\.println`)},
+	{"sql", regexp.MustCompile(`(?i)\bSELECT\b.+\bFROM\b|\bCREATE TABLE\b|\bINSERT INTO\b`)},
+	{"shell", regexp.MustCompile(`(?m)^\$ |^(sudo|apt|brew|curl|git|docker|kubectl|go|npm|pip) `)},
+	{"json", regexp.MustCompile(`^\s*[\[{]\s*"`)},
+	{"yaml", regexp.MustCompile(`(?m)^[\w-]+:\s*$\n^\s+[\w-]+: `)},
+}
+
+// Extract returns the code blocks of a post, each captioned with the
+// paragraph that introduces it
+func Extract(html string) []Snippet {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil
+	}
+
+	var snippets []Snippet
+	doc.Find("pre").Each(func(_ int, pre *goquery.Selection) {
+		code := strings.Trim(pre.Text(), "\n")
+		if strings.TrimSpace(code) == "" {
+			return
+		}
+
+		classes, _ := pre.Attr("class")
+		if inner := pre.Find("code").First(); inner.Length() > 0 {
+			c, _ := inner.Attr("class")
+			classes += " " + c
+		}
+		if lang, ok := pre.Attr("data-lang"); ok {
+			classes += " language-" + lang
+		}
+
+		snippets = append(snippets, Snippet{
+			Language: DetectLanguage(classes, code),
+			Caption:  caption(pre),
+			Code:     code,
+			Position: len(snippets),
+		})
+	})
+
+	return snippets
+}
+
+// DetectLanguage names the language of a code block from its CSS classes,
+// falling back to its content. It returns "" when unsure.
+func DetectLanguage(classes, code string) string {
+	if m := classLanguage.FindStringSubmatch(classes); m != nil {
+		if lang := NormalizeLanguage(m[1]); lang != "plaintext" && lang != "text" && lang != "none" {
+			return lang
+		}
+	}
+
+	for _, sig := range languageSignatures {
+		if sig.pattern.MatchString(code) {
+			return sig.language
+		}
+	}
+	return ""
+}
+
+// NormalizeLanguage maps language names and aliases to a canonical form
+func NormalizeLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if canonical, ok := languageAliases[lang]; ok {
+		return canonical
+	}
+	return lang
+}
+
+// caption finds the paragraph directly before a code block, looking past
+// wrapper elements such as syntax highlighting divs
+func caption(pre *goquery.Selection) string {
+	node := pre
+	for depth := 0; depth < 3 && node.Length() > 0; depth++ {
+		prev := node.Prev()
+		if prev.Length() == 0 {
+			node = node.Parent()
+			continue
+		}
+		if !prev.Is("p, li, h1, h2, h3, h4, h5, h6, figcaption") {
+			return ""
+		}
+
+		text := strings.Join(strings.Fields(prev.Text()), " ")
+		if len(text) > maxCaptionLen {
+			text = markdown.TruncateBytes(text, maxCaptionLen) + "..."
+		}
+		return text
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package snippet
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestExtract(t *testing.T) {
+	html := `<h2>Timeouts</h2>
+<p>Wrap the request in a deadline:</p>
+<div class="highlight"><pre><code class="language-golang">ctx, cancel := context.WithTimeout(ctx, time.Second)
+defer cancel()
+</code></pre></div>
+<p>Then check the result.</p>
+<pre>$ curl -s localhost:8080/health</pre>
+<pre>   </pre>`
+
+	snippets := Extract(html)
+	if len(snippets) != 2 {
+		t.Fatalf("expected 2 snippets, got %d", len(snippets))
+	}
+
+	first := snippets[0]
+	if first.Language != "go" {
+		t.Errorf("expected language go, got %q", first.Language)
+	}
+	if first.Caption != "Wrap the request in a deadline:" {
+		t.Errorf("unexpected caption: %q", first.Caption)
+	}
+	if first.Code != "ctx, cancel := context.WithTimeout(ctx, time.Second)\ndefer cancel()" {
+		t.Errorf("unexpected code: %q", first.Code)
+	}
+
+	second := snippets[1]
+	if second.Language != "shell" {
+		t.Errorf("expected language detected from content, got %q", second.Language)
+	}
+	if second.Caption != "Then check the result." {
+		t.Errorf("unexpected caption: %q", second.Caption)
+	}
+}
+
+func TestExtractCutsLongCaptionOnRuneBoundary(t *testing.T) {
+	html := "<p>" + strings.Repeat("é", maxCaptionLen) + "</p><pre>make build</pre>"
+
+	snippets := Extract(html)
+	if len(snippets) != 1 {
+		t.Fatalf("expected 1 snippet, got %d", len(snippets))
+	}
+	if c := snippets[0].Caption; !utf8.ValidString(c) || !strings.HasSuffix(c, "...") {
+		t.Errorf("expected a valid truncated caption, got %q", c)
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		classes string
+		code    string
+		want    string
+	}{
+		{"language-py", "x = 1", "python"},
+		{"lang-rs", "", "rust"},
+		{"", "def handler(event):\n    return event", "python"},
+		{"", "SELECT id FROM posts WHERE score > 0", "sql"},
+		{"", "fn main() {\n    println!(\"hi\");\n}", "rust"},
+		{"language-text", "just words", ""},
+	}
+
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.classes, tt.code); got != tt.want {
+			t.Errorf("DetectLanguage(%q, %q) = %q, want %q", tt.classes, tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteQuery(t *testing.T) {
+	if got := QuoteQuery(`context.WithTimeout  "x"`); got != `"context.WithTimeout" """x"""` {
+		t.Errorf("unexpected query: %s", got)
+	}
+}
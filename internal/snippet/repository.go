@@ -0,0 +1,146 @@
+package snippet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+)
+
+type Snippet struct {
+	ID       int64
+	PostID   int64
+	Language string
+	Caption  string
+	Code     string
+	Position int
+}
+
+// SearchResult is a snippet matching a search, with the post it came from
+type SearchResult struct {
+	Snippet
+	PostTitle string
+	PostURL   string
+}
+
+type Repository struct {
+	db *database.DB
+}
+
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// ReplaceForPost stores the snippets of a post, replacing earlier ones
+func (r *Repository) ReplaceForPost(postID int64, snippets []Snippet) error {
+	if _, err := r.db.Exec(`DELETE FROM snippets WHERE post_id = ?`, postID); err != nil {
+		return err
+	}
+
+	for i, s := range snippets {
+		if _, err := r.db.Exec(
+			`INSERT INTO snippets (post_id, language, caption, code, position) VALUES (?, ?, ?, ?, ?)`,
+			postID, s.Language, s.Caption, s.Code, i,
+		); err != nil {
+			return fmt.Errorf("failed to insert snippet: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *Repository) ListForPost(postID int64) ([]Snippet, error) {
+	rows, err := r.db.Query(`
+		SELECT id, post_id, COALESCE(language, ''), COALESCE(caption, ''), code, position
+		FROM snippets WHERE post_id = ? ORDER BY position
+	`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snippets []Snippet
+	for rows.Next() {
+		var s Snippet
+		if err := rows.Scan(&s.ID, &s.PostID, &s.Language, &s.Caption, &s.Code, &s.Position); err != nil {
+			return nil, err
+		}
+		snippets = append(snippets, s)
+	}
+	return snippets, rows.Err()
+}
+
+// Search finds snippets whose code or caption contain every term of the
+// query. Terms are matched literally as substrings of at least three
+// characters, so identifiers like context.WithTimeout need no FTS syntax.
+// An empty language matches all.
+func (r *Repository) Search(query, language string, limit int) ([]SearchResult, error) {
+	match := QuoteQuery(query)
+	if match == "" {
+		return nil, nil
+	}
+
+	args := []any{match}
+	langClause := ""
+	if language != "" {
+		langClause = "AND sn.language = ?"
+		args = append(args, NormalizeLanguage(language))
+	}
+	args = append(args, limit)
+
+	rows, err := r.db.Query(fmt.Sprintf(`
+		SELECT sn.id, sn.post_id, COALESCE(sn.language, ''), COALESCE(sn.caption, ''), sn.code, sn.position,
+		       p.title, p.url
+		FROM snippets_fts
+		JOIN snippets sn ON snippets_fts.rowid = sn.id
+		JOIN posts p ON sn.post_id = p.id
+		WHERE snippets_fts MATCH ?
+		%s
+		ORDER BY bm25(snippets_fts)
+		LIMIT ?
+	`, langClause), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var sr SearchResult
+		if err := rows.Scan(&sr.ID, &sr.PostID, &sr.Language, &sr.Caption, &sr.Code, &sr.Position,
+			&sr.PostTitle, &sr.PostURL); err != nil {
+			return nil, err
+		}
+		results = append(results, sr)
+	}
+	return results, rows.Err()
+}
+
+// Languages returns the number of snippets per language
+func (r *Repository) Languages() (map[string]int, error) {
+	rows, err := r.db.Query(`SELECT COALESCE(language, ''), COUNT(*) FROM snippets GROUP BY language`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var lang string
+		var n int
+		if err := rows.Scan(&lang, &n); err != nil {
+			return nil, err
+		}
+		counts[lang] = n
+	}
+	return counts, rows.Err()
+}
+
+// QuoteQuery turns free text into an FTS5 query where each whitespace
+// separated term is a quoted phrase, so punctuation is not parsed as syntax
+func QuoteQuery(query string) string {
+	var terms []string
+	for _, term := range strings.Fields(query) {
+		terms = append(terms, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+	}
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,81 @@
+package snippet
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/julienpequegnot/blogmon/internal/source"
+)
+
+func setupTestDB(t *testing.T) (*database.DB, int64) {
+	tmpDir := t.TempDir()
+	db, err := database.New(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+
+	srcRepo := source.NewRepository(db)
+	src, _ := srcRepo.Add("https://test.com", "Test", "")
+
+	postRepo := post.NewRepository(db)
+	p, _ := postRepo.Add(src.ID, "https://test.com/p1", "Test Post", "Author", time.Now(), "content")
+
+	return db, p.ID
+}
+
+func TestReplaceForPost(t *testing.T) {
+	db, postID := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	repo.ReplaceForPost(postID, []Snippet{{Language: "go", Code: "fmt.Println(1)"}, {Code: "ls"}})
+	err := repo.ReplaceForPost(postID, []Snippet{{Language: "go", Caption: "Print it", Code: "fmt.Println(2)"}})
+	if err != nil {
+		t.Fatalf("failed to replace snippets: %v", err)
+	}
+
+	snippets, _ := repo.ListForPost(postID)
+	if len(snippets) != 1 {
+		t.Fatalf("expected 1 snippet, got %d", len(snippets))
+	}
+	if snippets[0].Caption != "Print it" {
+		t.Errorf("unexpected caption: %s", snippets[0].Caption)
+	}
+}
+
+func TestSearchSnippets(t *testing.T) {
+	db, postID := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	repo.ReplaceForPost(postID, []Snippet{
+		{Language: "go", Caption: "Set a deadline", Code: "ctx, cancel := context.WithTimeout(ctx, time.Second)"},
+		{Language: "python", Code: "asyncio.wait_for(task, timeout=1)"},
+		{Language: "go", Code: "http.ListenAndServe(addr, nil)"},
+	})
+
+	results, err := repo.Search("context.WithTimeout", "", 10)
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if results[0].PostURL != "https://test.com/p1" || results[0].PostTitle != "Test Post" {
+		t.Errorf("expected link back to the post, got %+v", results[0])
+	}
+
+	results, _ = repo.Search("timeout", "golang", 10)
+	if len(results) != 1 || results[0].Language != "go" {
+		t.Errorf("expected language filter to keep only the go snippet, got %+v", results)
+	}
+
+	results, _ = repo.Search("deadline", "", 10)
+	if len(results) != 1 {
+		t.Errorf("expected caption match, got %d results", len(results))
+	}
+}
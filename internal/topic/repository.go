@@ -0,0 +1,75 @@
+package topic
+
+import (
+	"fmt"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+)
+
+type Repository struct {
+	db *database.DB
+}
+
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// SetForPost replaces the stored topics of a post
+func (r *Repository) SetForPost(postID int64, topics []string) error {
+	if err := r.DeleteForPost(postID); err != nil {
+		return err
+	}
+
+	for _, t := range topics {
+		if _, err := r.db.Exec(
+			`INSERT OR IGNORE INTO post_topics (post_id, topic) VALUES (?, ?)`,
+			postID, t,
+		); err != nil {
+			return fmt.Errorf("failed to insert topic: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *Repository) ListForPost(postID int64) ([]string, error) {
+	rows, err := r.db.Query(`SELECT topic FROM post_topics WHERE post_id = ? ORDER BY topic`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var topics []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		topics = append(topics, t)
+	}
+	return topics, rows.Err()
+}
+
+// ListAll returns the stored topics of every post, keyed by post ID
+func (r *Repository) ListAll() (map[int64][]string, error) {
+	rows, err := r.db.Query(`SELECT post_id, topic FROM post_topics ORDER BY post_id, topic`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	topics := make(map[int64][]string)
+	for rows.Next() {
+		var postID int64
+		var t string
+		if err := rows.Scan(&postID, &t); err != nil {
+			return nil, err
+		}
+		topics[postID] = append(topics[postID], t)
+	}
+	return topics, rows.Err()
+}
+
+func (r *Repository) DeleteForPost(postID int64) error {
+	_, err := r.db.Exec(`DELETE FROM post_topics WHERE post_id = ?`, postID)
+	return err
+}
@@ -0,0 +1,76 @@
+package topic
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/post"
+	"github.com/julienpequegnot/blogmon/internal/source"
+)
+
+func setupTestDB(t *testing.T) (*database.DB, int64, int64) {
+	tmpDir := t.TempDir()
+	db, err := database.New(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+
+	srcRepo := source.NewRepository(db)
+	src, _ := srcRepo.Add("https://test.com", "Test", "")
+
+	postRepo := post.NewRepository(db)
+	p1, _ := postRepo.Add(src.ID, "https://test.com/p1", "Post 1", "Author", time.Now(), "content")
+	p2, _ := postRepo.Add(src.ID, "https://test.com/p2", "Post 2", "Author", time.Now(), "content")
+
+	return db, p1.ID, p2.ID
+}
+
+func TestSetForPost(t *testing.T) {
+	db, postID, _ := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	if err := repo.SetForPost(postID, []string{"golang", "performance", "golang"}); err != nil {
+		t.Fatalf("failed to set topics: %v", err)
+	}
+
+	topics, err := repo.ListForPost(postID)
+	if err != nil {
+		t.Fatalf("failed to list topics: %v", err)
+	}
+	if len(topics) != 2 {
+		t.Errorf("expected 2 topics, got %d", len(topics))
+	}
+
+	// Setting again replaces the previous topics
+	repo.SetForPost(postID, []string{"rust"})
+	topics, _ = repo.ListForPost(postID)
+	if len(topics) != 1 || topics[0] != "rust" {
+		t.Errorf("expected [rust], got %v", topics)
+	}
+}
+
+func TestListAll(t *testing.T) {
+	db, postA, postB := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	repo.SetForPost(postA, []string{"golang"})
+	repo.SetForPost(postB, []string{"rust", "databases"})
+
+	all, err := repo.ListAll()
+	if err != nil {
+		t.Fatalf("failed to list topics: %v", err)
+	}
+
+	if len(all[postA]) != 1 {
+		t.Errorf("expected 1 topic for post A, got %d", len(all[postA]))
+	}
+	if len(all[postB]) != 2 {
+		t.Errorf("expected 2 topics for post B, got %d", len(all[postB]))
+	}
+}
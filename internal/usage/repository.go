@@ -0,0 +1,149 @@
+package usage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/llm"
+)
+
+// Groupings for Stats
+const (
+	ByStage = "stage"
+	ByModel = "model"
+	ByDay   = "day" // per day and model
+)
+
+// Stat aggregates the LLM calls of one group
+type Stat struct {
+	Group          string // stage, model or day depending on the grouping
+	Model          string // set when grouping by day
+	Calls          int
+	Failures       int
+	PromptTokens   int
+	ResponseTokens int
+	TotalLatency   time.Duration
+	MaxLatency     time.Duration
+	SuccessLatency time.Duration // latency of successful calls only
+}
+
+// FailureRate is the fraction of calls that failed
+func (s Stat) FailureRate() float64 {
+	if s.Calls == 0 {
+		return 0
+	}
+	return float64(s.Failures) / float64(s.Calls)
+}
+
+func (s Stat) AvgLatency() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Calls)
+}
+
+// TokensPerSecond is the generation throughput of successful calls
+func (s Stat) TokensPerSecond() float64 {
+	if s.SuccessLatency <= 0 {
+		return 0
+	}
+	return float64(s.ResponseTokens) / s.SuccessLatency.Seconds()
+}
+
+type Repository struct {
+	db *database.DB
+}
+
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Record stores an LLM call; it implements llm.Recorder
+func (r *Repository) Record(call llm.Call) error {
+	at := call.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO llm_calls (stage, model, prompt_tokens, response_tokens, latency_ms, success, error_class, error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, call.Stage, call.Model, call.PromptTokens, call.ResponseTokens, call.Latency.Milliseconds(),
+		call.ErrorClass == "", call.ErrorClass, call.Error, at.UTC())
+	return err
+}
+
+// Stats aggregates calls made since the given time
+func (r *Repository) Stats(by string, since time.Time) ([]Stat, error) {
+	var group, model string
+	switch by {
+	case ByStage:
+		group, model = "stage", "''"
+	case ByModel:
+		group, model = "model", "''"
+	case ByDay:
+		// created_at is stored in UTC, so its date prefix is the UTC day
+		group, model = "substr(created_at, 1, 10)", "model"
+	default:
+		return nil, fmt.Errorf("unknown grouping: %s", by)
+	}
+
+	rows, err := r.db.Query(fmt.Sprintf(`
+		SELECT %[1]s AS grp, %[2]s AS mdl,
+		       COUNT(*),
+		       SUM(CASE WHEN success THEN 0 ELSE 1 END),
+		       COALESCE(SUM(prompt_tokens), 0),
+		       COALESCE(SUM(response_tokens), 0),
+		       COALESCE(SUM(latency_ms), 0),
+		       COALESCE(MAX(latency_ms), 0),
+		       COALESCE(SUM(CASE WHEN success THEN latency_ms ELSE 0 END), 0)
+		FROM llm_calls
+		WHERE created_at >= ?
+		GROUP BY grp, mdl
+		ORDER BY grp, mdl
+	`, group, model), since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []Stat
+	for rows.Next() {
+		var s Stat
+		var total, maxMs, success int64
+		if err := rows.Scan(&s.Group, &s.Model, &s.Calls, &s.Failures, &s.PromptTokens, &s.ResponseTokens,
+			&total, &maxMs, &success); err != nil {
+			return nil, err
+		}
+		s.TotalLatency = time.Duration(total) * time.Millisecond
+		s.MaxLatency = time.Duration(maxMs) * time.Millisecond
+		s.SuccessLatency = time.Duration(success) * time.Millisecond
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// ErrorClasses counts failed calls since the given time by error class
+func (r *Repository) ErrorClasses(since time.Time) (map[string]int, error) {
+	rows, err := r.db.Query(`
+		SELECT error_class, COUNT(*) FROM llm_calls
+		WHERE NOT success AND created_at >= ?
+		GROUP BY error_class
+	`, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var class string
+		var n int
+		if err := rows.Scan(&class, &n); err != nil {
+			return nil, err
+		}
+		counts[class] = n
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,78 @@
+package usage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/blogmon/internal/database"
+	"github.com/julienpequegnot/blogmon/internal/llm"
+)
+
+func setupTestDB(t *testing.T) *database.DB {
+	tmpDir := t.TempDir()
+	db, err := database.New(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	return db
+}
+
+func TestStats(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	now := time.Now()
+	calls := []llm.Call{
+		{Stage: "extract", Model: "llama3.2", PromptTokens: 1000, ResponseTokens: 200, Latency: 2 * time.Second, At: now},
+		{Stage: "extract", Model: "llama3.2", PromptTokens: 1000, Latency: 4 * time.Second, ErrorClass: llm.ErrorInvalid, At: now},
+		{Stage: "summary", Model: "qwen2.5", PromptTokens: 500, ResponseTokens: 100, Latency: time.Second, At: now},
+		{Stage: "summary", Model: "qwen2.5", ResponseTokens: 999, Latency: time.Second, At: now.AddDate(0, 0, -40)},
+	}
+	for _, c := range calls {
+		if err := repo.Record(c); err != nil {
+			t.Fatalf("failed to record call: %v", err)
+		}
+	}
+
+	since := now.AddDate(0, 0, -30)
+	byStage, err := repo.Stats(ByStage, since)
+	if err != nil {
+		t.Fatalf("failed to get stats: %v", err)
+	}
+	if len(byStage) != 2 {
+		t.Fatalf("expected 2 stages, got %+v", byStage)
+	}
+
+	extract := byStage[0]
+	if extract.Group != "extract" || extract.Calls != 2 || extract.Failures != 1 {
+		t.Errorf("unexpected extract stats: %+v", extract)
+	}
+	if extract.FailureRate() != 0.5 || extract.AvgLatency() != 3*time.Second || extract.MaxLatency != 4*time.Second {
+		t.Errorf("unexpected extract rates: %+v", extract)
+	}
+	// Throughput only counts the latency of the successful call
+	if extract.TokensPerSecond() != 100 {
+		t.Errorf("expected 100 tokens/s, got %f", extract.TokensPerSecond())
+	}
+	if byStage[1].ResponseTokens != 100 {
+		t.Errorf("expected the old summary call to be excluded, got %+v", byStage[1])
+	}
+
+	byDay, err := repo.Stats(ByDay, since)
+	if err != nil {
+		t.Fatalf("failed to get daily stats: %v", err)
+	}
+	if len(byDay) != 2 || byDay[0].Group != now.UTC().Format("2006-01-02") || byDay[0].Model != "llama3.2" {
+		t.Errorf("unexpected daily stats: %+v", byDay)
+	}
+
+	classes, err := repo.ErrorClasses(since)
+	if err != nil {
+		t.Fatalf("failed to count error classes: %v", err)
+	}
+	if len(classes) != 1 || classes[llm.ErrorInvalid] != 1 {
+		t.Errorf("expected one invalid failure, got %v", classes)
+	}
+}
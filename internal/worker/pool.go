@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+	"sync"
+)
+
+// Run calls fn for every item using at most workers goroutines. Once ctx is
+// cancelled no new items are started; Run returns after in-flight calls finish.
+func Run[T any](ctx context.Context, workers int, items []T, fn func(ctx context.Context, item T)) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan T)
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range jobs {
+				fn(ctx, item)
+			}
+		}()
+	}
+
+dispatch:
+	for _, item := range items {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- item:
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+}
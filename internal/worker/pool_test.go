@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunProcessesAllItems(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8}
+
+	var mu sync.Mutex
+	sum := 0
+	Run(context.Background(), 3, items, func(ctx context.Context, n int) {
+		mu.Lock()
+		sum += n
+		mu.Unlock()
+	})
+
+	if sum != 36 {
+		t.Errorf("expected sum 36, got %d", sum)
+	}
+}
+
+func TestRunBoundsConcurrency(t *testing.T) {
+	items := make([]int, 20)
+
+	var active, peak int32
+	Run(context.Background(), 2, items, func(ctx context.Context, _ int) {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&active, -1)
+	})
+
+	if peak > 2 {
+		t.Errorf("expected at most 2 concurrent workers, got %d", peak)
+	}
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	items := make([]int, 100)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var calls int32
+	Run(ctx, 1, items, func(ctx context.Context, _ int) {
+		if atomic.AddInt32(&calls, 1) == 5 {
+			cancel()
+		}
+	})
+
+	if calls >= 100 {
+		t.Errorf("expected cancellation to stop dispatch, got %d calls", calls)
+	}
+}
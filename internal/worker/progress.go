@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Progress tracks a batch of work and renders a status line. In live mode the
+// line is redrawn in place after every update and log message.
+type Progress struct {
+	mu        sync.Mutex
+	out       io.Writer
+	live      bool
+	total     int
+	done      int
+	failed    int
+	skipped   int
+	startedAt time.Time
+}
+
+func NewProgress(out io.Writer, total int, live bool) *Progress {
+	return &Progress{
+		out:       out,
+		live:      live,
+		total:     total,
+		startedAt: time.Now(),
+	}
+}
+
+func (p *Progress) Done(msg string) {
+	p.record(&p.done, msg)
+}
+
+func (p *Progress) Failed(msg string) {
+	p.record(&p.failed, msg)
+}
+
+func (p *Progress) Skipped(msg string) {
+	p.record(&p.skipped, msg)
+}
+
+func (p *Progress) record(counter *int, msg string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	*counter++
+	p.clear()
+	if msg != "" {
+		fmt.Fprintln(p.out, msg)
+	}
+	p.draw()
+}
+
+// Succeeded returns the number of items that completed successfully
+func (p *Progress) Succeeded() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.done
+}
+
+// Remaining returns the number of items not yet finished
+func (p *Progress) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.total - p.finished()
+}
+
+// Finish removes the live status line
+func (p *Progress) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+}
+
+// Line formats the current status, e.g. "12/40 done, 1 failed, 27 remaining, ETA 3m20s"
+func (p *Progress) Line() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.line(time.Now())
+}
+
+func (p *Progress) line(now time.Time) string {
+	remaining := p.total - p.finished()
+	s := fmt.Sprintf("%d/%d done, %d failed, %d remaining", p.done, p.total, p.failed, remaining)
+	if p.skipped > 0 {
+		s += fmt.Sprintf(", %d skipped", p.skipped)
+	}
+
+	if finished := p.finished(); finished > 0 && remaining > 0 {
+		perItem := now.Sub(p.startedAt) / time.Duration(finished)
+		eta := (perItem * time.Duration(remaining)).Round(time.Second)
+		s += fmt.Sprintf(", ETA %s", eta)
+	}
+	return s
+}
+
+func (p *Progress) finished() int {
+	return p.done + p.failed + p.skipped
+}
+
+func (p *Progress) clear() {
+	if p.live {
+		fmt.Fprint(p.out, "\r\033[K")
+	}
+}
+
+func (p *Progress) draw() {
+	if p.live {
+		fmt.Fprint(p.out, p.line(time.Now()))
+	}
+}
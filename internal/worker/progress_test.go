@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressLine(t *testing.T) {
+	var out strings.Builder
+	p := NewProgress(&out, 4, false)
+
+	p.Done("first")
+	p.Failed("second")
+
+	line := p.Line()
+	if !strings.HasPrefix(line, "1/4 done, 1 failed, 2 remaining") {
+		t.Errorf("unexpected progress line: %q", line)
+	}
+	if p.Remaining() != 2 {
+		t.Errorf("expected 2 remaining, got %d", p.Remaining())
+	}
+	if out.String() != "first\nsecond\n" {
+		t.Errorf("expected log messages without status line, got %q", out.String())
+	}
+}
+
+func TestProgressETA(t *testing.T) {
+	p := NewProgress(&strings.Builder{}, 3, false)
+	p.startedAt = time.Now().Add(-10 * time.Second)
+	p.done = 1
+
+	line := p.line(p.startedAt.Add(10 * time.Second))
+	if !strings.HasSuffix(line, "ETA 20s") {
+		t.Errorf("expected ETA 20s, got %q", line)
+	}
+}